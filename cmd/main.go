@@ -20,6 +20,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"k8s.io/klog/v2"
 
@@ -41,7 +42,67 @@ func main() {
 		volMetricsFsRateLimit    = flag.Int("vol-metrics-fs-rate-limit", 5, "Volume metrics routines rate limiter per file system")
 		deleteAccessPointRootDir = flag.Bool("delete-access-point-root-dir", false,
 			"Opt in to delete access point root directory by DeleteVolume. By default, DeleteVolume will delete the access point behind Persistent Volume and deleting access point will not delete the access point root directory or its contents.")
-		tags = flag.String("tags", "", "Space separated key:value pairs which will be added as tags for EFS resources. For example, 'environment:prod region:us-east-1'")
+		deleteAccessPointOnRootDirCleanupFailure = flag.Bool("delete-access-point-on-root-dir-cleanup-failure", false,
+			"Only used with delete-access-point-root-dir. If the file system cannot be mounted to delete the access point root directory, log a warning and delete the access point anyway, leaving the root directory and its contents on the file system.")
+		retainAccessPointOnDelete = flag.Bool("retain-access-point-on-delete", false,
+			"Keep the access point behind a Persistent Volume when it is deleted so it can be reused, removing only the contents of its root directory.")
+		rootDirDeleteWorkers = flag.Int("root-dir-delete-workers", 1,
+			"How many files and directories DeleteVolume removes in parallel when it deletes or empties an access point root directory.")
+		internalMountsPlainNfs = flag.Bool("internal-mounts-plain-nfs", false,
+			"Mount the file system over plain NFSv4.1 through a mount target IP address, instead of with efs-utils using tls and iam, when the controller mounts it to delete an access point root directory.")
+		provisioningEvents = flag.Bool("provisioning-events", false,
+			"Record Kubernetes events on PVCs for notable provisioning decisions, such as the mount target chosen for cross account mount or tags dropped over the limit. Requires --extra-create-metadata on the csi-provisioner.")
+		tagStorageClass = flag.Bool("tag-storage-class", false,
+			"Tag access points with the storage class of the PVC they are provisioned for. Requires --extra-create-metadata on the csi-provisioner.")
+		probeMountTargets = flag.Bool("probe-mount-targets", false,
+			"Check that a mount target is reachable on the NFS port before the controller uses it, falling back to the other available mount targets of the file system.")
+		cleanupTempMountsOnStartup = flag.Bool("cleanup-temp-mounts-on-startup", false,
+			"Unmount and remove the temporary mount points of access point root directories left behind by an earlier run of the controller, for example one that crashed during DeleteVolume.")
+		tempMountPathPrefix = flag.String("temp-mount-path-prefix", driver.TempMountPathPrefix,
+			"Directory the controller creates temporary mount points in when it mounts a file system to delete an access point root directory. Each mount point gets a unique name.")
+		createAccessPointRetries = flag.Int("create-access-point-retries", 5,
+			"How many times CreateVolume retries a throttled CreateAccessPoint call, with exponential backoff, before failing with Unavailable so it is retried later.")
+		accessPointLimit = flag.Int("access-point-limit", driver.MaxAccessPointsPerFs,
+			"How many access points a file system can have. CreateVolume fails with ResourceExhausted, before creating anything, once a file system has that many. Defaults to the EFS quota, 0 disables the check.")
+		roleCloudCacheTTL = flag.Duration("role-cloud-cache-ttl", 15*time.Minute,
+			"How long the AWS clients created for a cross account role are reused before being created again. 0 creates new clients for every request.")
+		mountTargetCacheTTL = flag.Duration("mount-target-cache-ttl", 30*time.Second,
+			"How long the mount target found for a file system and availability zone is reused by concurrent and subsequent requests. 0 describes the mount targets for every request.")
+		deleteRetryInterval = flag.Duration("delete-retry-interval", 0,
+			"Minimum time between DeleteVolume attempts for the same volume. Attempts within it fail with Aborted without mounting the file system. 0 allows every attempt.")
+		deleteVolumeTimeout = flag.Duration("delete-volume-timeout", 5*time.Minute,
+			"How long a DeleteVolume, which is shared by the concurrent attempts to delete the same volume, may run. It keeps running when the attempt that started it gives up. 0 does not limit it.")
+		allowedDirectoryPerms = flag.String("allowed-directory-perms", "",
+			"Comma separated list of the octal modes, such as 700,750, that storage classes may give as directoryPerms. CreateVolume fails with InvalidArgument for any other mode. Any mode is allowed when empty.")
+		mountOptionsConfig = flag.String("internal-mount-options-config", "",
+			"Path to a JSON file mapping file system IDs to lists of mount options the controller adds to tls and iam when it mounts that file system. Options in a storage class's mountOptions secret take precedence.")
+		phaseMetrics = flag.Bool("phase-metrics", false,
+			"Also time each phase of CreateVolume and DeleteVolume, such as describing the file system, creating the access point or mounting, in a histogram with a phase label. Requires --metrics-address.")
+		defaultProvisioningMode = flag.String("default-provisioning-mode", "",
+			"Provisioning mode used for storage classes that do not set the provisioningMode parameter, such as efs-ap. CreateVolume fails with InvalidArgument for those storage classes when empty.")
+		volumeIdFormat = flag.String("volume-id-format", "legacy",
+			"Format of the volume IDs of new volumes, legacy (fs-...::fsap-...) or v1 (v1:accesspoint:fs-...:fsap-...). Only node plugins that understand v1 volume IDs can mount v1 volumes, so upgrade every node before setting it. Volumes in another region are always v1.")
+		defaultUid = flag.Int64("default-uid", -1,
+			"Uid of the access points of storage classes that set neither a uid parameter nor a uid provisioner secret. -1 uses the allocated gid.")
+		defaultGid = flag.Int64("default-gid", -1,
+			"Gid of the access points of storage classes that set neither a gid parameter nor a gid provisioner secret. -1 allocates one from the gid range.")
+		region = flag.String("region", "",
+			"AWS region of the EFS and STS clients. The region of the instance or task is used when empty.")
+		efsEndpoint = flag.String("efs-endpoint", "",
+			"URL of a custom EFS endpoint, such as a VPC endpoint or the endpoint of an isolated region. The endpoint of the region is used when empty.")
+		useFips = flag.Bool("fips", false,
+			"Use the FIPS endpoints of EFS and STS.")
+		verifyEfsConnectivity = flag.Bool("verify-efs-connectivity", false,
+			"Check at startup, and again from the CSI Probe, that the controller can reach a mount target of the canary file system on the NFS port, failing the Probe with the reason when it cannot. Requires --canary-file-system-id.")
+		verifyEfsConnectivityInterval = flag.Duration("verify-efs-connectivity-interval", time.Minute,
+			"How long the CSI Probe reuses the result of the last --verify-efs-connectivity check before checking again. 0 checks on every Probe.")
+		canaryFileSystemId = flag.String("canary-file-system-id", "",
+			"File system whose mount target --verify-efs-connectivity checks.")
+		canaryAzName = flag.String("canary-az", "",
+			"Availability zone of the mount target --verify-efs-connectivity checks. Any available mount target is used when empty, or when the file system has none in that zone.")
+		xrayDaemonAddress = flag.String("xray-daemon-address", "", "Address (host:port) of the AWS X-Ray daemon to send controller traces to. Tracing is disabled when empty.")
+		metricsAddress    = flag.String("metrics-address", "", "Address (host:port) to serve Prometheus provisioning metrics on at /metrics. Metrics are disabled when empty.")
+		tags              = flag.String("tags", "", "Space separated key:value pairs which will be added as tags for EFS resources. For example, 'environment:prod region:us-east-1'")
 	)
 	klog.InitFlags(nil)
 	flag.Parse()
@@ -60,7 +121,7 @@ func main() {
 	if err != nil {
 		klog.Fatalln(err)
 	}
-	drv := driver.NewDriver(*endpoint, etcAmazonEfs, *efsUtilsStaticFilesPath, *tags, *volMetricsOptIn, *volMetricsRefreshPeriod, *volMetricsFsRateLimit, *deleteAccessPointRootDir)
+	drv := driver.NewDriver(*endpoint, etcAmazonEfs, *efsUtilsStaticFilesPath, *tags, *volMetricsOptIn, *volMetricsRefreshPeriod, *volMetricsFsRateLimit, *deleteAccessPointRootDir, *deleteAccessPointOnRootDirCleanupFailure, *retainAccessPointOnDelete, *internalMountsPlainNfs, *provisioningEvents, *tagStorageClass, *probeMountTargets, *cleanupTempMountsOnStartup, *phaseMetrics, *useFips, *verifyEfsConnectivity, *createAccessPointRetries, *rootDirDeleteWorkers, *accessPointLimit, *defaultUid, *defaultGid, *roleCloudCacheTTL, *mountTargetCacheTTL, *deleteRetryInterval, *deleteVolumeTimeout, *verifyEfsConnectivityInterval, *tempMountPathPrefix, *allowedDirectoryPerms, *defaultProvisioningMode, *volumeIdFormat, *region, *efsEndpoint, *canaryFileSystemId, *canaryAzName, *xrayDaemonAddress, *metricsAddress, *mountOptionsConfig)
 	if err := drv.Run(); err != nil {
 		klog.Fatalln(err)
 	}
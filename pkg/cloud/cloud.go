@@ -17,20 +17,27 @@ limitations under the License.
 package cloud
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
+	"net"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/efs"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 	"k8s.io/klog/v2"
 )
 
@@ -38,26 +45,43 @@ const (
 	AccessDeniedException    = "AccessDeniedException"
 	AccessPointAlreadyExists = "AccessPointAlreadyExists"
 	PvcNameTagKey            = "pvcName"
+
+	// MinSessionDuration and MaxSessionDuration bound the duration STS accepts for an assumed role session.
+	MinSessionDuration = 15 * time.Minute
+	MaxSessionDuration = 12 * time.Hour
 )
 
 var (
 	ErrNotFound      = errors.New("Resource was not found")
 	ErrAlreadyExists = errors.New("Resource already exists")
 	ErrAccessDenied  = errors.New("Access denied")
+	ErrThrottled     = errors.New("Request was throttled")
+	// ErrNoMountTargets is terminal, the file system cannot be mounted until a mount target is created.
+	ErrNoMountTargets = errors.New("File system has no mount targets")
+	// ErrIncorrectLifeCycleState is transient, the file system is being created, updated or deleted.
+	ErrIncorrectLifeCycleState = errors.New("File system is not in a lifecycle state that allows the operation")
+	// ErrTagPolicyViolation is terminal, retrying with the same tags is rejected again.
+	ErrTagPolicyViolation = errors.New("Tags do not comply with the tag policy")
 )
 
 type FileSystem struct {
-	FileSystemId string
+	FileSystemId   string
+	Encrypted      bool
+	LifeCycleState string
+	Tags           map[string]string
 }
 
 type AccessPoint struct {
 	AccessPointId      string
 	FileSystemId       string
 	AccessPointRootDir string
+	// ClientToken is the idempotency token the access point was created with
+	ClientToken string
 	// Capacity is used for testing purpose only
 	// EFS does not consider capacity while provisioning new file systems or access points
 	CapacityGiB int64
 	PosixUser   *PosixUser
+	Tags        map[string]string
 }
 
 type PosixUser struct {
@@ -73,6 +97,9 @@ type AccessPointOptions struct {
 	FileSystemId   string
 	Uid            int64
 	Gid            int64
+	SecondaryGids  []int64
+	OwnerUid       int64
+	OwnerGid       int64
 	DirectoryPerms string
 	DirectoryPath  string
 	Tags           map[string]string
@@ -83,6 +110,8 @@ type MountTarget struct {
 	AZId          string
 	MountTargetId string
 	IPAddress     string
+	SubnetId      string
+	VpcId         string
 }
 
 // Efs abstracts efs client(https://docs.aws.amazon.com/sdk-for-go/api/service/efs/)
@@ -101,7 +130,10 @@ type Cloud interface {
 	DescribeAccessPoint(ctx context.Context, accessPointId string) (accessPoint *AccessPoint, err error)
 	ListAccessPoints(ctx context.Context, fileSystemId string) (accessPoints []*AccessPoint, err error)
 	DescribeFileSystem(ctx context.Context, fileSystemId string) (fs *FileSystem, err error)
+	FindFileSystemByTag(ctx context.Context, key, value string) (fileSystems []*FileSystem, err error)
 	DescribeMountTargets(ctx context.Context, fileSystemId, az string) (fs *MountTarget, err error)
+	DescribeMountTargetInSubnet(ctx context.Context, fileSystemId, subnetId string) (mountTarget *MountTarget, err error)
+	ListMountTargets(ctx context.Context, fileSystemId string) (mountTargets []*MountTarget, err error)
 }
 
 type cloud struct {
@@ -109,19 +141,55 @@ type cloud struct {
 	efs      Efs
 }
 
+// EndpointOptions override how the AWS clients find their endpoints, for environments such as GovCloud, China
+// or FIPS where the defaults do not work. The zero value keeps the defaults.
+type EndpointOptions struct {
+	// Region overrides the region found in the instance or task metadata.
+	Region string
+	// EfsEndpoint is the URL of a custom EFS endpoint, such as a VPC endpoint.
+	EfsEndpoint string
+	// UseFIPS resolves the FIPS endpoints of EFS and STS.
+	UseFIPS bool
+}
+
+// config returns the AWS config for a client in region, or in the overridden region.
+func (o EndpointOptions) config(region string) *aws.Config {
+	if o.Region != "" {
+		region = o.Region
+	}
+	config := aws.NewConfig().WithRegion(region)
+	if o.UseFIPS {
+		config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+	return config
+}
+
+// AssumeRoleObserver is told about every call that assumes awsRoleArn, with how long it took and its error.
+type AssumeRoleObserver func(awsRoleArn string, duration time.Duration, err error)
+
 // NewCloud returns a new instance of AWS cloud
 // It panics if session is invalid
-func NewCloud() (Cloud, error) {
-	return createCloud("")
+func NewCloud(endpointOpts EndpointOptions) (Cloud, error) {
+	return createCloud("", "", 0, endpointOpts, nil)
 }
 
 // NewCloudWithRole returns a new instance of AWS cloud after assuming an aws role
+// for sessionDuration, or the STS default when sessionDuration is zero. Every call that
+// assumes the role is reported to observe, unless it is nil.
 // It panics if driver does not have permissions to assume role.
-func NewCloudWithRole(awsRoleArn string) (Cloud, error) {
-	return createCloud(awsRoleArn)
+func NewCloudWithRole(awsRoleArn string, sessionDuration time.Duration, endpointOpts EndpointOptions, observe AssumeRoleObserver) (Cloud, error) {
+	return createCloud(awsRoleArn, "", sessionDuration, endpointOpts, observe)
 }
 
-func createCloud(awsRoleArn string) (Cloud, error) {
+// NewCloudWithRoleWebIdentity returns a new instance of AWS cloud after assuming an aws role with the web
+// identity token in tokenFile, such as the token of an IAM role for service accounts. The token file is read
+// again every time the role is assumed, so a rotated token is picked up. Every call that assumes the role is
+// reported to observe, unless it is nil.
+func NewCloudWithRoleWebIdentity(awsRoleArn, tokenFile string, sessionDuration time.Duration, endpointOpts EndpointOptions, observe AssumeRoleObserver) (Cloud, error) {
+	return createCloud(awsRoleArn, tokenFile, sessionDuration, endpointOpts, observe)
+}
+
+func createCloud(awsRoleArn, tokenFile string, sessionDuration time.Duration, endpointOpts EndpointOptions, observe AssumeRoleObserver) (Cloud, error) {
 	sess := session.Must(session.NewSession(&aws.Config{}))
 	svc := ec2metadata.New(sess)
 	api, err := DefaultKubernetesAPIClient()
@@ -142,7 +210,7 @@ func createCloud(awsRoleArn string) (Cloud, error) {
 		return nil, fmt.Errorf("could not get metadata: %v", err)
 	}
 
-	efs_client := createEfsClient(awsRoleArn, metadata, sess)
+	efs_client := createEfsClient(awsRoleArn, tokenFile, sessionDuration, metadata, sess, endpointOpts, observe)
 	klog.V(5).Infof("EFS Client created using the following endpoint: %+v", efs_client.(*efs.EFS).Client.ClientInfo.Endpoint)
 
 	return &cloud{
@@ -151,14 +219,122 @@ func createCloud(awsRoleArn string) (Cloud, error) {
 	}, nil
 }
 
-func createEfsClient(awsRoleArn string, metadata MetadataService, sess *session.Session) Efs {
-	config := aws.NewConfig().WithRegion(metadata.GetRegion())
-	if awsRoleArn != "" {
-		config = config.WithCredentials(stscreds.NewCredentials(sess, awsRoleArn))
+func createEfsClient(awsRoleArn, tokenFile string, sessionDuration time.Duration, metadata MetadataService, sess *session.Session, endpointOpts EndpointOptions, observe AssumeRoleObserver) Efs {
+	config := endpointOpts.config(metadata.GetRegion())
+	if endpointOpts.EfsEndpoint != "" {
+		config = config.WithEndpoint(endpointOpts.EfsEndpoint)
+	}
+	if awsRoleArn != "" && tokenFile != "" {
+		config = config.WithCredentials(newWebIdentityCredentials(createStsClient(sess, metadata, endpointOpts), awsRoleArn, tokenFile, sessionDuration, observe))
+	} else if awsRoleArn != "" {
+		config = config.WithCredentials(newAssumeRoleCredentials(createStsClient(sess, metadata, endpointOpts), awsRoleArn, sessionDuration, observe))
 	}
 	return efs.New(session.Must(session.NewSession(config)))
 }
 
+// createStsClient returns the STS client roles are assumed with. Overriding the region or using FIPS needs a
+// regional STS endpoint, otherwise the session's defaults are kept.
+func createStsClient(sess *session.Session, metadata MetadataService, endpointOpts EndpointOptions) *sts.STS {
+	if endpointOpts.Region == "" && !endpointOpts.UseFIPS {
+		return sts.New(sess)
+	}
+	return sts.New(sess, endpointOpts.config(metadata.GetRegion()).WithSTSRegionalEndpoint(endpoints.RegionalSTSEndpoint))
+}
+
+func newAssumeRoleCredentials(client stscreds.AssumeRoler, awsRoleArn string, sessionDuration time.Duration, observe AssumeRoleObserver) *credentials.Credentials {
+	if sessionDuration == 0 {
+		sessionDuration = stscreds.DefaultDuration
+	}
+	return credentials.NewCredentials(newObservedProvider(&assumeRoleProvider{
+		AssumeRoleProvider: &stscreds.AssumeRoleProvider{
+			Client:   client,
+			RoleARN:  awsRoleArn,
+			Duration: sessionDuration,
+		},
+	}, awsRoleArn, observe))
+}
+
+func newWebIdentityCredentials(client stsiface.STSAPI, awsRoleArn, tokenFile string, sessionDuration time.Duration, observe AssumeRoleObserver) *credentials.Credentials {
+	// FetchTokenPath reads the token file on every Retrieve
+	return credentials.NewCredentials(newObservedProvider(stscreds.NewWebIdentityRoleProviderWithOptions(client, awsRoleArn, "", stscreds.FetchTokenPath(tokenFile),
+		func(p *stscreds.WebIdentityRoleProvider) {
+			p.Duration = sessionDuration
+		}), awsRoleArn, observe))
+}
+
+// observedProvider reports every retrieval of its credentials, each of which assumes awsRoleArn, to observe.
+type observedProvider struct {
+	provider   credentials.ProviderWithContext
+	awsRoleArn string
+	observe    AssumeRoleObserver
+}
+
+func newObservedProvider(provider credentials.ProviderWithContext, awsRoleArn string, observe AssumeRoleObserver) credentials.Provider {
+	if observe == nil {
+		return provider
+	}
+	return &observedProvider{provider: provider, awsRoleArn: awsRoleArn, observe: observe}
+}
+
+func (p *observedProvider) Retrieve() (credentials.Value, error) {
+	return p.RetrieveWithContext(aws.BackgroundContext())
+}
+
+func (p *observedProvider) RetrieveWithContext(ctx credentials.Context) (credentials.Value, error) {
+	start := time.Now()
+	value, err := p.provider.RetrieveWithContext(ctx)
+	p.observe(p.awsRoleArn, time.Since(start), err)
+	return value, err
+}
+
+func (p *observedProvider) IsExpired() bool {
+	return p.provider.IsExpired()
+}
+
+// assumeRoleProvider assumes a role like stscreds.AssumeRoleProvider, but retries with the default session duration
+// of STS when the requested duration is longer than the role's MaxSessionDuration allows.
+type assumeRoleProvider struct {
+	*stscreds.AssumeRoleProvider
+}
+
+func (p *assumeRoleProvider) Retrieve() (credentials.Value, error) {
+	return p.RetrieveWithContext(aws.BackgroundContext())
+}
+
+func (p *assumeRoleProvider) RetrieveWithContext(ctx credentials.Context) (credentials.Value, error) {
+	value, err := p.AssumeRoleProvider.RetrieveWithContext(ctx)
+	if _, ok := p.Client.(defaultDurationAssumeRoler); err == nil || ok || !isMaxSessionDurationExceeded(err) {
+		return value, err
+	}
+	klog.Warningf("Session duration %v exceeds the maximum allowed by role %v, retrying with the default session duration", p.Duration, p.RoleARN)
+	p.Client = defaultDurationAssumeRoler{p.Client}
+	value, err = p.AssumeRoleProvider.RetrieveWithContext(ctx)
+	if err == nil {
+		klog.Infof("Assumed role %v with a session duration of %v", p.RoleARN, time.Until(p.ExpiresAt()).Round(time.Minute))
+	}
+	return value, err
+}
+
+// defaultDurationAssumeRoler assumes roles without DurationSeconds, so STS grants its default session duration.
+type defaultDurationAssumeRoler struct {
+	stscreds.AssumeRoler
+}
+
+func (c defaultDurationAssumeRoler) AssumeRole(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+	input.DurationSeconds = nil
+	return c.AssumeRoler.AssumeRole(input)
+}
+
+func (c defaultDurationAssumeRoler) AssumeRoleWithContext(ctx aws.Context, input *sts.AssumeRoleInput, opts ...request.Option) (*sts.AssumeRoleOutput, error) {
+	input.DurationSeconds = nil
+	if client, ok := c.AssumeRoler.(interface {
+		AssumeRoleWithContext(aws.Context, *sts.AssumeRoleInput, ...request.Option) (*sts.AssumeRoleOutput, error)
+	}); ok {
+		return client.AssumeRoleWithContext(ctx, input, opts...)
+	}
+	return c.AssumeRoler.AssumeRole(input)
+}
+
 func (c *cloud) GetMetadata() MetadataService {
 	return c.metadata
 }
@@ -192,8 +368,8 @@ func (c *cloud) CreateAccessPoint(ctx context.Context, clientToken string, acces
 		},
 		RootDirectory: &efs.RootDirectory{
 			CreationInfo: &efs.CreationInfo{
-				OwnerGid:    &accessPointOpts.Gid,
-				OwnerUid:    &accessPointOpts.Uid,
+				OwnerGid:    &accessPointOpts.OwnerGid,
+				OwnerUid:    &accessPointOpts.OwnerUid,
 				Permissions: &accessPointOpts.DirectoryPerms,
 			},
 			Path: &accessPointOpts.DirectoryPath,
@@ -201,12 +377,29 @@ func (c *cloud) CreateAccessPoint(ctx context.Context, clientToken string, acces
 		Tags: efsTags,
 	}
 
+	if len(accessPointOpts.SecondaryGids) > 0 {
+		createAPInput.PosixUser.SecondaryGids = aws.Int64Slice(accessPointOpts.SecondaryGids)
+	}
+
 	klog.V(5).Infof("Calling Create AP with input: %+v", *createAPInput)
 	res, err := c.efs.CreateAccessPointWithContext(ctx, createAPInput)
 	if err != nil {
 		if isAccessDenied(err) {
 			return nil, ErrAccessDenied
 		}
+		if isIncorrectLifeCycleState(err) {
+			return nil, ErrIncorrectLifeCycleState
+		}
+		if request.IsErrorThrottle(err) {
+			return nil, ErrThrottled
+		}
+		if isTagPolicyViolation(err) {
+			klog.Warningf("CreateAccessPoint in file system %v was rejected by a tag policy: %v", accessPointOpts.FileSystemId, err)
+			return nil, ErrTagPolicyViolation
+		}
+		if isAccessPointAlreadyExists(err) {
+			return nil, ErrAlreadyExists
+		}
 		return nil, fmt.Errorf("Failed to create access point: %v", err)
 	}
 	klog.V(5).Infof("Create AP response : %+v", res)
@@ -258,34 +451,27 @@ func (c *cloud) DescribeAccessPoint(ctx context.Context, accessPointId string) (
 		AccessPointId:      *accessPoints[0].AccessPointId,
 		FileSystemId:       *accessPoints[0].FileSystemId,
 		AccessPointRootDir: *accessPoints[0].RootDirectory.Path,
+		Tags:               parseEfsTagsToMap(accessPoints[0].Tags),
 	}, nil
 }
 
 func (c *cloud) findAccessPointByClientToken(ctx context.Context, clientToken string, accessPointOpts *AccessPointOptions) (accessPoint *AccessPoint, err error) {
 	klog.V(5).Infof("AccessPointOptions to find AP : %+v", accessPointOpts)
 	klog.V(2).Infof("ClientToken to find AP : %s", clientToken)
-	describeAPInput := &efs.DescribeAccessPointsInput{
-		FileSystemId: &accessPointOpts.FileSystemId,
-		MaxResults:   aws.Int64(1000),
-	}
-	res, err := c.efs.DescribeAccessPointsWithContext(ctx, describeAPInput)
+	accessPoints, err := c.ListAccessPoints(ctx, accessPointOpts.FileSystemId)
 	if err != nil {
-		if isAccessDenied(err) {
-			return
+		if err == ErrAccessDenied || err == ErrNotFound {
+			return nil, nil
 		}
-		if isFileSystemNotFound(err) {
-			return
-		}
-		err = fmt.Errorf("failed to list Access Points of efs = %s : %v", accessPointOpts.FileSystemId, err)
-		return
+		return nil, fmt.Errorf("failed to list Access Points of efs = %s : %v", accessPointOpts.FileSystemId, err)
 	}
-	for _, ap := range res.AccessPoints {
+	for _, ap := range accessPoints {
 		// check if AP exists with same client token
-		if aws.StringValue(ap.ClientToken) == clientToken {
+		if ap.ClientToken == clientToken {
 			return &AccessPoint{
-				AccessPointId:      *ap.AccessPointId,
-				FileSystemId:       *ap.FileSystemId,
-				AccessPointRootDir: *ap.RootDirectory.Path,
+				AccessPointId:      ap.AccessPointId,
+				FileSystemId:       ap.FileSystemId,
+				AccessPointRootDir: ap.AccessPointRootDir,
 			}, nil
 		}
 	}
@@ -293,35 +479,49 @@ func (c *cloud) findAccessPointByClientToken(ctx context.Context, clientToken st
 	return nil, nil
 }
 
+// ListAccessPoints returns every access point of the file system, following NextToken across pages since EFS
+// returns at most 100 access points per call.
 func (c *cloud) ListAccessPoints(ctx context.Context, fileSystemId string) (accessPoints []*AccessPoint, err error) {
 	describeAPInput := &efs.DescribeAccessPointsInput{
 		FileSystemId: &fileSystemId,
 	}
-	res, err := c.efs.DescribeAccessPointsWithContext(ctx, describeAPInput)
-	if err != nil {
-		if isAccessDenied(err) {
-			return
+	for {
+		res, err := c.efs.DescribeAccessPointsWithContext(ctx, describeAPInput)
+		if err != nil {
+			if isAccessDenied(err) {
+				return nil, ErrAccessDenied
+			}
+			if isFileSystemNotFound(err) {
+				return nil, ErrNotFound
+			}
+			return nil, fmt.Errorf("List Access Points failed: %v", err)
 		}
-		if isFileSystemNotFound(err) {
-			return
+
+		for _, accessPointDescription := range res.AccessPoints {
+			accessPoint := &AccessPoint{
+				AccessPointId: *accessPointDescription.AccessPointId,
+				FileSystemId:  *accessPointDescription.FileSystemId,
+				ClientToken:   aws.StringValue(accessPointDescription.ClientToken),
+				Tags:          parseEfsTagsToMap(accessPointDescription.Tags),
+			}
+			// Access points created outside of the driver do not have to enforce a POSIX user
+			if accessPointDescription.PosixUser != nil {
+				accessPoint.PosixUser = &PosixUser{
+					Gid: aws.Int64Value(accessPointDescription.PosixUser.Gid),
+					Uid: aws.Int64Value(accessPointDescription.PosixUser.Uid),
+				}
+			}
+			if accessPointDescription.RootDirectory != nil {
+				accessPoint.AccessPointRootDir = aws.StringValue(accessPointDescription.RootDirectory.Path)
+			}
+			accessPoints = append(accessPoints, accessPoint)
 		}
-		err = fmt.Errorf("List Access Points failed: %v", err)
-		return
-	}
 
-	for _, accessPointDescription := range res.AccessPoints {
-		accessPoint := &AccessPoint{
-			AccessPointId: *accessPointDescription.AccessPointId,
-			FileSystemId:  *accessPointDescription.FileSystemId,
-			PosixUser: &PosixUser{
-				Gid: *accessPointDescription.PosixUser.Gid,
-				Uid: *accessPointDescription.PosixUser.Gid,
-			},
+		if aws.StringValue(res.NextToken) == "" {
+			return accessPoints, nil
 		}
-		accessPoints = append(accessPoints, accessPoint)
+		describeAPInput.NextToken = res.NextToken
 	}
-
-	return
 }
 
 func (c *cloud) DescribeFileSystem(ctx context.Context, fileSystemId string) (fs *FileSystem, err error) {
@@ -343,27 +543,52 @@ func (c *cloud) DescribeFileSystem(ctx context.Context, fileSystemId string) (fs
 		return nil, fmt.Errorf("DescribeFileSystem failed. Expected exactly 1 file system in DescribeFileSystem result. However, recevied %d file systems", len(fileSystems))
 	}
 	return &FileSystem{
-		FileSystemId: *res.FileSystems[0].FileSystemId,
+		FileSystemId:   *res.FileSystems[0].FileSystemId,
+		Encrypted:      aws.BoolValue(res.FileSystems[0].Encrypted),
+		LifeCycleState: aws.StringValue(res.FileSystems[0].LifeCycleState),
+		Tags:           parseEfsTagsToMap(res.FileSystems[0].Tags),
 	}, nil
 }
 
-func (c *cloud) DescribeMountTargets(ctx context.Context, fileSystemId, azName string) (fs *MountTarget, err error) {
-	describeMtInput := &efs.DescribeMountTargetsInput{FileSystemId: &fileSystemId}
-	klog.V(5).Infof("Calling DescribeMountTargets with input: %+v", *describeMtInput)
-	res, err := c.efs.DescribeMountTargetsWithContext(ctx, describeMtInput)
-	if err != nil {
-		if isAccessDenied(err) {
-			return nil, ErrAccessDenied
+// FindFileSystemByTag returns every file system in the account and region that has the tag key with the given value.
+func (c *cloud) FindFileSystemByTag(ctx context.Context, key, value string) (fileSystems []*FileSystem, err error) {
+	describeFsInput := &efs.DescribeFileSystemsInput{}
+	for {
+		klog.V(5).Infof("Calling DescribeFileSystems with input: %+v", *describeFsInput)
+		res, err := c.efs.DescribeFileSystemsWithContext(ctx, describeFsInput)
+		if err != nil {
+			if isAccessDenied(err) {
+				return nil, ErrAccessDenied
+			}
+			return nil, fmt.Errorf("Describe File Systems failed: %v", err)
 		}
-		if isFileSystemNotFound(err) {
-			return nil, ErrNotFound
+
+		for _, fileSystem := range res.FileSystems {
+			tags := parseEfsTagsToMap(fileSystem.Tags)
+			if tagValue, ok := tags[key]; ok && tagValue == value {
+				fileSystems = append(fileSystems, &FileSystem{
+					FileSystemId:   aws.StringValue(fileSystem.FileSystemId),
+					Encrypted:      aws.BoolValue(fileSystem.Encrypted),
+					LifeCycleState: aws.StringValue(fileSystem.LifeCycleState),
+					Tags:           tags,
+				})
+			}
 		}
-		return nil, fmt.Errorf("Describe Mount Targets failed: %v", err)
+
+		if aws.StringValue(res.NextMarker) == "" {
+			return fileSystems, nil
+		}
+		describeFsInput.Marker = res.NextMarker
 	}
+}
 
-	mountTargets := res.MountTargets
+func (c *cloud) DescribeMountTargets(ctx context.Context, fileSystemId, azName string) (fs *MountTarget, err error) {
+	mountTargets, err := c.describeMountTargets(ctx, fileSystemId)
+	if err != nil {
+		return nil, err
+	}
 	if len(mountTargets) == 0 {
-		return nil, fmt.Errorf("Cannot find mount targets for file system %v. Please create mount targets for file system.", fileSystemId)
+		return nil, ErrNoMountTargets
 	}
 
 	availableMountTargets := getAvailableMountTargets(mountTargets)
@@ -377,20 +602,76 @@ func (c *cloud) DescribeMountTargets(ctx context.Context, fileSystemId, azName s
 		mountTarget = getMountTargetForAz(availableMountTargets, azName)
 	}
 
-	// Pick random Mount target from available mount target if azName is not provided.
-	// Or if there is no mount target matching azName
+	// Pick the first available mount target if azName is not provided, or if there is no mount target matching
+	// azName. They are sorted, so every caller settles on the same one.
 	if mountTarget == nil {
-		klog.Infof("Picking a random mount target from available mount target")
-		rand.Seed(time.Now().Unix())
-		mountTarget = availableMountTargets[rand.Intn(len(availableMountTargets))]
+		klog.Infof("Picking the first available mount target ordered by AZ name, subnet ID and IP address")
+		mountTarget = availableMountTargets[0]
 	}
 
+	return newMountTarget(mountTarget), nil
+}
+
+// DescribeMountTargetInSubnet returns the available mount target of the file system in the subnet,
+// or ErrNotFound if there is none.
+func (c *cloud) DescribeMountTargetInSubnet(ctx context.Context, fileSystemId, subnetId string) (mountTarget *MountTarget, err error) {
+	mountTargets, err := c.ListMountTargets(ctx, fileSystemId)
+	if err != nil {
+		return nil, err
+	}
+	for _, mt := range mountTargets {
+		if mt.SubnetId == subnetId {
+			return mt, nil
+		}
+	}
+	klog.V(2).Infof("File System %v has no available mount target in subnet %v", fileSystemId, subnetId)
+	return nil, ErrNotFound
+}
+
+// ListMountTargets returns the available mount targets of the file system, ordered by AZ name, subnet ID and IP
+// address.
+func (c *cloud) ListMountTargets(ctx context.Context, fileSystemId string) (mountTargets []*MountTarget, err error) {
+	describedMountTargets, err := c.describeMountTargets(ctx, fileSystemId)
+	if err != nil {
+		return nil, err
+	}
+
+	mountTargets = []*MountTarget{}
+	for _, mt := range getAvailableMountTargets(describedMountTargets) {
+		mountTargets = append(mountTargets, newMountTarget(mt))
+	}
+	return mountTargets, nil
+}
+
+// describeMountTargets returns every mount target of the file system, sorted before any of them are filtered so
+// that the pick does not depend on the order EFS returns them in.
+func (c *cloud) describeMountTargets(ctx context.Context, fileSystemId string) ([]*efs.MountTargetDescription, error) {
+	describeMtInput := &efs.DescribeMountTargetsInput{FileSystemId: &fileSystemId}
+	klog.V(5).Infof("Calling DescribeMountTargets with input: %+v", *describeMtInput)
+	res, err := c.efs.DescribeMountTargetsWithContext(ctx, describeMtInput)
+	if err != nil {
+		if isAccessDenied(err) {
+			return nil, ErrAccessDenied
+		}
+		if isFileSystemNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("Describe Mount Targets failed: %v", err)
+	}
+
+	sortMountTargets(res.MountTargets)
+	return res.MountTargets, nil
+}
+
+func newMountTarget(mountTarget *efs.MountTargetDescription) *MountTarget {
 	return &MountTarget{
 		AZName:        *mountTarget.AvailabilityZoneName,
 		AZId:          *mountTarget.AvailabilityZoneId,
 		MountTargetId: *mountTarget.MountTargetId,
 		IPAddress:     *mountTarget.IpAddress,
-	}, nil
+		SubnetId:      aws.StringValue(mountTarget.SubnetId),
+		VpcId:         aws.StringValue(mountTarget.VpcId),
+	}
 }
 
 func isFileSystemNotFound(err error) bool {
@@ -411,6 +692,40 @@ func isAccessPointNotFound(err error) bool {
 	return false
 }
 
+// isAccessPointAlreadyExists reports whether an access point was already created with the client token, but with
+// different parameters.
+func isAccessPointAlreadyExists(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		if awsErr.Code() == efs.ErrCodeAccessPointAlreadyExists {
+			return true
+		}
+	}
+	return false
+}
+
+func isIncorrectLifeCycleState(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		if awsErr.Code() == efs.ErrCodeIncorrectFileSystemLifeCycleState {
+			return true
+		}
+	}
+	return false
+}
+
+// isTagPolicyViolation reports whether a request was rejected because its tags do not comply with an AWS
+// Organizations tag policy, which EFS returns as a validation error naming the policy.
+func isTagPolicyViolation(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		switch awsErr.Code() {
+		case "TagPolicyViolation", "TagPolicyException":
+			return true
+		case efs.ErrCodeBadRequest, "ValidationException":
+			return strings.Contains(strings.ToLower(awsErr.Message()), "tag polic")
+		}
+	}
+	return false
+}
+
 func isAccessDenied(err error) bool {
 	if awsErr, ok := err.(awserr.Error); ok {
 		if awsErr.Code() == AccessDeniedException {
@@ -420,6 +735,14 @@ func isAccessDenied(err error) bool {
 	return false
 }
 
+func isMaxSessionDurationExceeded(err error) bool {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Code() == "ValidationError" && strings.Contains(awsErr.Message(), "MaxSessionDuration")
+	}
+	return false
+}
+
 func isDriverBootedInECS() bool {
 	ecsContainerMetadataUri := os.Getenv(taskMetadataV4EnvName)
 	return ecsContainerMetadataUri != ""
@@ -438,6 +761,14 @@ func parseEfsTags(tagMap map[string]string) []*efs.Tag {
 	return efsTags
 }
 
+func parseEfsTagsToMap(efsTags []*efs.Tag) map[string]string {
+	tagMap := make(map[string]string, len(efsTags))
+	for _, tag := range efsTags {
+		tagMap[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	return tagMap
+}
+
 func getAvailableMountTargets(mountTargets []*efs.MountTargetDescription) []*efs.MountTargetDescription {
 	availableMountTargets := []*efs.MountTargetDescription{}
 	for _, mt := range mountTargets {
@@ -449,6 +780,30 @@ func getAvailableMountTargets(mountTargets []*efs.MountTargetDescription) []*efs
 	return availableMountTargets
 }
 
+// sortMountTargets orders mount targets by AZ name, then subnet ID, then IP address.
+func sortMountTargets(mountTargets []*efs.MountTargetDescription) {
+	sort.SliceStable(mountTargets, func(i, j int) bool {
+		a, b := mountTargets[i], mountTargets[j]
+		if aws.StringValue(a.AvailabilityZoneName) != aws.StringValue(b.AvailabilityZoneName) {
+			return aws.StringValue(a.AvailabilityZoneName) < aws.StringValue(b.AvailabilityZoneName)
+		}
+		if aws.StringValue(a.SubnetId) != aws.StringValue(b.SubnetId) {
+			return aws.StringValue(a.SubnetId) < aws.StringValue(b.SubnetId)
+		}
+		return compareIPs(aws.StringValue(a.IpAddress), aws.StringValue(b.IpAddress)) < 0
+	})
+}
+
+// compareIPs compares two IP addresses numerically, falling back to a string comparison
+// when either of them cannot be parsed.
+func compareIPs(a, b string) int {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return strings.Compare(a, b)
+	}
+	return bytes.Compare(ipA.To16(), ipB.To16())
+}
+
 func getMountTargetForAz(mountTargets []*efs.MountTargetDescription, azName string) *efs.MountTargetDescription {
 	for _, mt := range mountTargets {
 		if *mt.AvailabilityZoneName == azName {
@@ -3,12 +3,20 @@ package cloud
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/efs"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/golang/mock/gomock"
 	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/cloud/mocks"
 )
@@ -101,6 +109,86 @@ func TestCreateAccessPoint(t *testing.T) {
 				mockCtl.Finish()
 			},
 		},
+		{
+			name: "Success - AP is created with secondary gids",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockCtl)
+				c := &cloud{
+					efs: mockEfs,
+				}
+
+				req := &AccessPointOptions{
+					FileSystemId:   fsId,
+					Uid:            uid,
+					Gid:            gid,
+					SecondaryGids:  []int64{2000, 2001},
+					DirectoryPerms: directoryPerms,
+					DirectoryPath:  directoryPath,
+				}
+
+				output := &efs.CreateAccessPointOutput{
+					AccessPointId: aws.String(accessPointId),
+					FileSystemId:  aws.String(fsId),
+				}
+
+				ctx := context.Background()
+				mockEfs.EXPECT().CreateAccessPointWithContext(gomock.Eq(ctx), gomock.Any()).Return(output, nil).
+					Do(func(ctx context.Context, input *efs.CreateAccessPointInput, opts ...request.Option) {
+						secondaryGids := aws.Int64ValueSlice(input.PosixUser.SecondaryGids)
+						if !reflect.DeepEqual(secondaryGids, []int64{2000, 2001}) {
+							t.Fatalf("SecondaryGids mismatched. Expected: %v, Actual: %v", []int64{2000, 2001}, secondaryGids)
+						}
+					})
+				_, err := c.CreateAccessPoint(ctx, clientToken, req, false)
+				if err != nil {
+					t.Fatalf("CreateAccessPointFailed is failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success - AP root directory is owned by a different user",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockCtl)
+				c := &cloud{
+					efs: mockEfs,
+				}
+
+				req := &AccessPointOptions{
+					FileSystemId:   fsId,
+					Uid:            uid,
+					Gid:            gid,
+					OwnerUid:       0,
+					OwnerGid:       2000,
+					DirectoryPerms: directoryPerms,
+					DirectoryPath:  directoryPath,
+				}
+
+				output := &efs.CreateAccessPointOutput{
+					AccessPointId: aws.String(accessPointId),
+					FileSystemId:  aws.String(fsId),
+				}
+
+				ctx := context.Background()
+				mockEfs.EXPECT().CreateAccessPointWithContext(gomock.Eq(ctx), gomock.Any()).Return(output, nil).
+					Do(func(ctx context.Context, input *efs.CreateAccessPointInput, opts ...request.Option) {
+						if *input.PosixUser.Uid != uid || *input.PosixUser.Gid != gid {
+							t.Fatalf("PosixUser mismatched. Expected: %v:%v, Actual: %v:%v", uid, gid, *input.PosixUser.Uid, *input.PosixUser.Gid)
+						}
+						creationInfo := input.RootDirectory.CreationInfo
+						if *creationInfo.OwnerUid != 0 || *creationInfo.OwnerGid != 2000 {
+							t.Fatalf("Root directory owner mismatched. Expected: 0:2000, Actual: %v:%v", *creationInfo.OwnerUid, *creationInfo.OwnerGid)
+						}
+					})
+				_, err := c.CreateAccessPoint(ctx, clientToken, req, false)
+				if err != nil {
+					t.Fatalf("CreateAccessPointFailed is failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
 		{
 			name: "Success - AP already exists",
 			testFunc: func(t *testing.T) {
@@ -205,6 +293,161 @@ func TestCreateAccessPoint(t *testing.T) {
 				mockCtl.Finish()
 			},
 		},
+		{
+			name: "Success: Creates the access point when access points cannot be listed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockCtl)
+				c := &cloud{efs: mockEfs}
+
+				req := &AccessPointOptions{
+					FileSystemId:   fsId,
+					Uid:            uid,
+					Gid:            gid,
+					DirectoryPerms: directoryPerms,
+					DirectoryPath:  directoryPath,
+				}
+				output := &efs.CreateAccessPointOutput{
+					AccessPointId: aws.String(accessPointId),
+					FileSystemId:  aws.String(fsId),
+				}
+
+				ctx := context.Background()
+				mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Eq(ctx), gomock.Any()).Return(nil, awserr.New(AccessDeniedException, "Access Denied", errors.New("Access Denied")))
+				mockEfs.EXPECT().CreateAccessPointWithContext(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
+				res, err := c.CreateAccessPoint(ctx, clientToken, req, true)
+				if err != nil {
+					t.Fatalf("CreateAccessPoint failed: %v", err)
+				}
+				if res.AccessPointId != accessPointId {
+					t.Fatalf("AccessPointId mismatched. Expected: %v, Actual: %v", accessPointId, res.AccessPointId)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Access Point already exists with the client token",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockCtl)
+				c := &cloud{efs: mockEfs}
+
+				req := &AccessPointOptions{
+					FileSystemId:   fsId,
+					Uid:            uid,
+					Gid:            gid,
+					DirectoryPerms: directoryPerms,
+					DirectoryPath:  directoryPath,
+				}
+
+				ctx := context.Background()
+				mockEfs.EXPECT().CreateAccessPointWithContext(gomock.Eq(ctx), gomock.Any()).Return(nil, awserr.New(efs.ErrCodeAccessPointAlreadyExists, "Access point already exists", errors.New("Access point already exists")))
+				_, err := c.CreateAccessPoint(ctx, clientToken, req, false)
+				if err != ErrAlreadyExists {
+					t.Fatalf("Failed. Expected: %v, Actual:%v", ErrAlreadyExists, err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: File System is being modified",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockCtl)
+				c := &cloud{efs: mockEfs}
+
+				req := &AccessPointOptions{
+					FileSystemId:   fsId,
+					Uid:            uid,
+					Gid:            gid,
+					DirectoryPerms: directoryPerms,
+					DirectoryPath:  directoryPath,
+				}
+
+				ctx := context.Background()
+				mockEfs.EXPECT().CreateAccessPointWithContext(gomock.Eq(ctx), gomock.Any()).Return(nil, awserr.New(efs.ErrCodeIncorrectFileSystemLifeCycleState, "File system is updating", errors.New("File system is updating")))
+				_, err := c.CreateAccessPoint(ctx, clientToken, req, false)
+				if err != ErrIncorrectLifeCycleState {
+					t.Fatalf("Failed. Expected: %v, Actual:%v", ErrIncorrectLifeCycleState, err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Request is throttled",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockCtl)
+				c := &cloud{efs: mockEfs}
+
+				req := &AccessPointOptions{
+					FileSystemId:   fsId,
+					Uid:            uid,
+					Gid:            gid,
+					DirectoryPerms: directoryPerms,
+					DirectoryPath:  directoryPath,
+				}
+
+				ctx := context.Background()
+				mockEfs.EXPECT().CreateAccessPointWithContext(gomock.Eq(ctx), gomock.Any()).Return(nil, awserr.New("ThrottlingException", "Rate exceeded", errors.New("Rate exceeded")))
+				_, err := c.CreateAccessPoint(ctx, clientToken, req, false)
+				if err != ErrThrottled {
+					t.Fatalf("Failed. Expected: %v, Actual:%v", ErrThrottled, err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Tags do not comply with the tag policy",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockCtl)
+				c := &cloud{efs: mockEfs}
+
+				req := &AccessPointOptions{
+					FileSystemId:   fsId,
+					Uid:            uid,
+					Gid:            gid,
+					DirectoryPerms: directoryPerms,
+					DirectoryPath:  directoryPath,
+					Tags:           map[string]string{"CostCenter": "abc"},
+				}
+
+				ctx := context.Background()
+				message := "The tag policy does not allow the specified value for the following tag key: 'CostCenter'."
+				mockEfs.EXPECT().CreateAccessPointWithContext(gomock.Eq(ctx), gomock.Any()).Return(nil, awserr.New(efs.ErrCodeBadRequest, message, errors.New(message)))
+				_, err := c.CreateAccessPoint(ctx, clientToken, req, false)
+				if err != ErrTagPolicyViolation {
+					t.Fatalf("Failed. Expected: %v, Actual:%v", ErrTagPolicyViolation, err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Other bad requests are not tag policy violations",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockCtl)
+				c := &cloud{efs: mockEfs}
+
+				req := &AccessPointOptions{
+					FileSystemId:   fsId,
+					Uid:            uid,
+					Gid:            gid,
+					DirectoryPerms: directoryPerms,
+					DirectoryPath:  directoryPath,
+				}
+
+				ctx := context.Background()
+				message := "Invalid root directory path"
+				mockEfs.EXPECT().CreateAccessPointWithContext(gomock.Eq(ctx), gomock.Any()).Return(nil, awserr.New(efs.ErrCodeBadRequest, message, errors.New(message)))
+				_, err := c.CreateAccessPoint(ctx, clientToken, req, false)
+				if err == nil || err == ErrTagPolicyViolation {
+					t.Fatalf("Failed. Expected a generic error, Actual:%v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -339,6 +582,9 @@ func TestDescribeAccessPoint(t *testing.T) {
 								},
 								Path: aws.String(directoryPath),
 							},
+							Tags: []*efs.Tag{
+								{Key: aws.String("efs.csi.aws.com/availability-zone"), Value: aws.String("us-east-1a")},
+							},
 						},
 					},
 					NextToken: nil,
@@ -361,6 +607,10 @@ func TestDescribeAccessPoint(t *testing.T) {
 				if fsId != res.FileSystemId {
 					t.Fatalf("FileSystemId mismatched. Expected: %v, Actual: %v", fsId, res.FileSystemId)
 				}
+
+				if res.Tags["efs.csi.aws.com/availability-zone"] != "us-east-1a" {
+					t.Fatalf("Tags mismatched. Expected the availability zone tag, Actual: %v", res.Tags)
+				}
 				mockctl.Finish()
 			},
 		},
@@ -601,80 +851,320 @@ func TestListAccessPoints(t *testing.T) {
 			},
 		},
 		{
-			name: "Fail - Access Denied",
+			name: "Success - access point tags are returned",
 			testFunc: func(t *testing.T) {
 				mockctl := gomock.NewController(t)
 				mockEfs := mocks.NewMockEfs(mockctl)
 				c := &cloud{efs: mockEfs}
+
+				output := &efs.DescribeAccessPointsOutput{
+					AccessPoints: []*efs.AccessPointDescription{
+						{
+							AccessPointId: aws.String(accessPointId),
+							FileSystemId:  aws.String(fsId),
+							PosixUser: &efs.PosixUser{
+								Gid: aws.Int64(Gid),
+								Uid: aws.Int64(Uid),
+							},
+							Tags: []*efs.Tag{
+								{Key: aws.String("efs.csi.aws.com/cluster"), Value: aws.String("true")},
+								{Key: aws.String("team"), Value: aws.String("storage")},
+							},
+						},
+					},
+				}
+
 				ctx := context.Background()
-				mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Eq(ctx), gomock.Any()).Return(nil, awserr.New(AccessDeniedException, "Access Denied", errors.New("Access Denied")))
-				_, err := c.ListAccessPoints(ctx, fsId)
-				if err == nil {
-					t.Fatalf("List Access Points should have failed: %v", err)
+				mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
+				res, err := c.ListAccessPoints(ctx, fsId)
+				if err != nil {
+					t.Fatalf("List Access Points failed: %v", err)
+				}
+
+				expectedTags := map[string]string{"efs.csi.aws.com/cluster": "true", "team": "storage"}
+				if !reflect.DeepEqual(res[0].Tags, expectedTags) {
+					t.Fatalf("Tags mismatched. Expected: %v, Actual: %v", expectedTags, res[0].Tags)
 				}
 
 				mockctl.Finish()
 			},
 		},
-	}
-	for _, tc := range testCases {
-		t.Run(tc.name, tc.testFunc)
-	}
-}
-
-func TestDescribeFileSystem(t *testing.T) {
-	var (
-		fsId = "fs-abcd1234"
-	)
-	testCases := []struct {
-		name     string
-		testFunc func(t *testing.T)
-	}{
 		{
-			name: "Success",
+			name: "Success - client token, root directory and posix user are reported",
 			testFunc: func(t *testing.T) {
 				mockctl := gomock.NewController(t)
 				mockEfs := mocks.NewMockEfs(mockctl)
 				c := &cloud{efs: mockEfs}
 
-				output := &efs.DescribeFileSystemsOutput{
-					FileSystems: []*efs.FileSystemDescription{
+				output := &efs.DescribeAccessPointsOutput{
+					AccessPoints: []*efs.AccessPointDescription{
 						{
-							CreationToken: aws.String("test"),
-							Encrypted:     aws.Bool(true),
+							AccessPointId: aws.String(accessPointId),
 							FileSystemId:  aws.String(fsId),
-							Name:          aws.String("test"),
-							OwnerId:       aws.String("1234567890"),
+							ClientToken:   aws.String("pvc-1234"),
+							PosixUser: &efs.PosixUser{
+								Gid: aws.Int64(1002),
+								Uid: aws.Int64(1001),
+							},
+							RootDirectory: &efs.RootDirectory{
+								Path: aws.String("/pvc-1234"),
+							},
 						},
 					},
 				}
 
 				ctx := context.Background()
-				mockEfs.EXPECT().DescribeFileSystemsWithContext(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
-				res, err := c.DescribeFileSystem(ctx, fsId)
+				mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
+				res, err := c.ListAccessPoints(ctx, fsId)
 				if err != nil {
-					t.Fatalf("Describe File System failed: %v", err)
+					t.Fatalf("List Access Points failed: %v", err)
 				}
 
-				if res == nil {
-					t.Fatal("Result is nil")
+				expected := &AccessPoint{
+					AccessPointId:      accessPointId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-1234",
+					ClientToken:        "pvc-1234",
+					PosixUser:          &PosixUser{Gid: 1002, Uid: 1001},
+					Tags:               map[string]string{},
 				}
-
-				if fsId != res.FileSystemId {
-					t.Fatalf("FileSystemId mismatched. Expected: %v, Actual: %v", fsId, res.FileSystemId)
+				if !reflect.DeepEqual(res[0], expected) {
+					t.Fatalf("Access Point mismatched. Expected: %+v, Actual: %+v", expected, res[0])
 				}
+
 				mockctl.Finish()
 			},
 		},
 		{
-			name: "Fail: DescribeFileSystems result has 0 file systems",
+			name: "Success - access points across pages",
 			testFunc: func(t *testing.T) {
 				mockctl := gomock.NewController(t)
 				mockEfs := mocks.NewMockEfs(mockctl)
 				c := &cloud{efs: mockEfs}
 
-				output := &efs.DescribeFileSystemsOutput{
-					FileSystems: []*efs.FileSystemDescription{},
+				page := func(first, count int) []*efs.AccessPointDescription {
+					var accessPoints []*efs.AccessPointDescription
+					for i := first; i < first+count; i++ {
+						accessPoints = append(accessPoints, &efs.AccessPointDescription{
+							AccessPointId: aws.String(fmt.Sprintf("fsap-%d", i)),
+							FileSystemId:  aws.String(fsId),
+							PosixUser: &efs.PosixUser{
+								Gid: aws.Int64(int64(i)),
+								Uid: aws.Int64(int64(i)),
+							},
+						})
+					}
+					return accessPoints
+				}
+
+				ctx := context.Background()
+				gomock.InOrder(
+					mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Eq(ctx), gomock.Eq(&efs.DescribeAccessPointsInput{FileSystemId: aws.String(fsId)})).Return(&efs.DescribeAccessPointsOutput{
+						AccessPoints: page(0, 100),
+						NextToken:    aws.String("page-2"),
+					}, nil),
+					mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Eq(ctx), gomock.Eq(&efs.DescribeAccessPointsInput{FileSystemId: aws.String(fsId), NextToken: aws.String("page-2")})).Return(&efs.DescribeAccessPointsOutput{
+						AccessPoints: page(100, 100),
+						NextToken:    aws.String("page-3"),
+					}, nil),
+					mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Eq(ctx), gomock.Eq(&efs.DescribeAccessPointsInput{FileSystemId: aws.String(fsId), NextToken: aws.String("page-3")})).Return(&efs.DescribeAccessPointsOutput{
+						AccessPoints: page(200, 5),
+					}, nil),
+				)
+				res, err := c.ListAccessPoints(ctx, fsId)
+				if err != nil {
+					t.Fatalf("List Access Points failed: %v", err)
+				}
+
+				if len(res) != 205 {
+					t.Fatalf("Expected 205 AccessPoints in response but got: %d", len(res))
+				}
+				if res[204].AccessPointId != "fsap-204" {
+					t.Fatalf("Expected the last AccessPoint to be fsap-204 but got: %v", res[204].AccessPointId)
+				}
+
+				mockctl.Finish()
+			},
+		},
+		{
+			name: "Success - access point without posix user",
+			testFunc: func(t *testing.T) {
+				mockctl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockctl)
+				c := &cloud{efs: mockEfs}
+
+				output := &efs.DescribeAccessPointsOutput{
+					AccessPoints: []*efs.AccessPointDescription{
+						{
+							AccessPointId: aws.String(accessPointId),
+							FileSystemId:  aws.String(fsId),
+							RootDirectory: &efs.RootDirectory{
+								Path: aws.String("/static"),
+							},
+						},
+					},
+				}
+
+				ctx := context.Background()
+				mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
+				res, err := c.ListAccessPoints(ctx, fsId)
+				if err != nil {
+					t.Fatalf("List Access Points failed: %v", err)
+				}
+
+				if len(res) != 1 || res[0].PosixUser != nil {
+					t.Fatalf("Expected one AccessPoint without a PosixUser but got: %+v", res)
+				}
+
+				mockctl.Finish()
+			},
+		},
+		{
+			name: "Success - allocated gid tag is returned without posix user",
+			testFunc: func(t *testing.T) {
+				mockctl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockctl)
+				c := &cloud{efs: mockEfs}
+
+				output := &efs.DescribeAccessPointsOutput{
+					AccessPoints: []*efs.AccessPointDescription{
+						{
+							AccessPointId: aws.String(accessPointId),
+							FileSystemId:  aws.String(fsId),
+							Tags: []*efs.Tag{
+								{Key: aws.String("efs.csi.aws.com/cluster"), Value: aws.String("true")},
+								{Key: aws.String("efs.csi.aws.com/allocated-gid"), Value: aws.String("50001")},
+							},
+						},
+					},
+				}
+
+				ctx := context.Background()
+				mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
+				res, err := c.ListAccessPoints(ctx, fsId)
+				if err != nil {
+					t.Fatalf("List Access Points failed: %v", err)
+				}
+
+				expected := &AccessPoint{
+					AccessPointId: accessPointId,
+					FileSystemId:  fsId,
+					Tags:          map[string]string{"efs.csi.aws.com/cluster": "true", "efs.csi.aws.com/allocated-gid": "50001"},
+				}
+				if !reflect.DeepEqual(res[0], expected) {
+					t.Fatalf("Access Point mismatched. Expected: %+v, Actual: %+v", expected, res[0])
+				}
+
+				mockctl.Finish()
+			},
+		},
+		{
+			name: "Fail - Access Denied",
+			testFunc: func(t *testing.T) {
+				mockctl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockctl)
+				c := &cloud{efs: mockEfs}
+				ctx := context.Background()
+				mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Eq(ctx), gomock.Any()).Return(nil, awserr.New(AccessDeniedException, "Access Denied", errors.New("Access Denied")))
+				_, err := c.ListAccessPoints(ctx, fsId)
+				if err != ErrAccessDenied {
+					t.Fatalf("Expected List Access Points to fail with %v, got: %v", ErrAccessDenied, err)
+				}
+
+				mockctl.Finish()
+			},
+		},
+		{
+			name: "Fail - File System Not Found",
+			testFunc: func(t *testing.T) {
+				mockctl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockctl)
+				c := &cloud{efs: mockEfs}
+				ctx := context.Background()
+				mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Eq(ctx), gomock.Any()).Return(nil, awserr.New(efs.ErrCodeFileSystemNotFound, "File System not found", errors.New("File System not found")))
+				_, err := c.ListAccessPoints(ctx, fsId)
+				if err != ErrNotFound {
+					t.Fatalf("Expected List Access Points to fail with %v, got: %v", ErrNotFound, err)
+				}
+
+				mockctl.Finish()
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestDescribeFileSystem(t *testing.T) {
+	var (
+		fsId = "fs-abcd1234"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Success",
+			testFunc: func(t *testing.T) {
+				mockctl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockctl)
+				c := &cloud{efs: mockEfs}
+
+				output := &efs.DescribeFileSystemsOutput{
+					FileSystems: []*efs.FileSystemDescription{
+						{
+							CreationToken:  aws.String("test"),
+							Encrypted:      aws.Bool(true),
+							FileSystemId:   aws.String(fsId),
+							LifeCycleState: aws.String(efs.LifeCycleStateAvailable),
+							Name:           aws.String("test"),
+							OwnerId:        aws.String("1234567890"),
+							Tags: []*efs.Tag{
+								{Key: aws.String("CostCenter"), Value: aws.String("1234")},
+							},
+						},
+					},
+				}
+
+				ctx := context.Background()
+				mockEfs.EXPECT().DescribeFileSystemsWithContext(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
+				res, err := c.DescribeFileSystem(ctx, fsId)
+				if err != nil {
+					t.Fatalf("Describe File System failed: %v", err)
+				}
+
+				if res == nil {
+					t.Fatal("Result is nil")
+				}
+
+				if fsId != res.FileSystemId {
+					t.Fatalf("FileSystemId mismatched. Expected: %v, Actual: %v", fsId, res.FileSystemId)
+				}
+
+				if !res.Encrypted {
+					t.Fatal("Expected the file system to be encrypted")
+				}
+
+				if res.LifeCycleState != efs.LifeCycleStateAvailable {
+					t.Fatalf("LifeCycleState mismatched. Expected: %v, Actual: %v", efs.LifeCycleStateAvailable, res.LifeCycleState)
+				}
+
+				if res.Tags["CostCenter"] != "1234" {
+					t.Fatalf("Tags mismatched. Expected CostCenter: 1234, Actual: %v", res.Tags)
+				}
+				mockctl.Finish()
+			},
+		},
+		{
+			name: "Fail: DescribeFileSystems result has 0 file systems",
+			testFunc: func(t *testing.T) {
+				mockctl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockctl)
+				c := &cloud{efs: mockEfs}
+
+				output := &efs.DescribeFileSystemsOutput{
+					FileSystems: []*efs.FileSystemDescription{},
 				}
 
 				ctx := context.Background()
@@ -781,6 +1271,57 @@ func TestDescribeFileSystem(t *testing.T) {
 	}
 }
 
+func TestFindFileSystemByTag(t *testing.T) {
+	fileSystem := func(fsId, name string) *efs.FileSystemDescription {
+		return &efs.FileSystemDescription{
+			FileSystemId:   aws.String(fsId),
+			LifeCycleState: aws.String(efs.LifeCycleStateAvailable),
+			Tags: []*efs.Tag{
+				{Key: aws.String("Name"), Value: aws.String(name)},
+			},
+		}
+	}
+
+	t.Run("Success: matches across pages", func(t *testing.T) {
+		mockctl := gomock.NewController(t)
+		defer mockctl.Finish()
+		mockEfs := mocks.NewMockEfs(mockctl)
+		c := &cloud{efs: mockEfs}
+
+		ctx := context.Background()
+		gomock.InOrder(
+			mockEfs.EXPECT().DescribeFileSystemsWithContext(gomock.Eq(ctx), gomock.Eq(&efs.DescribeFileSystemsInput{})).Return(&efs.DescribeFileSystemsOutput{
+				FileSystems: []*efs.FileSystemDescription{fileSystem("fs-abcd1234", "shared"), fileSystem("fs-bcde2345", "other")},
+				NextMarker:  aws.String("page-2"),
+			}, nil),
+			mockEfs.EXPECT().DescribeFileSystemsWithContext(gomock.Eq(ctx), gomock.Eq(&efs.DescribeFileSystemsInput{Marker: aws.String("page-2")})).Return(&efs.DescribeFileSystemsOutput{
+				FileSystems: []*efs.FileSystemDescription{fileSystem("fs-cdef3456", "shared")},
+			}, nil),
+		)
+
+		fileSystems, err := c.FindFileSystemByTag(ctx, "Name", "shared")
+		if err != nil {
+			t.Fatalf("FindFileSystemByTag failed: %v", err)
+		}
+		if len(fileSystems) != 2 || fileSystems[0].FileSystemId != "fs-abcd1234" || fileSystems[1].FileSystemId != "fs-cdef3456" {
+			t.Fatalf("Expected fs-abcd1234 and fs-cdef3456, got: %+v", fileSystems)
+		}
+	})
+
+	t.Run("Fail: Access Denied", func(t *testing.T) {
+		mockctl := gomock.NewController(t)
+		defer mockctl.Finish()
+		mockEfs := mocks.NewMockEfs(mockctl)
+		c := &cloud{efs: mockEfs}
+
+		ctx := context.Background()
+		mockEfs.EXPECT().DescribeFileSystemsWithContext(gomock.Eq(ctx), gomock.Any()).Return(nil, awserr.New(AccessDeniedException, "Access Denied", errors.New("Access Denied")))
+		if _, err := c.FindFileSystemByTag(ctx, "Name", "shared"); err != ErrAccessDenied {
+			t.Fatalf("Expected ErrAccessDenied, got: %v", err)
+		}
+	})
+}
+
 func TestDescribeMountTargets(t *testing.T) {
 	var (
 		fsId = "fs-abcd1234"
@@ -814,7 +1355,7 @@ func TestDescribeMountTargets(t *testing.T) {
 			expectError: errtyp{},
 		},
 		{
-			name: "Success: Mount target with preferred AZ does not exist. Pick random Az.",
+			name: "Success: Mount target with preferred AZ does not exist. Pick first available mount target.",
 			mockOutput: &efs.DescribeMountTargetsOutput{
 				MountTargets: []*efs.MountTargetDescription{
 					{
@@ -839,7 +1380,7 @@ func TestDescribeMountTargets(t *testing.T) {
 			},
 			expectError: errtyp{
 				code:    "",
-				message: "Cannot find mount targets for file system fs-abcd1234. Please create mount targets for file system.",
+				message: "File system has no mount targets",
 			},
 		},
 		{
@@ -904,6 +1445,133 @@ func TestDescribeMountTargets(t *testing.T) {
 	}
 }
 
+func TestDescribeMountTargetsDeterministicSelection(t *testing.T) {
+	fsId := "fs-abcd1234"
+	newMountTarget := func(az, subnet, ip string) *efs.MountTargetDescription {
+		return &efs.MountTargetDescription{
+			AvailabilityZoneId:   aws.String(az + "-id"),
+			AvailabilityZoneName: aws.String(az),
+			FileSystemId:         aws.String(fsId),
+			IpAddress:            aws.String(ip),
+			LifeCycleState:       aws.String("available"),
+			MountTargetId:        aws.String("fsmt-" + subnet + "-" + ip),
+			SubnetId:             aws.String(subnet),
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		mountTargets []*efs.MountTargetDescription
+		azName       string
+		expectedIp   string
+	}{
+		{
+			name: "Lowest AZ name wins",
+			mountTargets: []*efs.MountTargetDescription{
+				newMountTarget("us-east-1c", "subnet-a", "10.0.0.1"),
+				newMountTarget("us-east-1a", "subnet-z", "10.0.9.9"),
+				newMountTarget("us-east-1b", "subnet-a", "10.0.0.2"),
+			},
+			expectedIp: "10.0.9.9",
+		},
+		{
+			name: "Subnet ID breaks an AZ tie",
+			mountTargets: []*efs.MountTargetDescription{
+				newMountTarget("us-east-1a", "subnet-c", "10.0.0.1"),
+				newMountTarget("us-east-1a", "subnet-b", "10.0.0.3"),
+				newMountTarget("us-east-1a", "subnet-d", "10.0.0.2"),
+			},
+			expectedIp: "10.0.0.3",
+		},
+		{
+			name: "IP address breaks a subnet tie numerically",
+			mountTargets: []*efs.MountTargetDescription{
+				newMountTarget("us-east-1a", "subnet-a", "10.0.0.10"),
+				newMountTarget("us-east-1a", "subnet-a", "10.0.0.9"),
+				newMountTarget("us-east-1a", "subnet-a", "10.0.0.100"),
+			},
+			expectedIp: "10.0.0.9",
+		},
+		{
+			name: "Lowest subnet ID wins in the requested AZ",
+			mountTargets: []*efs.MountTargetDescription{
+				newMountTarget("us-east-1a", "subnet-a", "10.0.0.1"),
+				newMountTarget("us-east-1b", "subnet-c", "10.0.1.2"),
+				newMountTarget("us-east-1b", "subnet-b", "10.0.1.1"),
+			},
+			azName:     "us-east-1b",
+			expectedIp: "10.0.1.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Try every rotation of the input to make sure the order returned by EFS does not matter.
+			for i := range tc.mountTargets {
+				shuffled := append(append([]*efs.MountTargetDescription{}, tc.mountTargets[i:]...), tc.mountTargets[:i]...)
+
+				mockctl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockctl)
+				c := &cloud{efs: mockEfs}
+				ctx := context.Background()
+
+				mockEfs.EXPECT().DescribeMountTargetsWithContext(gomock.Eq(ctx), gomock.Any(), gomock.Any()).Return(&efs.DescribeMountTargetsOutput{MountTargets: shuffled}, nil)
+				res, err := c.DescribeMountTargets(ctx, fsId, tc.azName)
+				if err != nil {
+					t.Fatalf("DescribeMountTargets failed: %v", err)
+				}
+				if res.IPAddress != tc.expectedIp {
+					t.Fatalf("Mount target mismatched for rotation %d. Expected IP: %v, actual: %v", i, tc.expectedIp, res.IPAddress)
+				}
+				mockctl.Finish()
+			}
+		})
+	}
+}
+
+func TestListMountTargetsSorted(t *testing.T) {
+	fsId := "fs-abcd1234"
+	newMountTarget := func(az, subnet, ip string) *efs.MountTargetDescription {
+		return &efs.MountTargetDescription{
+			AvailabilityZoneId:   aws.String(az + "-id"),
+			AvailabilityZoneName: aws.String(az),
+			FileSystemId:         aws.String(fsId),
+			IpAddress:            aws.String(ip),
+			LifeCycleState:       aws.String("available"),
+			MountTargetId:        aws.String("fsmt-" + subnet),
+			SubnetId:             aws.String(subnet),
+		}
+	}
+
+	mockctl := gomock.NewController(t)
+	mockEfs := mocks.NewMockEfs(mockctl)
+	c := &cloud{efs: mockEfs}
+	ctx := context.Background()
+
+	// Two mount targets in the same AZ, returned in reversed order
+	output := &efs.DescribeMountTargetsOutput{
+		MountTargets: []*efs.MountTargetDescription{
+			newMountTarget("us-east-1b", "subnet-b", "10.0.1.2"),
+			newMountTarget("us-east-1b", "subnet-a", "10.0.1.1"),
+			newMountTarget("us-east-1a", "subnet-c", "10.0.0.1"),
+		},
+	}
+	mockEfs.EXPECT().DescribeMountTargetsWithContext(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
+	res, err := c.ListMountTargets(ctx, fsId)
+	if err != nil {
+		t.Fatalf("ListMountTargets failed: %v", err)
+	}
+	var subnets []string
+	for _, mt := range res {
+		subnets = append(subnets, mt.SubnetId)
+	}
+	expected := []string{"subnet-c", "subnet-a", "subnet-b"}
+	if !reflect.DeepEqual(subnets, expected) {
+		t.Fatalf("Mount targets mismatched. Expected subnets: %v, actual: %v", expected, subnets)
+	}
+	mockctl.Finish()
+}
+
 func testResult(t *testing.T, funcName string, ret interface{}, err error, expectError errtyp) {
 	if expectError.message == "" {
 		if err != nil {
@@ -963,6 +1631,17 @@ func Test_findAccessPointByPath(t *testing.T) {
 				},
 			}, nil)
 		}, wantAccessPoint: expectedSingleAP, wantErr: false},
+		{name: "Expected_ClientToken_Found_On_Later_Page", args: args{clientToken, &AccessPointOptions{FileSystemId: fsId, DirectoryPath: dirPath}}, prepare: func(mockEfs *mocks.MockEfs) {
+			gomock.InOrder(
+				mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Any(), gomock.Any()).Return(&efs.DescribeAccessPointsOutput{
+					AccessPoints: []*efs.AccessPointDescription{{FileSystemId: aws.String(fsId), ClientToken: diffClientToken, AccessPointId: aws.String("differentApId"), RootDirectory: &efs.RootDirectory{Path: aws.String("differentPath")}}},
+					NextToken:    aws.String("page-2"),
+				}, nil),
+				mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Any(), gomock.Eq(&efs.DescribeAccessPointsInput{FileSystemId: aws.String(fsId), NextToken: aws.String("page-2")})).Return(&efs.DescribeAccessPointsOutput{
+					AccessPoints: []*efs.AccessPointDescription{{FileSystemId: aws.String(fsId), ClientToken: &clientToken, AccessPointId: aws.String(expectedSingleAP.AccessPointId), RootDirectory: &efs.RootDirectory{Path: aws.String(expectedSingleAP.AccessPointRootDir)}}},
+				}, nil),
+			)
+		}, wantAccessPoint: expectedSingleAP, wantErr: false},
 		{name: "Fail_DescribeAccessPoints", args: args{clientToken, &AccessPointOptions{FileSystemId: fsId, DirectoryPath: dirPath}}, prepare: func(mockEfs *mocks.MockEfs) {
 			mockEfs.EXPECT().DescribeAccessPointsWithContext(gomock.Any(), gomock.Any()).Return(nil, errors.New("access_denied"))
 		}, wantAccessPoint: nil, wantErr: true},
@@ -987,3 +1666,318 @@ func Test_findAccessPointByPath(t *testing.T) {
 		})
 	}
 }
+
+func TestListMountTargets(t *testing.T) {
+	var (
+		fsId = "fs-abcd1234"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Success: only available mount targets are listed",
+			testFunc: func(t *testing.T) {
+				mockctl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockctl)
+				c := &cloud{efs: mockEfs}
+
+				output := &efs.DescribeMountTargetsOutput{
+					MountTargets: []*efs.MountTargetDescription{
+						{
+							AvailabilityZoneId:   aws.String("az-id-1"),
+							AvailabilityZoneName: aws.String("us-east-1a"),
+							FileSystemId:         aws.String(fsId),
+							IpAddress:            aws.String("10.0.1.10"),
+							LifeCycleState:       aws.String("available"),
+							MountTargetId:        aws.String("fsmt-1"),
+							SubnetId:             aws.String("subnet-1"),
+							VpcId:                aws.String("vpc-1"),
+						},
+						{
+							AvailabilityZoneId:   aws.String("az-id-2"),
+							AvailabilityZoneName: aws.String("us-east-1b"),
+							FileSystemId:         aws.String(fsId),
+							IpAddress:            aws.String("10.0.2.10"),
+							LifeCycleState:       aws.String("creating"),
+							MountTargetId:        aws.String("fsmt-2"),
+							SubnetId:             aws.String("subnet-2"),
+							VpcId:                aws.String("vpc-1"),
+						},
+					},
+				}
+
+				ctx := context.Background()
+				mockEfs.EXPECT().DescribeMountTargetsWithContext(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
+				res, err := c.ListMountTargets(ctx, fsId)
+				if err != nil {
+					t.Fatalf("List Mount Targets failed: %v", err)
+				}
+
+				expected := []*MountTarget{
+					{
+						AZName:        "us-east-1a",
+						AZId:          "az-id-1",
+						MountTargetId: "fsmt-1",
+						IPAddress:     "10.0.1.10",
+						SubnetId:      "subnet-1",
+						VpcId:         "vpc-1",
+					},
+				}
+				if !reflect.DeepEqual(res, expected) {
+					t.Fatalf("Mount targets mismatched. Expected: %+v, Actual: %+v", expected[0], res)
+				}
+				mockctl.Finish()
+			},
+		},
+		{
+			name: "Fail: File System not found",
+			testFunc: func(t *testing.T) {
+				mockctl := gomock.NewController(t)
+				mockEfs := mocks.NewMockEfs(mockctl)
+				c := &cloud{efs: mockEfs}
+
+				ctx := context.Background()
+				mockEfs.EXPECT().DescribeMountTargetsWithContext(gomock.Eq(ctx), gomock.Any()).Return(nil, awserr.New(efs.ErrCodeFileSystemNotFound, "File System not found", errors.New("File System not found")))
+				_, err := c.ListMountTargets(ctx, fsId)
+				if err != ErrNotFound {
+					t.Fatalf("Expected ErrNotFound, got: %v", err)
+				}
+				mockctl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+// fakeAssumeRoler records the DurationSeconds of each AssumeRole call, 0 when it was omitted, which like STS
+// grants an hour.
+type fakeAssumeRoler struct {
+	maxSessionDuration int64
+	durations          []int64
+}
+
+func (f *fakeAssumeRoler) AssumeRole(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+	duration := aws.Int64Value(input.DurationSeconds)
+	f.durations = append(f.durations, duration)
+	if duration == 0 {
+		duration = 3600
+	}
+	if duration > f.maxSessionDuration {
+		return nil, awserr.New("ValidationError", "The requested DurationSeconds exceeds the MaxSessionDuration set for this role.", nil)
+	}
+	return &sts.AssumeRoleOutput{
+		Credentials: &sts.Credentials{
+			AccessKeyId:     aws.String("AKID"),
+			SecretAccessKey: aws.String("SECRET"),
+			SessionToken:    aws.String("TOKEN"),
+			Expiration:      aws.Time(time.Now().Add(time.Duration(duration) * time.Second)),
+		},
+	}, nil
+}
+
+func TestAssumeRoleSessionDuration(t *testing.T) {
+	testCases := []struct {
+		name              string
+		sessionDuration   time.Duration
+		maxDuration       int64
+		expectedDurations []int64
+	}{
+		{
+			name:              "Success: default session duration",
+			maxDuration:       3600,
+			expectedDurations: []int64{900, 900},
+		},
+		{
+			name:              "Success: session duration is passed through",
+			sessionDuration:   6 * time.Hour,
+			maxDuration:       43200,
+			expectedDurations: []int64{21600, 21600},
+		},
+		{
+			name:              "Success: retry with the default duration when the role's maximum session duration is exceeded",
+			sessionDuration:   12 * time.Hour,
+			maxDuration:       3600,
+			expectedDurations: []int64{43200, 0, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeAssumeRoler{maxSessionDuration: tc.maxDuration}
+			creds := newAssumeRoleCredentials(client, "arn:aws:iam::1234567890:role/EFSCrossAccountRole", tc.sessionDuration, nil)
+			if _, err := creds.Get(); err != nil {
+				t.Fatalf("Failed to get credentials: %v", err)
+			}
+			// Refreshed credentials keep the duration the role accepted
+			creds.Expire()
+			if _, err := creds.Get(); err != nil {
+				t.Fatalf("Failed to refresh credentials: %v", err)
+			}
+			if !reflect.DeepEqual(client.durations, tc.expectedDurations) {
+				t.Fatalf("AssumeRole durations mismatched. Expected: %v, Actual: %v", tc.expectedDurations, client.durations)
+			}
+		})
+	}
+}
+
+func TestAssumeRoleObserver(t *testing.T) {
+	const roleArn = "arn:aws:iam::1234567890:role/EFSCrossAccountRole"
+	testCases := []struct {
+		name        string
+		maxDuration int64
+		expectErr   bool
+	}{
+		{
+			name:        "Success: role is assumed",
+			maxDuration: 43200,
+		},
+		{
+			name:        "Fail: role cannot be assumed",
+			maxDuration: 900,
+			expectErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var observed []error
+			observe := func(awsRoleArn string, duration time.Duration, err error) {
+				if awsRoleArn != roleArn {
+					t.Errorf("Expected role %v to be observed, got %v", roleArn, awsRoleArn)
+				}
+				observed = append(observed, err)
+			}
+			creds := newAssumeRoleCredentials(&fakeAssumeRoler{maxSessionDuration: tc.maxDuration}, roleArn, time.Hour, observe)
+			if _, err := creds.Get(); (err != nil) != tc.expectErr {
+				t.Fatalf("Expected error: %v, got: %v", tc.expectErr, err)
+			}
+			// Credentials that have not expired are not retrieved again
+			creds.Get()
+			expectedCalls := 1
+			if tc.expectErr {
+				expectedCalls = 2
+			}
+			if len(observed) != expectedCalls {
+				t.Fatalf("Expected %d observed calls, got %d", expectedCalls, len(observed))
+			}
+			if (observed[0] != nil) != tc.expectErr {
+				t.Fatalf("Expected the observed call to fail: %v, got: %v", tc.expectErr, observed[0])
+			}
+		})
+	}
+}
+
+// newFakeWebIdentitySTS returns an STS client that answers AssumeRoleWithWebIdentity without calling AWS and
+// records the tokens it was called with.
+func newFakeWebIdentitySTS(t *testing.T) (*sts.STS, *[]string) {
+	var tokens []string
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.AnonymousCredentials,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	client := sts.New(sess)
+	client.Handlers.Send.Clear()
+	client.Handlers.Unmarshal.Clear()
+	client.Handlers.UnmarshalMeta.Clear()
+	client.Handlers.ValidateResponse.Clear()
+	client.Handlers.Send.PushBack(func(r *request.Request) {
+		input := r.Params.(*sts.AssumeRoleWithWebIdentityInput)
+		tokens = append(tokens, *input.WebIdentityToken)
+		r.Data.(*sts.AssumeRoleWithWebIdentityOutput).Credentials = &sts.Credentials{
+			AccessKeyId:     aws.String("AKID"),
+			SecretAccessKey: aws.String("SECRET"),
+			SessionToken:    aws.String("TOKEN"),
+			Expiration:      aws.Time(time.Now().Add(time.Hour)),
+		}
+	})
+	return client, &tokens
+}
+
+func TestWebIdentityCredentialsRereadTokenFile(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("token-1"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	client, tokens := newFakeWebIdentitySTS(t)
+	creds := newWebIdentityCredentials(client, "arn:aws:iam::1234567890:role/EFSCrossAccountRole", tokenFile, time.Hour, nil)
+	if _, err := creds.Get(); err != nil {
+		t.Fatalf("Failed to get credentials: %v", err)
+	}
+
+	// The token is rotated before the credentials expire
+	if err := os.WriteFile(tokenFile, []byte("token-2"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	creds.Expire()
+	if _, err := creds.Get(); err != nil {
+		t.Fatalf("Failed to get credentials: %v", err)
+	}
+
+	if !reflect.DeepEqual(*tokens, []string{"token-1", "token-2"}) {
+		t.Fatalf("AssumeRoleWithWebIdentity tokens mismatched. Expected: %v, Actual: %v", []string{"token-1", "token-2"}, *tokens)
+	}
+}
+
+func TestCreateClientsWithEndpointOptions(t *testing.T) {
+	testCases := []struct {
+		name        string
+		opts        EndpointOptions
+		efsEndpoint string
+		stsEndpoint string
+	}{
+		{
+			name:        "Region from metadata",
+			efsEndpoint: "https://elasticfilesystem.us-east-1.amazonaws.com",
+		},
+		{
+			name:        "Region override",
+			opts:        EndpointOptions{Region: "us-gov-west-1"},
+			efsEndpoint: "https://elasticfilesystem.us-gov-west-1.amazonaws.com",
+			stsEndpoint: "https://sts.us-gov-west-1.amazonaws.com",
+		},
+		{
+			name:        "China region override",
+			opts:        EndpointOptions{Region: "cn-north-1"},
+			efsEndpoint: "https://elasticfilesystem.cn-north-1.amazonaws.com.cn",
+			stsEndpoint: "https://sts.cn-north-1.amazonaws.com.cn",
+		},
+		{
+			name:        "FIPS",
+			opts:        EndpointOptions{UseFIPS: true},
+			efsEndpoint: "https://elasticfilesystem-fips.us-east-1.amazonaws.com",
+			stsEndpoint: "https://sts-fips.us-east-1.amazonaws.com",
+		},
+		{
+			name:        "Custom EFS endpoint",
+			opts:        EndpointOptions{Region: "us-gov-west-1", EfsEndpoint: "https://efs.example.com"},
+			efsEndpoint: "https://efs.example.com",
+			stsEndpoint: "https://sts.us-gov-west-1.amazonaws.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sess := session.Must(session.NewSession(&aws.Config{}))
+			m := &metadata{region: "us-east-1"}
+
+			client := createEfsClient("", "", 0, m, sess, tc.opts, nil).(*efs.EFS)
+			if endpoint := client.Client.ClientInfo.Endpoint; endpoint != tc.efsEndpoint {
+				t.Fatalf("EFS endpoint mismatched. Expected: %v, Actual: %v", tc.efsEndpoint, endpoint)
+			}
+
+			if tc.stsEndpoint != "" {
+				if endpoint := createStsClient(sess, m, tc.opts).Client.ClientInfo.Endpoint; endpoint != tc.stsEndpoint {
+					t.Fatalf("STS endpoint mismatched. Expected: %v, Actual: %v", tc.stsEndpoint, endpoint)
+				}
+			}
+		})
+	}
+}
@@ -90,6 +90,16 @@ func (c *FakeCloudProvider) DescribeFileSystem(ctx context.Context, fileSystemId
 	return fs, nil
 }
 
+func (c *FakeCloudProvider) FindFileSystemByTag(ctx context.Context, key, value string) ([]*FileSystem, error) {
+	var fileSystems []*FileSystem
+	for _, fs := range c.fileSystems {
+		if tagValue, ok := fs.Tags[key]; ok && tagValue == value {
+			fileSystems = append(fileSystems, fs)
+		}
+	}
+	return fileSystems, nil
+}
+
 func (c *FakeCloudProvider) DescribeMountTargets(ctx context.Context, fileSystemId, az string) (mountTarget *MountTarget, err error) {
 	if mt, ok := c.mountTargets[fileSystemId]; ok {
 		return mt, nil
@@ -98,6 +108,22 @@ func (c *FakeCloudProvider) DescribeMountTargets(ctx context.Context, fileSystem
 	return nil, ErrNotFound
 }
 
+func (c *FakeCloudProvider) DescribeMountTargetInSubnet(ctx context.Context, fileSystemId, subnetId string) (mountTarget *MountTarget, err error) {
+	if mt, ok := c.mountTargets[fileSystemId]; ok && mt.SubnetId == subnetId {
+		return mt, nil
+	}
+
+	return nil, ErrNotFound
+}
+
+func (c *FakeCloudProvider) ListMountTargets(ctx context.Context, fileSystemId string) ([]*MountTarget, error) {
+	if mt, ok := c.mountTargets[fileSystemId]; ok {
+		return []*MountTarget{mt}, nil
+	}
+
+	return nil, ErrNotFound
+}
+
 func (c *FakeCloudProvider) ListAccessPoints(ctx context.Context, fileSystemId string) ([]*AccessPoint, error) {
 	accessPoints := []*AccessPoint{
 		c.accessPoints[fileSystemId],
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/cloud"
+	"golang.org/x/sync/singleflight"
+	"k8s.io/klog/v2"
+)
+
+type cloudCacheKey struct {
+	roleArn         string
+	tokenFile       string
+	sessionDuration time.Duration
+	region          string
+}
+
+type cloudCacheEntry struct {
+	cloud   cloud.Cloud
+	expires time.Time
+}
+
+// cloudCache keeps the clouds created for cross account roles and for regions other than the driver's, so that every
+// CreateVolume and DeleteVolume does not have to build a new session and assume the role again. Entries are rebuilt
+// once they are older than ttl. Concurrent requests for the same role and region share a single creation, and never
+// wait for the creation of another.
+type cloudCache struct {
+	ttl      time.Duration
+	newCloud func(roleArn, tokenFile string, sessionDuration time.Duration, region string) (cloud.Cloud, error)
+	now      func() time.Time
+	metrics  *provisioningMetrics
+
+	group   singleflight.Group
+	mu      sync.Mutex
+	entries map[cloudCacheKey]cloudCacheEntry
+}
+
+func newCloudCache(ttl time.Duration, endpointOpts cloud.EndpointOptions, metrics *provisioningMetrics) *cloudCache {
+	return &cloudCache{
+		ttl: ttl,
+		newCloud: func(roleArn, tokenFile string, sessionDuration time.Duration, region string) (cloud.Cloud, error) {
+			return newRoleCloud(roleArn, tokenFile, sessionDuration, region, endpointOpts, metrics.observeAssumeRole)
+		},
+		now:     time.Now,
+		metrics: metrics,
+		entries: make(map[cloudCacheKey]cloudCacheEntry),
+	}
+}
+
+// newRoleCloud assumes roleArn with the web identity token in tokenFile, or with the driver's own credentials
+// when tokenFile is empty. Without roleArn it uses the driver's own credentials. A region overrides the region of
+// endpointOpts. Every call that assumes the role is reported to observe.
+func newRoleCloud(roleArn, tokenFile string, sessionDuration time.Duration, region string, endpointOpts cloud.EndpointOptions, observe cloud.AssumeRoleObserver) (cloud.Cloud, error) {
+	if region != "" {
+		endpointOpts.Region = region
+	}
+	switch {
+	case roleArn == "":
+		return cloud.NewCloud(endpointOpts)
+	case tokenFile != "":
+		return cloud.NewCloudWithRoleWebIdentity(roleArn, tokenFile, sessionDuration, endpointOpts, observe)
+	default:
+		return cloud.NewCloudWithRole(roleArn, sessionDuration, endpointOpts, observe)
+	}
+}
+
+// get returns the cloud for roleArn in region, creating it if it is not cached or has expired. An empty roleArn uses
+// the driver's own credentials and an empty region the driver's region. A ttl of 0 disables caching.
+func (c *cloudCache) get(roleArn, tokenFile string, sessionDuration time.Duration, region string) (cloud.Cloud, error) {
+	key := cloudCacheKey{roleArn: roleArn, tokenFile: tokenFile, sessionDuration: sessionDuration, region: region}
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && c.now().Before(entry.expires) {
+		c.mu.Unlock()
+		klog.V(5).Infof("Reusing cached cloud for role %q in region %q", roleArn, region)
+		c.metrics.observeRoleCloud(roleArn, true)
+		return entry.cloud, nil
+	}
+	c.mu.Unlock()
+	c.metrics.observeRoleCloud(roleArn, false)
+
+	localCloud, err, _ := c.group.Do(fmt.Sprintf("%s|%s|%s|%s", roleArn, tokenFile, sessionDuration, region), func() (interface{}, error) {
+		localCloud, err := c.newCloud(roleArn, tokenFile, sessionDuration, region)
+		if err != nil {
+			return nil, err
+		}
+		if c.ttl > 0 {
+			c.mu.Lock()
+			c.entries[key] = cloudCacheEntry{cloud: localCloud, expires: c.now().Add(c.ttl)}
+			c.mu.Unlock()
+		}
+		return localCloud, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return localCloud.(cloud.Cloud), nil
+}
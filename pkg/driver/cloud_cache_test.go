@@ -0,0 +1,216 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/cloud"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testRoleArn = "arn:aws:iam::1234567890:role/EFSCrossAccountRole"
+
+// newTestCloudCache returns a cache whose clock is advanced by the returned function and
+// a pointer to the number of clouds it has created.
+func newTestCloudCache(ttl time.Duration, newErr error) (*cloudCache, func(time.Duration), *int) {
+	calls := 0
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newCloudCache(ttl, cloud.EndpointOptions{}, nil)
+	c.now = func() time.Time { return now }
+	c.newCloud = func(roleArn, tokenFile string, sessionDuration time.Duration, region string) (cloud.Cloud, error) {
+		calls++
+		if newErr != nil {
+			return nil, newErr
+		}
+		return &cloud.FakeCloudProvider{}, nil
+	}
+	return c, func(d time.Duration) { now = now.Add(d) }, &calls
+}
+
+func TestGetCloudReusesRoleCloudWithinTTL(t *testing.T) {
+	cache, advance, calls := newTestCloudCache(15*time.Minute, nil)
+	driver := &Driver{roleClouds: cache}
+	secrets := map[string]string{RoleArn: testRoleArn}
+
+	first, _, err := getCloud(secrets, driver, "")
+	if err != nil {
+		t.Fatalf("getCloud failed: %v", err)
+	}
+	advance(10 * time.Minute)
+	second, _, err := getCloud(secrets, driver, "")
+	if err != nil {
+		t.Fatalf("getCloud failed: %v", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("Expected 1 cloud to be created, got %d", *calls)
+	}
+	if first != second {
+		t.Fatal("Expected the cached cloud to be returned")
+	}
+
+	advance(10 * time.Minute)
+	if _, _, err = getCloud(secrets, driver, ""); err != nil {
+		t.Fatalf("getCloud failed: %v", err)
+	}
+	if *calls != 2 {
+		t.Fatalf("Expected an expired cloud to be created again, got %d creations", *calls)
+	}
+}
+
+func TestCloudCacheKeys(t *testing.T) {
+	cache, _, calls := newTestCloudCache(15*time.Minute, nil)
+
+	for _, key := range []struct {
+		roleArn         string
+		tokenFile       string
+		sessionDuration time.Duration
+		region          string
+	}{
+		{testRoleArn, "", 0, ""},
+		{testRoleArn, "", time.Hour, ""},
+		{"arn:aws:iam::1234567890:role/OtherRole", "", 0, ""},
+		{testRoleArn, "/var/run/secrets/eks.amazonaws.com/serviceaccount/token", 0, ""},
+		{testRoleArn, "", 0, "eu-west-1"},
+		{"", "", 0, "eu-west-1"},
+		{testRoleArn, "", 0, ""},
+		{"", "", 0, "eu-west-1"},
+	} {
+		if _, err := cache.get(key.roleArn, key.tokenFile, key.sessionDuration, key.region); err != nil {
+			t.Fatalf("get failed: %v", err)
+		}
+	}
+	if *calls != 6 {
+		t.Fatalf("Expected a cloud per role, token file, session duration and region, got %d creations", *calls)
+	}
+}
+
+func TestCloudCacheDisabled(t *testing.T) {
+	cache, _, calls := newTestCloudCache(0, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.get(testRoleArn, "", 0, ""); err != nil {
+			t.Fatalf("get failed: %v", err)
+		}
+	}
+	if *calls != 2 {
+		t.Fatalf("Expected a cloud to be created for every call, got %d creations", *calls)
+	}
+}
+
+func TestCloudCacheDoesNotCacheErrors(t *testing.T) {
+	cache, _, calls := newTestCloudCache(15*time.Minute, errors.New("assume role failed"))
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.get(testRoleArn, "", 0, ""); err == nil {
+			t.Fatal("get did not fail")
+		}
+	}
+	if *calls != 2 {
+		t.Fatalf("Expected a failed creation to be retried, got %d creations", *calls)
+	}
+}
+
+func TestGetCloudAssumesRoleWithWebIdentity(t *testing.T) {
+	const tokenFile = "/var/run/secrets/eks.amazonaws.com/serviceaccount/token"
+	var tokenFiles []string
+	cache := newCloudCache(15*time.Minute, cloud.EndpointOptions{}, nil)
+	cache.newCloud = func(roleArn, tokenFile string, sessionDuration time.Duration, region string) (cloud.Cloud, error) {
+		tokenFiles = append(tokenFiles, tokenFile)
+		return &cloud.FakeCloudProvider{}, nil
+	}
+	driver := &Driver{roleClouds: cache}
+
+	if _, _, err := getCloud(map[string]string{RoleArn: testRoleArn}, driver, ""); err != nil {
+		t.Fatalf("getCloud failed: %v", err)
+	}
+	if _, _, err := getCloud(map[string]string{RoleArn: testRoleArn, WebIdentityTokenFile: tokenFile}, driver, ""); err != nil {
+		t.Fatalf("getCloud failed: %v", err)
+	}
+	if len(tokenFiles) != 2 || tokenFiles[0] != "" || tokenFiles[1] != tokenFile {
+		t.Fatalf("Expected the role to be assumed without and then with the web identity token, got token files %q", tokenFiles)
+	}
+
+	_, _, err := getCloud(map[string]string{RoleArn: testRoleArn, WebIdentityTokenFile: ""}, driver, "")
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument for an empty token file, got: %v", err)
+	}
+}
+
+func TestGetCloudCreatesEachKeyOutsideTheLock(t *testing.T) {
+	const otherRoleArn = "arn:aws:iam::1234567890:role/OtherRole"
+	var mu sync.Mutex
+	created := map[string]int{}
+	release := make(chan struct{})
+	cache := newCloudCache(15*time.Minute, cloud.EndpointOptions{}, nil)
+	cache.newCloud = func(roleArn, tokenFile string, sessionDuration time.Duration, region string) (cloud.Cloud, error) {
+		mu.Lock()
+		created[roleArn]++
+		mu.Unlock()
+		if roleArn == testRoleArn {
+			<-release
+		}
+		return &cloud.FakeCloudProvider{}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.get(testRoleArn, "", 0, ""); err != nil {
+				t.Errorf("get failed: %v", err)
+			}
+		}()
+	}
+
+	// The creation for testRoleArn is blocked, which must not hold up another role.
+	done := make(chan error)
+	go func() {
+		_, err := cache.get(otherRoleArn, "", 0, "")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("get failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the cloud of another role to be created while the first is still being created")
+	}
+
+	close(release)
+	wg.Wait()
+	mu.Lock()
+	creations := created[testRoleArn]
+	mu.Unlock()
+	if creations < 1 || created[otherRoleArn] != 1 {
+		t.Fatalf("Unexpected creations: %v", created)
+	}
+	if _, err := cache.get(testRoleArn, "", 0, ""); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if created[testRoleArn] != creations {
+		t.Fatalf("Expected the cached cloud to be reused, got %d creations after %d", created[testRoleArn], creations)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"k8s.io/klog/v2"
+)
+
+// ConnectivityCheckTimeout bounds a single connectivity check, which describes the mount targets of the canary file
+// system and dials one of them.
+const ConnectivityCheckTimeout = 30 * time.Second
+
+// connectivityCheck keeps the result of the last EFS connectivity check for ttl, so that the CSI Probe reports a
+// network misconfiguration, and its repair, that happen after startup without checking on every Probe.
+type connectivityCheck struct {
+	ttl time.Duration
+	now func() time.Time
+
+	group   singleflight.Group
+	mu      sync.Mutex
+	checked time.Time
+	err     error
+}
+
+func newConnectivityCheck(ttl time.Duration) *connectivityCheck {
+	return &connectivityCheck{
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// check returns the result of the last call to verify while it is younger than ttl, and calls verify again otherwise.
+// Concurrent checks share a single call, which does not end with the context of the check that started it. A nil
+// check always succeeds, and a ttl of 0 calls verify for every check.
+func (c *connectivityCheck) check(ctx context.Context, verify func(ctx context.Context) error) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	if !c.checked.IsZero() && c.now().Sub(c.checked) < c.ttl {
+		err := c.err
+		c.mu.Unlock()
+		return err
+	}
+	c.mu.Unlock()
+
+	_, err, _ := c.group.Do("", func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(detachedContext{ctx}, ConnectivityCheckTimeout)
+		defer cancel()
+		err := verify(ctx)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if err != nil && c.err == nil {
+			klog.Errorf("Failed to verify EFS connectivity, reporting not ready: %v", err)
+		} else if err == nil && c.err != nil {
+			klog.Infof("Verified EFS connectivity, reporting ready again")
+		}
+		c.checked, c.err = c.now(), err
+		return nil, err
+	})
+	return err
+}
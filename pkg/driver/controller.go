@@ -19,43 +19,108 @@ package driver
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"net"
 	"os"
 	"path"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
 
+	"github.com/aws/aws-sdk-go/service/efs"
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/cloud"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 )
 
 const (
 	AccessPointMode       = "efs-ap"
+	AllocatedGidTagKey    = "efs.csi.aws.com/allocated-gid"
 	AzName                = "az"
+	AzNameTagKey          = "efs.csi.aws.com/availability-zone"
+	SubnetIdTagKey        = "efs.csi.aws.com/subnet-id"
 	BasePath              = "basePath"
+	DataClass             = "dataClass"
+	DataClassPersistent   = "persistent"
+	DataClassScratch      = "scratch"
+	DataClassTagKey       = "efs.csi.aws.com/data-class"
+	MaxAccessPointsPerFs  = 1000
 	DefaultGidMin         = 50000
 	DefaultGidMax         = 7000000
 	DefaultTagKey         = "efs.csi.aws.com/cluster"
+	DriverTagKeyPrefix    = "efs.csi.aws.com/"
 	DefaultTagValue       = "true"
+	DefaultVolumeSize     = 5 * 1024 * 1024 * 1024
+	DeleteRootDir         = "deleteRootDir"
 	DirectoryPerms        = "directoryPerms"
+	DryRun                = "dryRun"
+	DryRunVolumePrefix    = "dryrun-"
+	EmitResolvedParams    = "emitResolvedParameters"
+	EncryptInTransit      = "encryptInTransit"
 	EnsureUniqueDirectory = "ensureUniqueDirectory"
+	ExpectedVpcId         = "expectedVpcId"
+	FileSystemArn         = "fileSystemArn"
+	FileSystemName        = "fileSystemName"
+	NameTagKey            = "Name"
 	FsId                  = "fileSystemId"
 	Gid                   = "gid"
 	GidMin                = "gidRangeStart"
 	GidMax                = "gidRangeEnd"
+	InheritFsTags         = "inheritFileSystemTags"
+	MaxApsPerNamespace    = "maxAccessPointsPerNamespace"
+	MountOptions          = "mountOptions"
 	MountTargetIp         = "mounttargetip"
+	NameSanitization      = "nameSanitization"
+	NameSanitizeNone      = "none"
+	NameSanitizeReject    = "reject"
+	NameSanitizeReplace   = "replace"
+	VolCtxRootDir         = "accesspoint/rootdir"
+	VolCtxUid             = "accesspoint/uid"
+	VolCtxGid             = "accesspoint/gid"
+	VolCtxDirectoryPerms  = "accesspoint/directoryperms"
+	VolCtxResolvedParams  = "accesspoint/resolvedparameters"
 	ProvisioningMode      = "provisioningMode"
 	PvName                = "csi.storage.k8s.io/pv/name"
 	PvcName               = "csi.storage.k8s.io/pvc/name"
 	PvcNamespace          = "csi.storage.k8s.io/pvc/namespace"
+	PvcNamespaceTagKey    = "efs.csi.aws.com/pvc-namespace"
+	MaxTagsPerResource    = 50
+	MaxTagKeyLength       = 128
+	MaxTagValueLength     = 256
+	MaxPosixId            = 4294967295
+	MaxSecondaryGids      = 16
+	RequireEncryption     = "requireEncryption"
 	RoleArn               = "awsRoleArn"
+	RootDirNamePattern    = "rootDirNamePattern"
+	SecondaryGids         = "secondaryGids"
+	SessionDuration       = "sessionDuration"
+	SkipFsDescribeDenied  = "skipFsDescribeOnAccessDenied"
+	WebIdentityTokenFile  = "webIdentityTokenFile"
+	StorageClassTagKey    = "efs.csi.aws.com/storage-class"
+	SubnetId              = "subnetId"
 	SubPathPattern        = "subPathPattern"
+	TagAllocatedGid       = "tagAllocatedGid"
 	TempMountPathPrefix   = "/var/lib/csi/pv"
+	UniquePath            = "uniquePath"
+	UniquePathHashLength  = 8
+	NfsMountOptions       = "nfsvers=4.1,rsize=1048576,wsize=1048576,hard,timeo=600,retrans=2,noresvport"
+	NfsPort               = "2049"
+	OwnerUid              = "ownerUid"
+	OwnerGid              = "ownerGid"
+	MountProbeTimeout     = 3 * time.Second
+	ThrottleRetryDelay    = time.Second
 	Uid                   = "uid"
 	ReuseAccessPointKey   = "reuseAccessPoint"
 	PvcNameKey            = "csi.storage.k8s.io/pvc/name"
@@ -65,7 +130,10 @@ var (
 	// controllerCaps represents the capability of controller service
 	controllerCaps = []csi.ControllerServiceCapability_RPC_Type{
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
+		csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
 	}
+	// provisioningModes are the values the provisioningMode parameter can take.
+	provisioningModes = []string{AccessPointMode}
 	// subPathPatternComponents shows the elements that we allow to be in the construction of the root directory
 	// of the access point, as well as the values we need to extract them from the Volume Parameters.
 	subPathPatternComponents = map[string]string{
@@ -73,9 +141,40 @@ var (
 		".PVC.namespace": PvcNamespace,
 		".PV.name":       PvName,
 	}
+	// tagTemplateFields are the fields a tag value template can reference, as well as the values we need to
+	// extract them from the Volume Parameters.
+	tagTemplateFields = map[string]string{
+		"PVCName":      PvcName,
+		"PVCNamespace": PvcNamespace,
+		"PVName":       PvName,
+	}
+	// unsafeNameChars matches the characters nameSanitization rejects or replaces in a volume name.
+	unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+	// tempMountName matches the temporary mount points DeleteVolume creates, named after the access point with an
+	// optional unique suffix.
+	tempMountName = regexp.MustCompile(`^fsap-[0-9A-Za-z]+(-[0-9a-f-]{36})?$`)
+	// roleArnPattern is the shape an IAM role ARN supplied for cross account mount must take.
+	roleArnPattern = regexp.MustCompile(`^arn:aws[-a-z]*:iam::\d+:role/.+$`)
+	// fileSystemArnPattern captures the partition, region, account and file system ID of an EFS file system ARN.
+	fileSystemArnPattern = regexp.MustCompile(`^arn:(aws[-a-z]*):elasticfilesystem:([a-z]{2}(?:-[a-z]+)+-\d+):(\d{12}):file-system/(fs-[0-9a-f]+)$`)
 )
 
 func (d *Driver) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest) (*csi.CreateVolumeResponse, error) {
+	start := time.Now()
+	ctx, endSegment := d.getTracer().BeginSegment(ctx, "CreateVolume")
+	resp, err := d.createVolume(ctx, req)
+	endSegment(err)
+	d.metrics.observe(operationProvision, modeAccessPoint, start, err)
+	if err == nil && !strings.HasPrefix(resp.GetVolume().GetVolumeId(), DryRunVolumePrefix) {
+		// The file system may have been referenced by ARN or name, so it is taken from the volume ID
+		if handle, err := parseVolumeHandle(resp.GetVolume().GetVolumeId()); err == nil {
+			d.metrics.observeProvisioned(handle.fileSystemId)
+		}
+	}
+	return resp, err
+}
+
+func (d *Driver) createVolume(ctx context.Context, req *csi.CreateVolumeRequest) (*csi.CreateVolumeResponse, error) {
 	klog.V(4).Infof("CreateVolume: called with args %+v", *req)
 
 	var reuseAccessPoint bool
@@ -102,7 +201,12 @@ func (d *Driver) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest)
 
 	// Volume size is required to match PV to PVC by k8s.
 	// Volume size is not consumed by EFS for any purposes.
+	// A request without any capacity range gets a default size rather than a zero-size volume.
 	volSize := req.GetCapacityRange().GetRequiredBytes()
+	if req.GetCapacityRange() == nil {
+		volSize = DefaultVolumeSize
+		klog.V(4).Infof("CreateVolume: no capacity requested for volume %v, using default size of %d bytes", volName, volSize)
+	}
 
 	volCaps := req.GetVolumeCapabilities()
 	if len(volCaps) == 0 {
@@ -119,73 +223,115 @@ func (d *Driver) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest)
 	var (
 		azName           string
 		basePath         string
+		dryRun           bool
+		emitResolved     bool
 		gid              int64
 		gidMin           int
 		gidMax           int
+		gidRangeSet      bool
+		inheritedTagKeys []string
 		localCloud       cloud.Cloud
+		maxApsPerNs      int
 		provisioningMode string
+		requireEncrypted bool
 		roleArn          string
+		rootDirPattern   *regexp.Regexp
+		skipDescribe     bool
+		subnetId         string
+		tagGid           bool
 		uid              int64
+		uniquePath       bool
 	)
 
 	//Parse parameters
 	if value, ok := volumeParams[ProvisioningMode]; ok {
 		provisioningMode = value
-		//TODO: Add FS provisioning mode check when implemented
-		if provisioningMode != AccessPointMode {
-			errStr := "Provisioning mode " + provisioningMode + " is not supported. Only Access point provisioning: 'efs-ap' is supported"
-			return nil, status.Error(codes.InvalidArgument, errStr)
-		}
+	} else if d.defaultProvisioningMode != "" {
+		klog.V(4).Infof("CreateVolume: %v not set, using the default %v", ProvisioningMode, d.defaultProvisioningMode)
+		provisioningMode = d.defaultProvisioningMode
 	} else {
 		return nil, status.Errorf(codes.InvalidArgument, "Missing %v parameter", ProvisioningMode)
 	}
+	//TODO: Add FS provisioning mode check when implemented
+	if err := checkProvisioningMode(provisioningMode); err != nil {
+		return nil, err
+	}
 
-	// Create tags
-	tags := map[string]string{
+	// Create default tags
+	defaultTags := map[string]string{
 		DefaultTagKey: DefaultTagValue,
 	}
 
-	// Append input tags to default tag
-	if len(d.tags) != 0 {
-		for k, v := range d.tags {
-			tags[k] = v
+	// Record the owning namespace so per-namespace access point quotas can be enforced
+	pvcNamespace := volumeParams[PvcNamespace]
+	if pvcNamespace != "" {
+		defaultTags[PvcNamespaceTagKey] = pvcNamespace
+	}
+
+	// Record how long the data is meant to live so external tooling can clean up scratch volumes
+	if value, ok := volumeParams[DataClass]; ok {
+		if value != DataClassScratch && value != DataClassPersistent {
+			return nil, status.Errorf(codes.InvalidArgument, "%v must be one of %v or %v, got %q", DataClass, DataClassScratch, DataClassPersistent, value)
+		}
+		defaultTags[DataClassTagKey] = value
+	}
+
+	// Record which storage class provisioned the access point for auditing
+	if d.tagStorageClass {
+		if storageClass := d.pvcStorageClassName(ctx, volumeParams); storageClass != "" {
+			defaultTags[StorageClassTagKey] = storageClass
 		}
 	}
 
 	accessPointsOptions := &cloud.AccessPointOptions{
 		CapacityGiB: volSize,
-		Tags:        tags,
 	}
 
+	var fsArn *fileSystemArn
+	var fsName string
 	if value, ok := volumeParams[FsId]; ok {
 		if strings.TrimSpace(value) == "" {
 			return nil, status.Errorf(codes.InvalidArgument, "Parameter %v cannot be empty", FsId)
 		}
+		if _, ok := volumeParams[FileSystemArn]; ok {
+			return nil, status.Errorf(codes.InvalidArgument, "Only one of %v and %v can be set", FsId, FileSystemArn)
+		}
 		accessPointsOptions.FileSystemId = value
+	} else if value, ok := volumeParams[FileSystemArn]; ok {
+		fsArn, err = parseFileSystemArn(value)
+		if err != nil {
+			return nil, err
+		}
+		accessPointsOptions.FileSystemId = fsArn.fileSystemId
+	} else if value, ok := volumeParams[FileSystemName]; ok {
+		if strings.TrimSpace(value) == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "Parameter %v cannot be empty", FileSystemName)
+		}
+		// Resolved to an ID once the clients for the storage class are known
+		fsName = value
 	} else {
 		return nil, status.Errorf(codes.InvalidArgument, "Missing %v parameter", FsId)
 	}
 
-	uid = -1
-	if value, ok := volumeParams[Uid]; ok {
-		uid, err = strconv.ParseInt(value, 10, 64)
-		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "Failed to parse invalid %v: %v", Uid, err)
-		}
-		if uid < 0 {
-			return nil, status.Errorf(codes.InvalidArgument, "%v must be greater or equal than 0", Uid)
-		}
+	// Without a uid or gid the gid is allocated, and the uid is the allocated gid
+	uid, err = parseUidGid(Uid, req.GetSecrets(), volumeParams, d.defaultIds, -1)
+	if err != nil {
+		return nil, err
 	}
 
-	gid = -1
-	if value, ok := volumeParams[Gid]; ok {
-		gid, err = strconv.ParseInt(value, 10, 64)
-		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "Failed to parse invalid %v: %v", Gid, err)
-		}
-		if uid < 0 {
-			return nil, status.Errorf(codes.InvalidArgument, "%v must be greater or equal than 0", Gid)
-		}
+	gid, err = parseUidGid(Gid, req.GetSecrets(), volumeParams, d.defaultIds, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerUid, err := parseUidGid(OwnerUid, req.GetSecrets(), volumeParams, d.defaultIds, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerGid, err := parseUidGid(OwnerGid, req.GetSecrets(), volumeParams, d.defaultIds, -1)
+	if err != nil {
+		return nil, err
 	}
 
 	if value, ok := volumeParams[GidMin]; ok {
@@ -196,6 +342,7 @@ func (d *Driver) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest)
 		if gidMin <= 0 {
 			return nil, status.Errorf(codes.InvalidArgument, "%v must be greater than 0", GidMin)
 		}
+		gidRangeSet = true
 	}
 
 	if value, ok := volumeParams[GidMax]; ok {
@@ -224,53 +371,155 @@ func (d *Driver) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest)
 	}
 
 	if value, ok := volumeParams[DirectoryPerms]; ok {
-		accessPointsOptions.DirectoryPerms = value
+		accessPointsOptions.DirectoryPerms, err = parseDirectoryPerms(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(d.allowedDirectoryPerms) > 0 && !d.allowedDirectoryPerms[accessPointsOptions.DirectoryPerms] {
+			allowed := make([]string, 0, len(d.allowedDirectoryPerms))
+			for mode := range d.allowedDirectoryPerms {
+				allowed = append(allowed, mode)
+			}
+			sort.Strings(allowed)
+			return nil, status.Errorf(codes.InvalidArgument, "%v %q is not one of the modes allowed by the driver: %v", DirectoryPerms, value, allowed)
+		}
+	}
+
+	if value, ok := volumeParams[SecondaryGids]; ok {
+		accessPointsOptions.SecondaryGids, err = parseSecondaryGids(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if value, ok := volumeParams[EmitResolvedParams]; ok {
+		emitResolved, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Failed to parse invalid %v: %v", EmitResolvedParams, err)
+		}
+	}
+
+	if value, ok := volumeParams[DryRun]; ok {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Failed to parse invalid %v: %v", DryRun, err)
+		}
+	}
+
+	if value, ok := volumeParams[RequireEncryption]; ok {
+		requireEncrypted, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Failed to parse invalid %v: %v", RequireEncryption, err)
+		}
+	}
+
+	if value, ok := volumeParams[SkipFsDescribeDenied]; ok {
+		skipDescribe, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Failed to parse invalid %v: %v", SkipFsDescribeDenied, err)
+		}
+	}
+
+	if value, ok := volumeParams[UniquePath]; ok {
+		uniquePath, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Failed to parse invalid %v: %v", UniquePath, err)
+		}
+	}
+
+	if value, ok := volumeParams[TagAllocatedGid]; ok {
+		tagGid, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Failed to parse invalid %v: %v", TagAllocatedGid, err)
+		}
+	}
+
+	if value, ok := volumeParams[MaxApsPerNamespace]; ok {
+		maxApsPerNs, err = strconv.Atoi(value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Failed to parse invalid %v: %v", MaxApsPerNamespace, err)
+		}
+		if maxApsPerNs <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "%v must be greater than 0", MaxApsPerNamespace)
+		}
+		if pvcNamespace == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "%v requires the PVC namespace. Please enable --extra-create-metadata on the csi-provisioner", MaxApsPerNamespace)
+		}
+	}
+
+	if value, ok := volumeParams[InheritFsTags]; ok {
+		for _, key := range strings.Split(value, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				inheritedTagKeys = append(inheritedTagKeys, key)
+			}
+		}
+		if len(inheritedTagKeys) == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "Parameter %v must list at least one tag key", InheritFsTags)
+		}
+	}
+
+	if value, ok := volumeParams[RootDirNamePattern]; ok {
+		// The pattern has to match the whole directory name, not just part of it
+		rootDirPattern, err = regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Failed to parse invalid %v: %v", RootDirNamePattern, err)
+		}
 	}
 
 	// Storage class parameter `az` will be used to fetch preferred mount target for cross account mount.
-	// If the `az` storage class parameter is not provided, a random mount target will be picked for mounting.
+	// If the `az` storage class parameter is not provided, the first available mount target ordered by AZ name, subnet ID and IP address will be picked for mounting.
 	// This storage class parameter different from `az` mount option provided by efs-utils https://github.com/aws/efs-utils/blob/v1.31.1/src/mount_efs/__init__.py#L195
 	// The `az` mount option provided by efs-utils is used for cross az mount or to provide az of efs one zone file system mount within the same aws-account.
 	// To make use of the `az` mount option, add it under storage class's `mountOptions` section. https://kubernetes.io/docs/concepts/storage/storage-classes/#mount-options
 	if value, ok := volumeParams[AzName]; ok {
 		azName = value
+		// DeleteVolume only has the volume ID, so it reads the zone back from the access point to mount in it
+		defaultTags[AzNameTagKey] = azName
 	}
 
-	localCloud, roleArn, err = getCloud(req.GetSecrets(), d)
-	if err != nil {
-		return nil, err
+	// Pins the mount target used for cross account mount to a subnet, for VPCs where not every subnet is reachable.
+	if value, ok := volumeParams[SubnetId]; ok {
+		if !strings.HasPrefix(value, "subnet-") {
+			return nil, status.Errorf(codes.InvalidArgument, "%v %q must be a subnet ID of the form subnet-...", SubnetId, value)
+		}
+		subnetId = value
+		// DeleteVolume mounts through the same mount target, so the subnet is recorded like the zone
+		defaultTags[SubnetIdTagKey] = subnetId
 	}
 
-	// Check if file system exists. Describe FS handles appropriate error codes
-	if _, err = localCloud.DescribeFileSystem(ctx, accessPointsOptions.FileSystemId); err != nil {
-		if err == cloud.ErrAccessDenied {
-			return nil, status.Errorf(codes.Unauthenticated, "Access Denied. Please ensure you have the right AWS permissions: %v", err)
-		}
-		if err == cloud.ErrNotFound {
-			return nil, status.Errorf(codes.InvalidArgument, "File System does not exist: %v", err)
-		}
-		return nil, status.Errorf(codes.Internal, "Failed to fetch File System info: %v", err)
+	// The options DeleteVolume mounts the file system with to delete the access point root directory
+	if _, err := internalMountOptions(nil, volumeParams, nil); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid mount options: %v", err)
+	}
+	for k, v := range mountParameterTags(volumeParams) {
+		defaultTags[k] = v
 	}
 
-	var allocatedGid int64
-	if uid == -1 || gid == -1 {
-		allocatedGid, err = d.gidAllocator.getNextGid(ctx, accessPointsOptions.FileSystemId, gidMin, gidMax)
-		if err != nil {
-			return nil, err
+	// Tags whose value is a template are expanded per PVC, and rank below the tags given literally
+	userTags, templateTags := map[string]string{}, map[string]string{}
+	for k, v := range d.tags {
+		if strings.Contains(v, "{{") {
+			templateTags[k] = v
+		} else {
+			userTags[k] = v
 		}
 	}
-	if uid == -1 {
-		uid = allocatedGid
-	}
-	if gid == -1 {
-		gid = allocatedGid
+	templateTags, err = expandTagTemplates(templateTags, volumeParams)
+	if err != nil {
+		return nil, err
 	}
 
 	if value, ok := volumeParams[BasePath]; ok {
+		for _, component := range strings.Split(value, "/") {
+			if component == ".." {
+				return nil, status.Errorf(codes.InvalidArgument, "%v %q must not contain '..'", BasePath, value)
+			}
+		}
 		basePath = value
 	}
 
 	rootDirName := volName
+	uniqueRootDir := false
 	// Check if a custom structure should be imposed on the access point directory
 	if value, ok := volumeParams[SubPathPattern]; ok {
 		// Try and construct the root directory and check it only contains supported components
@@ -284,93 +533,404 @@ func (d *Driver) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest)
 				} else {
 					klog.Infof("Appending PVC UID to path.")
 					rootDirName = fmt.Sprintf("%s-%s", val, uuid.New().String())
+					uniqueRootDir = true
 				}
 			} else {
 				klog.Infof("Appending PVC UID to path.")
 				rootDirName = fmt.Sprintf("%s-%s", val, uuid.New().String())
+				uniqueRootDir = true
 			}
 		} else {
 			return nil, err
 		}
 	} else {
 		klog.Infof("Using PV name for access point directory.")
+		rootDirName, err = sanitizeVolumeName(volName, volumeParams[NameSanitization])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if uniquePath {
+		// Same-named PVCs from different namespaces would otherwise share a directory under a common basePath
+		rootDirName = fmt.Sprintf("%s-%s", rootDirName, getPathHash(volName, volumeParams))
 	}
 
 	rootDir := path.Join("/", basePath, rootDirName)
+	if !isWithinDir(path.Join("/", basePath), rootDir) {
+		return nil, status.Errorf(codes.InvalidArgument, "Access point directory %q resolves to %v, which is outside of %v %q", rootDirName, rootDir, BasePath, basePath)
+	}
 	if ok, err := validateEfsPathRequirements(rootDir); !ok {
 		return nil, err
 	}
+	if rootDirPattern != nil && !rootDirPattern.MatchString(path.Base(rootDir)) {
+		return nil, status.Errorf(codes.InvalidArgument, "Access point directory name %q does not match %v %q", path.Base(rootDir), RootDirNamePattern, volumeParams[RootDirNamePattern])
+	}
 	klog.Infof("Using %v as the access point directory.", rootDir)
 
+	if dryRun {
+		klog.Infof("CreateVolume: %v is set, skipping creating an Access Point for volume %v", DryRun, volName)
+		return &csi.CreateVolumeResponse{
+			Volume: &csi.Volume{
+				CapacityBytes: volSize,
+				VolumeId:      DryRunVolumePrefix + volName,
+				VolumeContext: map[string]string{VolCtxRootDir: rootDir},
+			},
+		}, nil
+	}
+
+	// A file system referenced by an ARN in another region is reached through clients for that region
+	var region string
+	if fsArn != nil && fsArn.region != d.clientRegion() {
+		region = fsArn.region
+	}
+	localCloud, roleArn, err = getCloud(req.GetSecrets(), d, region)
+	if err != nil {
+		return nil, err
+	}
+
+	if fsArn != nil {
+		if err = checkFileSystemArn(fsArn, roleArn); err != nil {
+			return nil, err
+		}
+	}
+	if fsName != "" {
+		accessPointsOptions.FileSystemId, err = findFileSystemByName(ctx, localCloud, fsName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Check if file system exists. Describe FS handles appropriate error codes
+	var fileSystem *cloud.FileSystem
+	err = d.getTracer().Capture(ctx, "DescribeFileSystem", func(ctx context.Context) (err error) {
+		fileSystem, err = localCloud.DescribeFileSystem(ctx, accessPointsOptions.FileSystemId)
+		return err
+	})
+	if err == cloud.ErrAccessDenied && skipDescribe && !requireEncrypted {
+		// Least privilege roles may be allowed to create access points without describing the file system,
+		// CreateAccessPoint still fails if the file system does not exist
+		klog.Warningf("CreateVolume: not allowed to describe File System %v, creating the Access Point without checking it: %v", accessPointsOptions.FileSystemId, err)
+		fileSystem = &cloud.FileSystem{FileSystemId: accessPointsOptions.FileSystemId}
+	} else if err != nil {
+		if err == cloud.ErrAccessDenied {
+			return nil, status.Errorf(codes.Unauthenticated, "Access Denied. Please ensure you have the right AWS permissions: %v", err)
+		}
+		if err == cloud.ErrNotFound {
+			// EFS only looks up file systems in the client's region, so a file system in another region is reported as missing
+			clientRegion := region
+			if clientRegion == "" {
+				clientRegion = d.clientRegion()
+			}
+			if clientRegion != "" {
+				return nil, status.Errorf(codes.InvalidArgument, "File System %v does not exist in region %v, check that it was created in the region the driver's clients use: %v", accessPointsOptions.FileSystemId, clientRegion, err)
+			}
+			return nil, status.Errorf(codes.InvalidArgument, "File System does not exist: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to fetch File System info: %v", err)
+	}
+	if err = checkFileSystemLifeCycleState(fileSystem); err != nil {
+		return nil, err
+	}
+	if requireEncrypted && !fileSystem.Encrypted {
+		return nil, status.Errorf(codes.FailedPrecondition, "File System %v is not encrypted at rest, which %v requires", accessPointsOptions.FileSystemId, RequireEncryption)
+	}
+
+	inheritedTags := map[string]string{}
+	for _, key := range inheritedTagKeys {
+		// The driver's own tags mark the access points it owns, so they are never copied from the file system
+		if strings.HasPrefix(key, DriverTagKeyPrefix) {
+			continue
+		}
+		if value, ok := fileSystem.Tags[key]; ok {
+			inheritedTags[key] = value
+		}
+	}
+	if value, ok := volumeParams[ExpectedVpcId]; ok {
+		if err = checkFileSystemVpc(ctx, localCloud, accessPointsOptions.FileSystemId, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if maxApsPerNs > 0 {
+		if err = checkNamespaceAccessPointQuota(ctx, localCloud, accessPointsOptions.FileSystemId, pvcNamespace, maxApsPerNs); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.accessPointLimit > 0 {
+		if err = checkAccessPointLimit(ctx, localCloud, accessPointsOptions.FileSystemId, d.accessPointLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	// Remember what was explicitly requested, a retry may allocate a different gid than the attempt it repeats
+	requestedUid, requestedGid := uid, gid
+	// A configured GID range always allocates the gid, overriding a fixed one
+	if gidRangeSet {
+		requestedGid = -1
+	}
+	var allocatedGid int64
+	if uid == -1 || gid == -1 || gidRangeSet {
+		allocatedGid, err = d.gidAllocator.getNextGid(ctx, localCloud, accessPointsOptions.FileSystemId, gidMin, gidMax)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if uid == -1 {
+		uid = allocatedGid
+	}
+	if gid == -1 || gidRangeSet {
+		gid = allocatedGid
+		// Recording the allocated gid lets the allocator recover it without relying on the PosixUser
+		if tagGid {
+			defaultTags[AllocatedGidTagKey] = strconv.FormatInt(allocatedGid, 10)
+		}
+	}
+
+	var droppedTags []string
+	accessPointsOptions.Tags, droppedTags, err = getTags(userTags, templateTags, inheritedTags, defaultTags)
+	if err != nil {
+		return nil, err
+	}
+	if len(droppedTags) > 0 {
+		d.recordPvcEvent(ctx, volumeParams, corev1.EventTypeWarning, "TagsDropped", "Access points can have at most %d tags, dropped tags %v", MaxTagsPerResource, droppedTags)
+	}
+
 	accessPointsOptions.Uid = uid
 	accessPointsOptions.Gid = gid
 	accessPointsOptions.DirectoryPath = rootDir
+	// The root directory is owned by the POSIX user unless another owner is requested
+	accessPointsOptions.OwnerUid, accessPointsOptions.OwnerGid = uid, gid
+	if ownerUid != -1 {
+		accessPointsOptions.OwnerUid = ownerUid
+	}
+	if ownerGid != -1 {
+		accessPointsOptions.OwnerGid = ownerGid
+	}
 
-	accessPointId, err := localCloud.CreateAccessPoint(ctx, clientToken, accessPointsOptions, reuseAccessPoint)
-	if err != nil {
+	var accessPointId *cloud.AccessPoint
+	err = d.retryThrottled(ctx, "CreateAccessPoint", func() error {
+		return d.getTracer().Capture(ctx, "CreateAccessPoint", func(ctx context.Context) (err error) {
+			accessPointId, err = localCloud.CreateAccessPoint(ctx, clientToken, accessPointsOptions, reuseAccessPoint)
+			return err
+		})
+	})
+	if err == cloud.ErrAlreadyExists {
+		// The external-provisioner retries CreateVolume with the same name, reuse what an earlier attempt created
+		requestedRootDir := rootDir
+		if uniqueRootDir {
+			requestedRootDir = ""
+		}
+		accessPointId, err = findExistingAccessPoint(ctx, localCloud, clientToken, accessPointsOptions.FileSystemId, requestedUid, requestedGid, requestedRootDir)
+		if err != nil {
+			return nil, err
+		}
+		klog.V(2).Infof("CreateVolume: reusing Access Point %v created by an earlier attempt for volume %v", accessPointId.AccessPointId, volName)
+		if accessPointId.PosixUser != nil {
+			accessPointsOptions.Uid = accessPointId.PosixUser.Uid
+			accessPointsOptions.Gid = accessPointId.PosixUser.Gid
+		}
+		accessPointsOptions.DirectoryPath = accessPointId.AccessPointRootDir
+	} else if err != nil {
 		if err == cloud.ErrAccessDenied {
 			return nil, status.Errorf(codes.Unauthenticated, "Access Denied. Please ensure you have the right AWS permissions: %v", err)
 		}
-		if err == cloud.ErrAlreadyExists {
-			return nil, status.Errorf(codes.AlreadyExists, "Access Point already exists")
+		if err == cloud.ErrIncorrectLifeCycleState {
+			return nil, status.Errorf(codes.Unavailable, "File System %v is being modified and cannot create Access Points right now, please retry: %v", accessPointsOptions.FileSystemId, err)
+		}
+		if err == cloud.ErrThrottled {
+			return nil, status.Errorf(codes.Unavailable, "Creating Access Points in File System %v is being throttled, please retry: %v", accessPointsOptions.FileSystemId, err)
+		}
+		if err == cloud.ErrTagPolicyViolation {
+			tagKeys := make([]string, 0, len(accessPointsOptions.Tags))
+			for k := range accessPointsOptions.Tags {
+				tagKeys = append(tagKeys, k)
+			}
+			sort.Strings(tagKeys)
+			return nil, status.Errorf(codes.InvalidArgument, "Access Point in File System %v was rejected because its tags do not comply with the account's tag policy, tag keys sent: %v", accessPointsOptions.FileSystemId, tagKeys)
 		}
 		return nil, status.Errorf(codes.Internal, "Failed to create Access point in File System %v : %v", accessPointsOptions.FileSystemId, err)
 	}
 
-	volContext := map[string]string{}
+	// Report what the access point was created with, these are informational and ignored by NodePublishVolume
+	volContext := map[string]string{
+		VolCtxRootDir: accessPointsOptions.DirectoryPath,
+		VolCtxUid:     strconv.FormatInt(accessPointsOptions.Uid, 10),
+		VolCtxGid:     strconv.FormatInt(accessPointsOptions.Gid, 10),
+	}
+	if accessPointsOptions.DirectoryPerms != "" {
+		volContext[VolCtxDirectoryPerms] = accessPointsOptions.DirectoryPerms
+	}
+	if emitResolved {
+		resolved, err := json.Marshal(newResolvedParameters(provisioningMode, basePath, accessPointsOptions, req.GetSecrets()))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "Failed to encode resolved parameters: %v", err)
+		}
+		volContext[VolCtxResolvedParams] = string(resolved)
+	}
 
 	// Fetch mount target Ip for cross-account mount
 	if roleArn != "" {
-		mountTarget, err := localCloud.DescribeMountTargets(ctx, accessPointsOptions.FileSystemId, azName)
+		mountTarget, err := d.describeMountTarget(ctx, localCloud, roleArn, region, accessPointsOptions.FileSystemId, azName, subnetId)
+		if code := status.Code(err); code == codes.FailedPrecondition || code == codes.InvalidArgument {
+			return nil, err
+		}
+		if err == nil && subnetId != "" && azName != "" && mountTarget.AZName != azName {
+			return nil, status.Errorf(codes.InvalidArgument, "%v %v is in %v, which conflicts with %v %v", SubnetId, subnetId, mountTarget.AZName, AzName, azName)
+		}
 		if err != nil {
 			klog.Warningf("Failed to describe mount targets for file system %v. Skip using `mounttargetip` mount option: %v", accessPointsOptions.FileSystemId, err)
 		} else {
 			volContext[MountTargetIp] = mountTarget.IPAddress
+			if azName != "" && mountTarget.AZName != azName {
+				d.recordPvcEvent(ctx, volumeParams, corev1.EventTypeWarning, "MountTargetFallback", "No available mount target in %v, using mount target %v in %v", azName, mountTarget.MountTargetId, mountTarget.AZName)
+			} else {
+				d.recordPvcEvent(ctx, volumeParams, corev1.EventTypeNormal, "MountTargetSelected", "Using mount target %v in %v", mountTarget.MountTargetId, mountTarget.AZName)
+			}
 		}
 	}
 
+	handle := newAccessPointVolumeHandle(accessPointsOptions.FileSystemId, accessPointId.AccessPointId)
+	handle.region = region
 	return &csi.CreateVolumeResponse{
 		Volume: &csi.Volume{
 			CapacityBytes: volSize,
-			VolumeId:      accessPointsOptions.FileSystemId + "::" + accessPointId.AccessPointId,
+			VolumeId:      handle.encode(d.volumeIdFormat),
 			VolumeContext: volContext,
 		},
 	}, nil
 }
 
 func (d *Driver) DeleteVolume(ctx context.Context, req *csi.DeleteVolumeRequest) (*csi.DeleteVolumeResponse, error) {
+	start := time.Now()
+	ctx, endSegment := d.getTracer().BeginSegment(ctx, "DeleteVolume")
+	resp, err := d.deleteVolumeOnce(ctx, req)
+	endSegment(err)
+	d.metrics.observe(operationDelete, modeAccessPoint, start, err)
+	return resp, err
+}
+
+// deleteVolumeOnce coalesces concurrent deletes of the same volume, such as an external-provisioner retry that
+// overlaps the attempt it repeats. They share one execution and its result instead of mounting the file system and
+// removing the same access point root directory twice. The shared execution runs detached from the context of the
+// call that started it, bounded by --delete-volume-timeout, so that call giving up does not fail the others. Each
+// caller stops waiting once its own context is done.
+func (d *Driver) deleteVolumeOnce(ctx context.Context, req *csi.DeleteVolumeRequest) (*csi.DeleteVolumeResponse, error) {
+	deleteCtx := context.Context(detachedContext{ctx})
+	results := d.deleteCalls.DoChan(req.GetVolumeId(), func() (interface{}, error) {
+		ctx := deleteCtx
+		if d.deleteTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d.deleteTimeout)
+			defer cancel()
+		}
+		return d.deleteVolume(ctx, req)
+	})
+
+	select {
+	case result := <-results:
+		if result.Shared {
+			klog.V(4).Infof("DeleteVolume: concurrent deletes of volume %v shared one execution", req.GetVolumeId())
+		}
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*csi.DeleteVolumeResponse), nil
+	case <-ctx.Done():
+		return nil, status.Errorf(codes.DeadlineExceeded, "DeleteVolume for %v is still in progress: %v", req.GetVolumeId(), ctx.Err())
+	}
+}
+
+// detachedContext carries the values of its parent, such as the trace segment, but is never cancelled and has no
+// deadline, so work started for one request can outlive it.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+func (d *Driver) deleteVolume(ctx context.Context, req *csi.DeleteVolumeRequest) (*csi.DeleteVolumeResponse, error) {
 	var (
 		localCloud cloud.Cloud
 		roleArn    string
 		err        error
 	)
 
-	localCloud, roleArn, err = getCloud(req.GetSecrets(), d)
-	if err != nil {
-		return nil, err
-	}
-
 	klog.V(4).Infof("DeleteVolume: called with args %+v", *req)
 	volId := req.GetVolumeId()
 	if volId == "" {
 		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
 	}
 
-	fileSystemId, _, accessPointId, err := parseVolumeId(volId)
+	if wait := d.deleteLimiter.allow(volId); wait > 0 {
+		return nil, status.Errorf(codes.Aborted, "DeleteVolume for %v was attempted less than %v ago, retry in %v", volId, d.deleteLimiter.interval, wait.Round(time.Second))
+	}
+
+	// Volumes provisioned with dryRun have nothing in EFS to delete
+	if strings.HasPrefix(volId, DryRunVolumePrefix) {
+		klog.Infof("DeleteVolume: %v was provisioned with %v, nothing to delete", volId, DryRun)
+		return &csi.DeleteVolumeResponse{}, nil
+	}
+
+	// The storage class secrets can override --delete-access-point-root-dir for the volumes it provisions
+	deleteRootDir := d.deleteAccessPointRootDir
+	if value, ok := req.GetSecrets()[DeleteRootDir]; ok {
+		deleteRootDir, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Secret %v has invalid value %q: %v", DeleteRootDir, value, err)
+		}
+	}
+
+	handle, err := parseVolumeHandle(volId)
+	if status.Code(err) == codes.Unimplemented {
+		return nil, err
+	}
+	// A volume ID that names an access point but no valid file system, such as "::fsap-..." or
+	// "v1:accesspoint::fsap-...", cannot be cleaned up. Unlike an unknown volume ID, reporting success would
+	// silently leak its access point.
+	if apId := accessPointIdOfVolumeId(volId); err != nil && apId != "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Volume ID %v names access point %v but cannot be parsed: %v", volId, apId, err)
+	}
 	if err != nil {
 		//Returning success for an invalid volume ID. See here - https://github.com/kubernetes-csi/csi-test/blame/5deb83d58fea909b2895731d43e32400380aae3c/pkg/sanity/controller.go#L733
 		klog.V(5).Infof("DeleteVolume: Failed to parse volumeID: %v, err: %v, returning success", volId, err)
 		return &csi.DeleteVolumeResponse{}, nil
 	}
+	fileSystemId, accessPointId := handle.fileSystemId, handle.accessPointId
+
+	localCloud, roleArn, err = getCloud(req.GetSecrets(), d, handle.region)
+	if err != nil {
+		return nil, err
+	}
 
 	//TODO: Add Delete File System when FS provisioning is implemented
 	if accessPointId != "" {
 
-		// Delete access point root directory if delete-access-point-root-dir is set.
-		if d.deleteAccessPointRootDir {
+		// Delete access point root directory if delete-access-point-root-dir is set,
+		// or empty it if the access point is retained for reuse.
+		if deleteRootDir || d.retainAccessPoint {
 			// Check if Access point exists.
 			// If access point exists, retrieve its root directory and delete it/
-			accessPoint, err := localCloud.DescribeAccessPoint(ctx, accessPointId)
+			var accessPoint *cloud.AccessPoint
+			err = d.getTracer().Capture(ctx, "DescribeAccessPoint", func(ctx context.Context) (err error) {
+				accessPoint, err = localCloud.DescribeAccessPoint(ctx, accessPointId)
+				return err
+			})
 			if err != nil {
 				if err == cloud.ErrAccessDenied {
 					return nil, status.Errorf(codes.Unauthenticated, "Access Denied. Please ensure you have the right AWS permissions: %v", err)
@@ -381,43 +941,97 @@ func (d *Driver) DeleteVolume(ctx context.Context, req *csi.DeleteVolumeRequest)
 				}
 				return nil, status.Errorf(codes.Internal, "Could not get describe Access Point: %v , error: %v", accessPointId, err)
 			}
+			// An access point rooted at the file system root would have the whole file system deleted with it
+			if path.Clean("/"+accessPoint.AccessPointRootDir) == "/" {
+				klog.Errorf("DeleteVolume: refusing to delete root directory %q of Access Point %v for volume %v, it is the file system root", accessPoint.AccessPointRootDir, accessPointId, volId)
+				return nil, status.Errorf(codes.InvalidArgument, "Access Point %v of volume %v has the file system root as its root directory, refusing to delete it", accessPointId, volId)
+			}
 
 			//Mount File System at it root and delete access point root directory
-			mountOptions := []string{"tls", "iam"}
-			if roleArn != "" {
-				mountTarget, err := localCloud.DescribeMountTargets(ctx, fileSystemId, "")
-
+			source, fsType := fileSystemId, "efs"
+			azName := accessPoint.Tags[AzNameTagKey]
+			subnetId := accessPoint.Tags[SubnetIdTagKey]
+			usesMountTarget := false
+			var mountOptions []string
+			mountOptions, err = internalMountOptions(d.fsMountOptions[fileSystemId], mountParametersFromTags(accessPoint.Tags), req.GetSecrets())
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "Invalid mount options for %q: %v", fileSystemId, err)
+			}
+			if handle.region != "" {
+				mountOptions = append(mountOptions, "region="+handle.region)
+			}
+			if d.plainNfsInternalMounts {
+				// Without efs-utils the file system can only be reached through a mount target's IP address
+				mountTarget, err := d.describeMountTarget(ctx, localCloud, roleArn, handle.region, fileSystemId, azName, subnetId)
+				if status.Code(err) == codes.FailedPrecondition {
+					return nil, err
+				}
+				if err != nil {
+					return nil, status.Errorf(codes.Internal, "Could not find a mount target to mount %q over NFS: %v", fileSystemId, err)
+				}
+				source, fsType, mountOptions = mountTarget.IPAddress+":/", "nfs4", strings.Split(NfsMountOptions, ",")
+				usesMountTarget = true
+			} else if roleArn != "" {
+				mountTarget, err := d.describeMountTarget(ctx, localCloud, roleArn, handle.region, fileSystemId, azName, subnetId)
+				if status.Code(err) == codes.FailedPrecondition {
+					return nil, err
+				}
 				if err == nil {
 					mountOptions = append(mountOptions, MountTargetIp+"="+mountTarget.IPAddress)
+					usesMountTarget = true
 				} else {
 					klog.Warningf("Failed to describe mount targets for file system %v. Skip using `mounttargetip` mount option: %v", fileSystemId, err)
 				}
 			}
 
-			target := TempMountPathPrefix + "/" + accessPointId
+			// Concurrent deletes of the same access point must not share a mount point
+			target := path.Join(d.tempMountDir(), accessPointId+"-"+uuid.New().String())
 			if err := d.mounter.MakeDir(target); err != nil {
 				return nil, status.Errorf(codes.Internal, "Could not create dir %q: %v", target, err)
 			}
-			if err := d.mounter.Mount(fileSystemId, target, "efs", mountOptions); err != nil {
-				os.Remove(target)
-				return nil, status.Errorf(codes.Internal, "Could not mount %q at %q: %v", fileSystemId, target, err)
-			}
-			err = os.RemoveAll(target + accessPoint.AccessPointRootDir)
-			if err != nil {
-				return nil, status.Errorf(codes.Internal, "Could not delete access point root directory %q: %v", accessPoint.AccessPointRootDir, err)
+			err = d.getTracer().Capture(ctx, "Mount", func(ctx context.Context) error {
+				return runWithContext(ctx, func() error {
+					return d.mounter.Mount(source, target, fsType, mountOptions)
+				}, func(err error) {
+					if err == nil {
+						d.cleanupTempMount(target)
+					} else {
+						os.Remove(target)
+					}
+				})
+			})
+			if err != nil && usesMountTarget {
+				// The mount target may be gone or unreachable, so it is described again on the next attempt
+				d.mountTargets.invalidate(roleArn, handle.region, fileSystemId, azName, subnetId)
 			}
-			err = d.mounter.Unmount(target)
-			if err != nil {
-				return nil, status.Errorf(codes.Internal, "Could not unmount %q: %v", target, err)
+			if isContextError(err) {
+				return nil, status.Errorf(codes.DeadlineExceeded, "Could not mount %q at %q: %v", fileSystemId, target, err)
 			}
-			err = os.RemoveAll(target)
 			if err != nil {
-				return nil, status.Errorf(codes.Internal, "Could not delete %q: %v", target, err)
+				os.Remove(target)
+				if !d.rootDirCleanupBestEffort || d.retainAccessPoint {
+					if IsUnknownFsTypeError(err) {
+						return nil, status.Errorf(codes.FailedPrecondition, "Could not mount %q at %q, the controller host cannot mount file systems of type %q: %v. Please install efs-utils on the controller or set --internal-mounts-plain-nfs", fileSystemId, target, fsType, err)
+					}
+					return nil, status.Errorf(codes.Internal, "Could not mount %q at %q: %v", fileSystemId, target, err)
+				}
+				// The file system is unreachable, so leave the data behind rather than blocking removal of the volume forever.
+				klog.Warningf("DeleteVolume: Could not mount %q at %q: %v. Deleting access point %v and leaving its root directory %q in place", fileSystemId, target, err, accessPointId, accessPoint.AccessPointRootDir)
+			} else if err = d.removeRootDir(ctx, target, accessPoint.AccessPointRootDir); err != nil {
+				return nil, err
 			}
 		}
 
+		if d.retainAccessPoint {
+			klog.V(4).Infof("DeleteVolume: Retaining Access Point %v for reuse", accessPointId)
+			return &csi.DeleteVolumeResponse{}, nil
+		}
+
 		// Delete access point
-		if err = localCloud.DeleteAccessPoint(ctx, accessPointId); err != nil {
+		err = d.getTracer().Capture(ctx, "DeleteAccessPoint", func(ctx context.Context) error {
+			return localCloud.DeleteAccessPoint(ctx, accessPointId)
+		})
+		if err != nil {
 			if err == cloud.ErrAccessDenied {
 				return nil, status.Errorf(codes.Unauthenticated, "Access Denied. Please ensure you have the right AWS permissions: %v", err)
 			}
@@ -504,8 +1118,62 @@ func (d *Driver) ListSnapshots(ctx context.Context, req *csi.ListSnapshotsReques
 	return nil, status.Error(codes.Unimplemented, "")
 }
 
+// ControllerExpandVolume accepts any new size for an existing volume. EFS file systems and access points grow
+// elastically and have no size to change, the capacity only matters for matching PVCs to PVs.
 func (d *Driver) ControllerExpandVolume(ctx context.Context, req *csi.ControllerExpandVolumeRequest) (*csi.ControllerExpandVolumeResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+	klog.V(4).Infof("ControllerExpandVolume: called with args %+v", *req)
+	volId := req.GetVolumeId()
+	if volId == "" {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
+	}
+	capRange := req.GetCapacityRange()
+	if capRange == nil {
+		return nil, status.Error(codes.InvalidArgument, "Capacity range not provided")
+	}
+	newSize := capRange.GetRequiredBytes()
+	if newSize == 0 {
+		newSize = capRange.GetLimitBytes()
+	}
+	if newSize <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "Capacity range does not specify a size")
+	}
+	if limit := capRange.GetLimitBytes(); limit > 0 && newSize > limit {
+		return nil, status.Errorf(codes.OutOfRange, "Required bytes %d exceed limit bytes %d", newSize, limit)
+	}
+
+	handle, err := parseVolumeHandle(volId)
+	if status.Code(err) == codes.Unimplemented {
+		return nil, err
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "Volume not found, err: %v", err)
+	}
+	fileSystemId, accessPointId := handle.fileSystemId, handle.accessPointId
+
+	localCloud, _, err := getCloud(req.GetSecrets(), d, handle.region)
+	if err != nil {
+		return nil, err
+	}
+	if accessPointId != "" {
+		_, err = localCloud.DescribeAccessPoint(ctx, accessPointId)
+	} else {
+		_, err = localCloud.DescribeFileSystem(ctx, fileSystemId)
+	}
+	if err != nil {
+		if err == cloud.ErrAccessDenied {
+			return nil, status.Errorf(codes.Unauthenticated, "Access Denied. Please ensure you have the right AWS permissions: %v", err)
+		}
+		if err == cloud.ErrNotFound {
+			return nil, status.Errorf(codes.NotFound, "Volume %v not found: %v", volId, err)
+		}
+		return nil, status.Errorf(codes.Internal, "Could not describe volume %v: %v", volId, err)
+	}
+
+	klog.V(4).Infof("ControllerExpandVolume: volume %v is now %d bytes", volId, newSize)
+	return &csi.ControllerExpandVolumeResponse{
+		CapacityBytes:         newSize,
+		NodeExpansionRequired: false,
+	}, nil
 }
 
 func (d *Driver) ControllerGetVolume(ctx context.Context, req *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
@@ -513,8 +1181,9 @@ func (d *Driver) ControllerGetVolume(ctx context.Context, req *csi.ControllerGet
 	return nil, status.Error(codes.Unimplemented, "")
 }
 
-func getCloud(secrets map[string]string, driver *Driver) (cloud.Cloud, string, error) {
-
+// getCloud returns the cloud for a file system in region, which is empty for the region of the driver's clients,
+// assuming the cross account role in secrets if there is one. The role ARN is returned with it.
+func getCloud(secrets map[string]string, driver *Driver, region string) (cloud.Cloud, string, error) {
 	var localCloud cloud.Cloud
 	var roleArn string
 	var err error
@@ -526,10 +1195,43 @@ func getCloud(secrets map[string]string, driver *Driver) (cloud.Cloud, string, e
 	}
 
 	if roleArn != "" {
-		localCloud, err = cloud.NewCloudWithRole(roleArn)
+		if !roleArnPattern.MatchString(roleArn) {
+			return nil, "", status.Errorf(codes.InvalidArgument, "Secret %v has malformed value %q: expected an IAM role ARN of the form arn:aws:iam::<account-id>:role/<role-name>", RoleArn, roleArn)
+		}
+		var sessionDuration time.Duration
+		if value, ok := secrets[SessionDuration]; ok {
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, "", status.Errorf(codes.InvalidArgument, "Failed to parse invalid %v: %v", SessionDuration, err)
+			}
+			sessionDuration = time.Duration(seconds) * time.Second
+			if sessionDuration < cloud.MinSessionDuration || sessionDuration > cloud.MaxSessionDuration {
+				return nil, "", status.Errorf(codes.InvalidArgument, "%v must be between %d and %d seconds", SessionDuration,
+					int64(cloud.MinSessionDuration.Seconds()), int64(cloud.MaxSessionDuration.Seconds()))
+			}
+		}
+		// Assume the role with a web identity token, such as the one projected for IAM roles for service accounts
+		tokenFile, ok := secrets[WebIdentityTokenFile]
+		if ok && tokenFile == "" {
+			return nil, "", status.Errorf(codes.InvalidArgument, "Secret %v cannot be empty", WebIdentityTokenFile)
+		}
+		if driver.roleClouds != nil {
+			localCloud, err = driver.roleClouds.get(roleArn, tokenFile, sessionDuration, region)
+		} else {
+			localCloud, err = newRoleCloud(roleArn, tokenFile, sessionDuration, region, driver.endpointOpts, driver.metrics.observeAssumeRole)
+		}
 		if err != nil {
 			return nil, "", status.Errorf(codes.Unauthenticated, "Unable to initialize aws cloud: %v. Please verify role has the correct AWS permissions for cross account mount", err)
 		}
+	} else if region != "" {
+		if driver.roleClouds != nil {
+			localCloud, err = driver.roleClouds.get("", "", 0, region)
+		} else {
+			localCloud, err = newRoleCloud("", "", 0, region, driver.endpointOpts, nil)
+		}
+		if err != nil {
+			return nil, "", status.Errorf(codes.Internal, "Unable to initialize aws cloud for region %v: %v", region, err)
+		}
 	} else {
 		localCloud = driver.cloud
 	}
@@ -537,6 +1239,664 @@ func getCloud(secrets map[string]string, driver *Driver) (cloud.Cloud, string, e
 	return localCloud, roleArn, nil
 }
 
+// runWithContext runs fn, which cannot be interrupted, but stops waiting for it once ctx is done and returns
+// the context's error. The result of an abandoned fn is passed to abandoned once it finishes, so that it can be cleaned up.
+func runWithContext(ctx context.Context, fn func() error, abandoned func(error)) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		go func() {
+			abandoned(<-done)
+		}()
+		return ctx.Err()
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// retryThrottled calls fn until it returns an error other than cloud.ErrThrottled, retrying with jittered
+// exponential backoff at most d.throttleRetries times. It gives up early once ctx is done.
+func (d *Driver) retryThrottled(ctx context.Context, operation string, fn func() error) error {
+	backoff := wait.Backoff{
+		Duration: d.throttleRetryDelay,
+		Factor:   2,
+		Jitter:   0.5,
+		Steps:    d.throttleRetries,
+	}
+	for {
+		err := fn()
+		if err != cloud.ErrThrottled || backoff.Steps < 1 {
+			return err
+		}
+		delay := backoff.Step()
+		klog.V(4).Infof("%v was throttled, retrying in %v", operation, delay)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// tempMountDir returns the directory DeleteVolume creates its temporary mount points in.
+func (d *Driver) tempMountDir() string {
+	if d.tempMountPrefix != "" {
+		return d.tempMountPrefix
+	}
+	return TempMountPathPrefix
+}
+
+// cleanupTempMount unmounts and removes a temporary mount point that DeleteVolume gave up on. The mount point is only
+// removed once it is unmounted, so the file system behind it is never touched.
+func (d *Driver) cleanupTempMount(target string) {
+	if err := d.mounter.Unmount(target); err != nil {
+		klog.Warningf("Could not unmount abandoned temporary mount %q: %v", target, err)
+		return
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		klog.Warningf("Could not remove abandoned temporary mount point %q: %v", target, err)
+	}
+}
+
+// removeRootDir deletes the access point root directory rootDir of the file system mounted at target, or empties
+// it when the access point is retained. The file system is unmounted and target removed whether or not that
+// succeeds, and every step that failed is reported in the returned error.
+func (d *Driver) removeRootDir(ctx context.Context, target, rootDir string) error {
+	var errs []error
+	code := codes.Internal
+	removed := make(chan struct{})
+
+	rootDirPath := path.Join(target, rootDir)
+	if !isWithinDir(target, rootDirPath) || rootDirPath == path.Clean(target) {
+		close(removed)
+		code = codes.InvalidArgument
+		errs = append(errs, fmt.Errorf("Access point root directory %q does not resolve to a directory within the file system root", rootDir))
+	} else {
+		remover := newTreeRemover(d.rootDirDeleteWorkers)
+		err := d.getTracer().Capture(ctx, "RemoveRootDir", func(ctx context.Context) error {
+			return runWithContext(ctx, func() error {
+				defer close(removed)
+				if d.retainAccessPoint {
+					return remover.removeContents(ctx, rootDirPath)
+				}
+				return remover.removeAll(ctx, rootDirPath)
+			}, func(error) {
+				d.cleanupTempMount(target)
+			})
+		})
+		if isContextError(err) {
+			code = codes.DeadlineExceeded
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Could not delete access point root directory %q: %v", rootDir, err))
+		}
+	}
+
+	select {
+	case <-removed:
+		if err := d.getTracer().Capture(ctx, "Unmount", func(context.Context) error {
+			return d.mounter.Unmount(target)
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("Could not unmount %q: %v", target, err))
+		} else if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			// Remove only the now empty mount point, never what may still be mounted on it
+			errs = append(errs, fmt.Errorf("Could not delete %q: %v", target, err))
+		}
+	default:
+		// The removal was abandoned but is still running, it cleans up once it finishes
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return status.Error(code, errors.Join(errs...).Error())
+}
+
+// cleanupTempMounts unmounts and removes the temporary mount points an earlier run of the controller left in dir,
+// for example because it crashed in the middle of DeleteVolume. Only directories named after an access point are
+// touched, and they are removed with os.Remove so the contents of a file system that fails to unmount are never deleted.
+func (d *Driver) cleanupTempMounts(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			klog.Warningf("Could not list temporary mount points in %q: %v", dir, err)
+		}
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !tempMountName.MatchString(entry.Name()) {
+			continue
+		}
+		target := path.Join(dir, entry.Name())
+		notMnt, err := d.mounter.IsLikelyNotMountPoint(target)
+		if err != nil {
+			klog.Warningf("Could not check whether %q is mounted: %v", target, err)
+			continue
+		}
+		if !notMnt {
+			if err := d.mounter.Unmount(target); err != nil {
+				klog.Warningf("Could not unmount leftover temporary mount %q: %v", target, err)
+				continue
+			}
+		}
+		if err := os.Remove(target); err != nil {
+			klog.Warningf("Could not remove leftover temporary mount point %q: %v", target, err)
+			continue
+		}
+		klog.Infof("Cleaned up leftover temporary mount point %q", target)
+	}
+}
+
+// resolvedParameters records the settings an access point was provisioned with, after defaults, secrets and
+// templates were applied. Only the names of the secrets are kept.
+type resolvedParameters struct {
+	FileSystemId     string            `json:"fileSystemId"`
+	ProvisioningMode string            `json:"provisioningMode"`
+	BasePath         string            `json:"basePath,omitempty"`
+	RootDirectory    string            `json:"rootDirectory"`
+	DirectoryPerms   string            `json:"directoryPerms,omitempty"`
+	Uid              int64             `json:"uid"`
+	Gid              int64             `json:"gid"`
+	Tags             map[string]string `json:"tags,omitempty"`
+	Secrets          []string          `json:"secrets,omitempty"`
+}
+
+func newResolvedParameters(provisioningMode, basePath string, accessPointOpts *cloud.AccessPointOptions, secrets map[string]string) *resolvedParameters {
+	secretNames := make([]string, 0, len(secrets))
+	for k := range secrets {
+		secretNames = append(secretNames, k)
+	}
+	sort.Strings(secretNames)
+	return &resolvedParameters{
+		FileSystemId:     accessPointOpts.FileSystemId,
+		ProvisioningMode: provisioningMode,
+		BasePath:         basePath,
+		RootDirectory:    accessPointOpts.DirectoryPath,
+		DirectoryPerms:   accessPointOpts.DirectoryPerms,
+		Uid:              accessPointOpts.Uid,
+		Gid:              accessPointOpts.Gid,
+		Tags:             accessPointOpts.Tags,
+		Secrets:          secretNames,
+	}
+}
+
+// parseDirectoryPerms parses an octal file mode such as 700 or 0755 and returns it in the form EFS expects.
+func parseDirectoryPerms(value string) (string, error) {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 07777 {
+		return "", status.Errorf(codes.InvalidArgument, "%v %q must be an octal file mode, such as 700 or 0755", DirectoryPerms, value)
+	}
+	return fmt.Sprintf("%03o", mode), nil
+}
+
+// parseAllowedDirectoryPerms parses a comma separated list of octal modes into the set of directoryPerms values
+// CreateVolume accepts, normalised like parseDirectoryPerms. An empty list allows any mode and returns nil.
+func parseAllowedDirectoryPerms(value string) (map[string]bool, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	allowed := map[string]bool{}
+	for _, field := range strings.Split(value, ",") {
+		mode, err := parseDirectoryPerms(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed directory perms %q: %v", value, err)
+		}
+		allowed[mode] = true
+	}
+	return allowed, nil
+}
+
+// isWithinDir reports whether p is dir or below it once both are cleaned.
+func isWithinDir(dir, p string) bool {
+	dir, p = path.Clean(dir), path.Clean(p)
+	if dir == "/" {
+		return strings.HasPrefix(p, "/")
+	}
+	return p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// sanitizeVolumeName makes the volume name safe to use as a directory name according to the nameSanitization mode.
+// Slashes and null bytes can never be part of a directory name, so they are rejected unless they are replaced.
+func sanitizeVolumeName(volName, mode string) (string, error) {
+	switch mode {
+	case "", NameSanitizeNone:
+		if strings.ContainsAny(volName, "/\x00") {
+			return "", status.Errorf(codes.InvalidArgument, "Volume name %q cannot contain '/' or null bytes", volName)
+		}
+		return volName, nil
+	case NameSanitizeReject:
+		if unsafeNameChars.MatchString(volName) {
+			return "", status.Errorf(codes.InvalidArgument, "Volume name %q may only contain letters, digits, '.', '_' and '-'", volName)
+		}
+		return volName, nil
+	case NameSanitizeReplace:
+		return unsafeNameChars.ReplaceAllString(volName, "-"), nil
+	default:
+		return "", status.Errorf(codes.InvalidArgument, "%v must be one of %v, %v or %v, got %q", NameSanitization, NameSanitizeNone, NameSanitizeReject, NameSanitizeReplace, mode)
+	}
+}
+
+// parseUidGid resolves the Uid, Gid, OwnerUid or OwnerGid given by key. A value in the secrets takes precedence over
+// the storage class parameter, which takes precedence over the driver's default in driverDefaults. fallback, which is
+// -1 for a value the caller allocates or derives itself, is returned when none of them is set.
+func parseUidGid(key string, secrets, volumeParams map[string]string, driverDefaults map[string]int64, fallback int64) (int64, error) {
+	value, ok := secrets[key]
+	if !ok {
+		value, ok = volumeParams[key]
+	}
+	if !ok {
+		if id, ok := driverDefaults[key]; ok {
+			return id, nil
+		}
+		return fallback, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "Failed to parse invalid %v: %v", key, err)
+	}
+	if id < 0 || id > MaxPosixId {
+		return 0, status.Errorf(codes.InvalidArgument, "%v must be between 0 and %d", key, int64(MaxPosixId))
+	}
+	return id, nil
+}
+
+// parseSecondaryGids parses a comma separated list of the secondary group IDs of an access point's POSIX user.
+func parseSecondaryGids(value string) ([]int64, error) {
+	var gids []int64
+	for _, field := range strings.Split(value, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Failed to parse invalid %v: %v", SecondaryGids, err)
+		}
+		if id < 0 || id > MaxPosixId {
+			return nil, status.Errorf(codes.InvalidArgument, "%v must be between 0 and %d, got %d", SecondaryGids, int64(MaxPosixId), id)
+		}
+		gids = append(gids, id)
+	}
+	if len(gids) > MaxSecondaryGids {
+		return nil, status.Errorf(codes.InvalidArgument, "%v can list at most %d group IDs, got %d", SecondaryGids, MaxSecondaryGids, len(gids))
+	}
+	return gids, nil
+}
+
+// getTags merges the tags for an access point, keeping at most MaxTagsPerResource of them and returning the keys
+// of the tags it dropped. When a key is set by more than one source, or there are too many tags, tags given to the
+// driver win over tags given to it as templates, which win over tags inherited from the file system, which win over
+// the driver's default tags. The ownership tag DefaultTagKey is the exception and always kept. Tags given to the
+// driver that use DriverTagKeyPrefix, and a kept tag whose key or value is longer than AWS allows, are InvalidArgument.
+func getTags(userTags, templateTags, inheritedTags, defaultTags map[string]string) (map[string]string, []string, error) {
+	var reserved []string
+	for _, source := range []map[string]string{userTags, templateTags} {
+		for k := range source {
+			if strings.HasPrefix(k, DriverTagKeyPrefix) {
+				reserved = append(reserved, k)
+			}
+		}
+	}
+	if len(reserved) > 0 {
+		sort.Strings(reserved)
+		return nil, nil, status.Errorf(codes.InvalidArgument, "Tags %v use the prefix %v, which is reserved for the driver's own tags", reserved, DriverTagKeyPrefix)
+	}
+	tags := map[string]string{}
+	// The ownership tag marks the access points the driver may delete, so it is never dropped
+	if value, ok := defaultTags[DefaultTagKey]; ok {
+		tags[DefaultTagKey] = value
+	}
+
+	var dropped []string
+	for _, source := range []map[string]string{userTags, templateTags, inheritedTags, defaultTags} {
+		keys := make([]string, 0, len(source))
+		for k := range source {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, ok := tags[k]; ok {
+				continue
+			}
+			if len(tags) == MaxTagsPerResource {
+				dropped = append(dropped, k)
+				continue
+			}
+			tags[k] = source[k]
+		}
+	}
+	if len(dropped) > 0 {
+		klog.Warningf("Access points can have at most %d tags, dropping tags %v", MaxTagsPerResource, dropped)
+	}
+
+	var tooLong []string
+	for k, v := range tags {
+		if utf8.RuneCountInString(k) > MaxTagKeyLength || utf8.RuneCountInString(v) > MaxTagValueLength {
+			tooLong = append(tooLong, k)
+		}
+	}
+	if len(tooLong) > 0 {
+		sort.Strings(tooLong)
+		return nil, nil, status.Errorf(codes.InvalidArgument, "Tag keys can be at most %d characters and values at most %d characters, tags over the limit: %v", MaxTagKeyLength, MaxTagValueLength, tooLong)
+	}
+	return tags, dropped, nil
+}
+
+// pvcStorageClassName returns the storage class of the PVC a volume is being provisioned for, or "" when it cannot
+// be found. It needs a Kubernetes client and the PVC name and namespace passed with --extra-create-metadata.
+func (d *Driver) pvcStorageClassName(ctx context.Context, volumeParams map[string]string) string {
+	name, namespace := volumeParams[PvcName], volumeParams[PvcNamespace]
+	if d.kubeClient == nil || name == "" || namespace == "" {
+		return ""
+	}
+	pvc, err := d.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		klog.V(4).Infof("Could not get PVC %v/%v to find its storage class: %v", namespace, name, err)
+		return ""
+	}
+	if pvc.Spec.StorageClassName == nil {
+		return ""
+	}
+	return *pvc.Spec.StorageClassName
+}
+
+// expandTagTemplates expands the {{ .PVCName }}, {{ .PVCNamespace }} and {{ .PVName }} placeholders in tag values
+// with the PVC metadata the external-provisioner passes in the Volume Parameters.
+func expandTagTemplates(tags, volumeParams map[string]string) (map[string]string, error) {
+	fields := map[string]string{}
+	for field, volumeParamsKey := range tagTemplateFields {
+		if value, ok := volumeParams[volumeParamsKey]; ok && value != "" {
+			fields[field] = value
+		}
+	}
+
+	expanded := make(map[string]string, len(tags))
+	for k, v := range tags {
+		if !strings.Contains(v, "{{") {
+			expanded[k] = v
+			continue
+		}
+		tmpl, err := template.New(k).Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Tag %v has an invalid template value %q: %v", k, v, err)
+		}
+		var value strings.Builder
+		if err = tmpl.Execute(&value, fields); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Tag %v value %q could not be expanded, it can only reference %v, "+
+				"which require --extra-create-metadata on the csi-provisioner: %v", k, v, getTagTemplateFieldNames(), err)
+		}
+		expanded[k] = value.String()
+	}
+	return expanded, nil
+}
+
+func getTagTemplateFieldNames() []string {
+	names := make([]string, 0, len(tagTemplateFields))
+	for field := range tagTemplateFields {
+		names = append(names, "."+field)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// findExistingAccessPoint returns the access point created with clientToken on the file system. The uid, gid and
+// root directory are only compared when they were requested rather than generated, a value of -1 or "" skips them.
+func findExistingAccessPoint(ctx context.Context, localCloud cloud.Cloud, clientToken, fileSystemId string, uid, gid int64, rootDir string) (*cloud.AccessPoint, error) {
+	accessPoints, err := localCloud.ListAccessPoints(ctx, fileSystemId)
+	if err != nil {
+		if err == cloud.ErrAccessDenied {
+			return nil, status.Errorf(codes.Unauthenticated, "Access Point of an earlier attempt exists on File System %v but cannot be looked up, allow elasticfilesystem:DescribeAccessPoints: %v", fileSystemId, err)
+		}
+		return nil, status.Errorf(codes.Internal, "Failed to list Access Points of File System %v: %v", fileSystemId, err)
+	}
+
+	for _, ap := range accessPoints {
+		if ap == nil || ap.ClientToken != clientToken {
+			continue
+		}
+		if ap.PosixUser != nil && ((uid != -1 && ap.PosixUser.Uid != uid) || (gid != -1 && ap.PosixUser.Gid != gid)) {
+			return nil, status.Errorf(codes.AlreadyExists, "Access Point %v already exists with uid %d and gid %d, requested uid %d and gid %d",
+				ap.AccessPointId, ap.PosixUser.Uid, ap.PosixUser.Gid, uid, gid)
+		}
+		if rootDir != "" && ap.AccessPointRootDir != rootDir {
+			return nil, status.Errorf(codes.AlreadyExists, "Access Point %v already exists with root directory %v, requested %v",
+				ap.AccessPointId, ap.AccessPointRootDir, rootDir)
+		}
+		return ap, nil
+	}
+	return nil, status.Errorf(codes.AlreadyExists, "Access Point already exists")
+}
+
+// checkFileSystemVpc ensures the file system can be reached through an available mount target in the expected VPC.
+func checkFileSystemVpc(ctx context.Context, localCloud cloud.Cloud, fileSystemId, vpcId string) error {
+	mountTargets, err := localCloud.ListMountTargets(ctx, fileSystemId)
+	if err != nil {
+		if err == cloud.ErrAccessDenied {
+			return status.Errorf(codes.Unauthenticated, "Access Denied. Please ensure you have the right AWS permissions: %v", err)
+		}
+		if err == cloud.ErrNotFound {
+			return status.Errorf(codes.InvalidArgument, "File System does not exist: %v", err)
+		}
+		return status.Errorf(codes.Internal, "Failed to list Mount Targets of File System %v: %v", fileSystemId, err)
+	}
+
+	for _, mt := range mountTargets {
+		if mt.VpcId == vpcId {
+			klog.V(5).Infof("Mount target %v of File System %v is in expected VPC %v", mt.MountTargetId, fileSystemId, vpcId)
+			return nil
+		}
+	}
+	return status.Errorf(codes.FailedPrecondition, "File System %v has no available mount target in expected VPC %v", fileSystemId, vpcId)
+}
+
+// describeMountTarget picks the mount target of the file system the controller uses through localCloud, the cloud of
+// roleArn in region. It is the mount target in subnetId when that is set, which is InvalidArgument if there is none,
+// and otherwise prefers azName. When
+// mount target probing is enabled, mount targets whose NFS port cannot be reached are skipped. A file system without
+// any mount target cannot be mounted, so that is FailedPrecondition rather than a mount that fails in efs-utils.
+func (d *Driver) describeMountTarget(ctx context.Context, localCloud cloud.Cloud, roleArn, region, fileSystemId, azName, subnetId string) (*cloud.MountTarget, error) {
+	if !d.probeMountTargets {
+		mountTarget, err := d.mountTargets.describe(ctx, localCloud, roleArn, region, fileSystemId, azName, subnetId)
+		if subnetId != "" && err == cloud.ErrNotFound {
+			return nil, status.Errorf(codes.InvalidArgument, "File System %v has no available mount target in %v %v", fileSystemId, SubnetId, subnetId)
+		}
+		if err == cloud.ErrNoMountTargets {
+			return nil, status.Errorf(codes.FailedPrecondition, "File System %v has no mount targets, create a mount target in a subnet the cluster can reach to mount it", fileSystemId)
+		}
+		return mountTarget, err
+	}
+
+	mountTargets, err := localCloud.ListMountTargets(ctx, fileSystemId)
+	if err != nil {
+		return nil, err
+	}
+	if subnetId != "" {
+		var inSubnet []*cloud.MountTarget
+		for _, mt := range mountTargets {
+			if mt.SubnetId == subnetId {
+				inSubnet = append(inSubnet, mt)
+			}
+		}
+		if len(inSubnet) == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "File System %v has no available mount target in %v %v", fileSystemId, SubnetId, subnetId)
+		}
+		mountTargets = inSubnet
+	}
+	// Mount targets in the requested availability zone are tried first
+	sort.SliceStable(mountTargets, func(i, j int) bool {
+		return azName != "" && mountTargets[i].AZName == azName && mountTargets[j].AZName != azName
+	})
+	for _, mt := range mountTargets {
+		if err := d.probeMountTarget(ctx, mt.IPAddress); err != nil {
+			klog.Warningf("Skipping mount target %v of File System %v in %v: %v", mt.MountTargetId, fileSystemId, mt.AZName, err)
+			continue
+		}
+		return mt, nil
+	}
+	return nil, status.Errorf(codes.FailedPrecondition, "None of the %d available mount targets of File System %v are reachable on port %v", len(mountTargets), fileSystemId, NfsPort)
+}
+
+func (d *Driver) probeMountTarget(ctx context.Context, ipAddress string) error {
+	ctx, cancel := context.WithTimeout(ctx, MountProbeTimeout)
+	defer cancel()
+	dialContext := d.dialContext
+	if dialContext == nil {
+		dialContext = (&net.Dialer{}).DialContext
+	}
+	conn, err := dialContext(ctx, "tcp", net.JoinHostPort(ipAddress, NfsPort))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// checkNamespaceAccessPointQuota counts the driver owned access points on the file system that were provisioned
+// for the given namespace and fails once the namespace has reached its quota.
+func checkNamespaceAccessPointQuota(ctx context.Context, localCloud cloud.Cloud, fileSystemId, namespace string, quota int) error {
+	accessPoints, err := localCloud.ListAccessPoints(ctx, fileSystemId)
+	if err != nil {
+		if err == cloud.ErrAccessDenied {
+			return status.Errorf(codes.Unauthenticated, "Access Denied. Please ensure you have the right AWS permissions: %v", err)
+		}
+		return status.Errorf(codes.Internal, "Failed to list Access Points of File System %v: %v", fileSystemId, err)
+	}
+
+	used := 0
+	for _, ap := range accessPoints {
+		if ap == nil {
+			continue
+		}
+		if _, ok := ap.Tags[DefaultTagKey]; ok && ap.Tags[PvcNamespaceTagKey] == namespace {
+			used++
+		}
+	}
+	klog.V(5).Infof("Namespace %v is using %d of %d access points on File System %v", namespace, used, quota, fileSystemId)
+
+	if used >= quota {
+		return status.Errorf(codes.ResourceExhausted, "Namespace %v has reached its quota of %d access points on File System %v", namespace, quota, fileSystemId)
+	}
+	return nil
+}
+
+// checkProvisioningMode fails with InvalidArgument listing the supported modes when mode is not one of them.
+func checkProvisioningMode(mode string) error {
+	for _, supported := range provisioningModes {
+		if mode == supported {
+			return nil
+		}
+	}
+	return status.Errorf(codes.InvalidArgument, "Provisioning mode %q is not supported, %v must be one of %v", mode, ProvisioningMode, provisioningModes)
+}
+
+// findFileSystemByName returns the ID of the only file system whose Name tag is name.
+func findFileSystemByName(ctx context.Context, localCloud cloud.Cloud, name string) (string, error) {
+	fileSystems, err := localCloud.FindFileSystemByTag(ctx, NameTagKey, name)
+	if err != nil {
+		if err == cloud.ErrAccessDenied {
+			return "", status.Errorf(codes.Unauthenticated, "Access Denied. Please ensure you have the right AWS permissions: %v", err)
+		}
+		return "", status.Errorf(codes.Internal, "Failed to find File System named %v: %v", name, err)
+	}
+	switch len(fileSystems) {
+	case 0:
+		return "", status.Errorf(codes.InvalidArgument, "No File System has the %v tag %q", NameTagKey, name)
+	case 1:
+		klog.V(4).Infof("CreateVolume: %v %q is File System %v", FileSystemName, name, fileSystems[0].FileSystemId)
+		return fileSystems[0].FileSystemId, nil
+	}
+	fileSystemIds := make([]string, 0, len(fileSystems))
+	for _, fileSystem := range fileSystems {
+		fileSystemIds = append(fileSystemIds, fileSystem.FileSystemId)
+	}
+	sort.Strings(fileSystemIds)
+	return "", status.Errorf(codes.InvalidArgument, "File Systems %v all have the %v tag %q, use %v to pick one", fileSystemIds, NameTagKey, name, FsId)
+}
+
+// fileSystemArn is a file system referenced by its ARN.
+type fileSystemArn struct {
+	partition    string
+	region       string
+	accountId    string
+	fileSystemId string
+}
+
+func parseFileSystemArn(value string) (*fileSystemArn, error) {
+	match := fileSystemArnPattern.FindStringSubmatch(value)
+	if match == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v %q is malformed: expected an ARN of the form arn:aws:elasticfilesystem:<region>:<account-id>:file-system/<file-system-id>", FileSystemArn, value)
+	}
+	return &fileSystemArn{
+		partition:    match[1],
+		region:       match[2],
+		accountId:    match[3],
+		fileSystemId: match[4],
+	}, nil
+}
+
+// checkFileSystemArn fails when the file system fsArn references cannot be reached with roleArn. EFS API calls are
+// made in the account of a cross account role, so it has to be the account that owns the file system.
+func checkFileSystemArn(fsArn *fileSystemArn, roleArn string) error {
+	if roleArn != "" {
+		if roleAccountId := strings.Split(roleArn, ":")[4]; roleAccountId != fsArn.accountId {
+			return status.Errorf(codes.InvalidArgument, "File System %v is owned by account %v, but %v %v is in account %v", fsArn.fileSystemId, fsArn.accountId, RoleArn, roleArn, roleAccountId)
+		}
+	}
+	return nil
+}
+
+// clientRegion returns the region of the driver's own clients: --region, or else the region of the instance or task
+// the driver runs on.
+func (d *Driver) clientRegion() string {
+	if d.endpointOpts.Region != "" {
+		return d.endpointOpts.Region
+	}
+	if d.cloud == nil {
+		return ""
+	}
+	if metadata := d.cloud.GetMetadata(); metadata != nil {
+		return metadata.GetRegion()
+	}
+	return ""
+}
+
+// checkFileSystemLifeCycleState fails when access points cannot be created in fileSystem because of its lifecycle
+// state. A file system that is being created or updated becomes available again, so that is Unavailable and retried.
+// An unknown state, such as one that was not described, is allowed.
+func checkFileSystemLifeCycleState(fileSystem *cloud.FileSystem) error {
+	switch fileSystem.LifeCycleState {
+	case "", efs.LifeCycleStateAvailable:
+		return nil
+	case efs.LifeCycleStateCreating, efs.LifeCycleStateUpdating:
+		return status.Errorf(codes.Unavailable, "File System %v is %v, please retry once it is %v", fileSystem.FileSystemId, fileSystem.LifeCycleState, efs.LifeCycleStateAvailable)
+	default:
+		return status.Errorf(codes.FailedPrecondition, "File System %v is %v, Access Points can only be created while it is %v", fileSystem.FileSystemId, fileSystem.LifeCycleState, efs.LifeCycleStateAvailable)
+	}
+}
+
+// checkAccessPointLimit fails with ResourceExhausted when a file system already has limit access points, so
+// CreateVolume fails with a clear error instead of CreateAccessPoint failing once EFS's quota is reached. The check
+// is skipped when the caller may not list access points, CreateAccessPoint still enforces the quota.
+func checkAccessPointLimit(ctx context.Context, localCloud cloud.Cloud, fileSystemId string, limit int) error {
+	accessPoints, err := localCloud.ListAccessPoints(ctx, fileSystemId)
+	if err != nil {
+		if err == cloud.ErrAccessDenied {
+			klog.Warningf("Skipping the access point limit check of File System %v, access points cannot be listed: %v", fileSystemId, err)
+			return nil
+		}
+		return status.Errorf(codes.Internal, "Failed to list Access Points of File System %v: %v", fileSystemId, err)
+	}
+	klog.V(5).Infof("File System %v has %d of %d access points", fileSystemId, len(accessPoints), limit)
+
+	if len(accessPoints) >= limit {
+		return status.Errorf(codes.ResourceExhausted, "File System %v has reached the limit of %d access points, delete unused access points or raise --access-point-limit if the quota in this region is higher", fileSystemId, limit)
+	}
+	return nil
+}
+
 func interpolateRootDirectoryName(rootDirectoryPath string, volumeParams map[string]string) (string, error) {
 	r := strings.NewReplacer(createListOfVariableSubstitutions(volumeParams)...)
 	result := r.Replace(rootDirectoryPath)
@@ -590,3 +1950,13 @@ func get64LenHash(text string) string {
 	h.Write([]byte(text))
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
+
+// getPathHash returns a short hash identifying the claim a volume is provisioned for, its PVC namespace and name
+// when the external-provisioner passes them and the PV name otherwise.
+func getPathHash(volName string, volumeParams map[string]string) string {
+	key := volName
+	if pvcName, ok := volumeParams[PvcName]; ok {
+		key = volumeParams[PvcNamespace] + "/" + pvcName
+	}
+	return get64LenHash(key)[:UniquePathHashLength]
+}
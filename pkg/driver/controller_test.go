@@ -2,16 +2,29 @@ package driver
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"os"
+	"path"
+	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 
+	"github.com/aws/aws-sdk-go/service/efs"
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/golang/mock/gomock"
 
@@ -49,7 +62,7 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
@@ -106,7 +119,161 @@ func TestCreateVolume(t *testing.T) {
 			},
 		},
 		{
-			name: "Success: Using fixed UID/GID and GID range",
+			name: "Success: Emit a v1 volume ID",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:       endpoint,
+					cloud:          mockCloud,
+					gidAllocator:   NewGidAllocator(),
+					volumeIdFormat: volumeIdV1,
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						Uid:              "1000",
+						Gid:              "1001",
+					},
+				}
+
+				ctx := context.Background()
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(&cloud.FileSystem{FileSystemId: fsId}, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any(), gomock.Eq(false)).Return(accessPoint, nil)
+
+				res, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				if expected := "v1:accesspoint:" + fsId + ":" + apId; res.Volume.VolumeId != expected {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", expected, res.Volume.VolumeId)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Using the driver's default UID/GID",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					defaultIds:   map[string]int64{Uid: 1000, Gid: 1001},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						BasePath:         "test",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				// No gid is allocated, so the access points are not listed
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any(), gomock.Eq(false)).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if accessPointOpts.Uid != 1000 {
+							t.Fatalf("Uid mimatched. Expected: %v, actual: %v", 1000, accessPointOpts.Uid)
+						}
+						if accessPointOpts.Gid != 1001 {
+							t.Fatalf("Gid mimatched. Expected: %v, actual: %v", 1001, accessPointOpts.Gid)
+						}
+					})
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Mount option parameters are recorded on the access point",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						Uid:              "1000",
+						Gid:              "1000",
+						MountOptions:     "regional,noresvport",
+						EncryptInTransit: "false",
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(&cloud.FileSystem{FileSystemId: fsId}, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any(), gomock.Eq(false)).Return(&cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId}, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if tag := accessPointOpts.Tags[MountOptionsTagKey]; tag != "regional noresvport" {
+							t.Fatalf("Mount options tag mismatched. Expected: %v, actual: %v", "regional noresvport", tag)
+						}
+						if tag := accessPointOpts.Tags[EncryptInTransitTagKey]; tag != "false" {
+							t.Fatalf("Encrypt in transit tag mismatched. Expected: %v, actual: %v", "false", tag)
+						}
+					})
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Mount option parameters conflict",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -114,7 +281,44 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						MountOptions:     "tls",
+						EncryptInTransit: "false",
+					},
+				}
+
+				ctx := context.Background()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: GID range overrides fixed GID",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
@@ -146,13 +350,14 @@ func TestCreateVolume(t *testing.T) {
 					FileSystemId:  fsId,
 				}
 				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
 				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
 					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
 						if accessPointOpts.Uid != 1000 {
 							t.Fatalf("Uid mimatched. Expected: %v, actual: %v", accessPointOpts.Uid, 1000)
 						}
-						if accessPointOpts.Gid != 1001 {
-							t.Fatalf("Gid mimatched. Expected: %v, actual: %v", accessPointOpts.Uid, 1001)
+						if accessPointOpts.Gid != 10000 {
+							t.Fatalf("Gid mimatched. Expected: %v, actual: %v", 10000, accessPointOpts.Gid)
 						}
 					})
 
@@ -181,7 +386,7 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
@@ -267,7 +472,7 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
@@ -401,7 +606,7 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
@@ -496,7 +701,7 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 					tags:         parseTagsFromStr(""),
 				}
 
@@ -548,11 +753,21 @@ func TestCreateVolume(t *testing.T) {
 				if res.Volume.VolumeId != volumeId {
 					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
 				}
+
+				expectedVolContext := map[string]string{
+					VolCtxRootDir:        "/" + volumeName,
+					VolCtxUid:            "2000",
+					VolCtxGid:            "2000",
+					VolCtxDirectoryPerms: "777",
+				}
+				if !reflect.DeepEqual(res.Volume.VolumeContext, expectedVolContext) {
+					t.Fatalf("Volume context mismatched. Expected: %v, Actual: %v", expectedVolContext, res.Volume.VolumeContext)
+				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Using Default GID ranges",
+			name: "Success: Resolved parameters are emitted without secret values",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -560,7 +775,8 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr("Team:storage"),
 				}
 
 				req := &csi.CreateVolumeRequest{
@@ -572,10 +788,17 @@ func TestCreateVolume(t *testing.T) {
 						RequiredBytes: capacityRange,
 					},
 					Parameters: map[string]string{
-						ProvisioningMode: "efs-ap",
-						FsId:             fsId,
-						DirectoryPerms:   "777",
-						BasePath:         "test",
+						ProvisioningMode:   "efs-ap",
+						FsId:               fsId,
+						GidMin:             "1000",
+						GidMax:             "2000",
+						DirectoryPerms:     "700",
+						BasePath:           "/dynamic",
+						EmitResolvedParams: "true",
+					},
+					Secrets: map[string]string{
+						Uid:           "1234",
+						"credentials": "top-secret",
 					},
 				}
 
@@ -586,33 +809,46 @@ func TestCreateVolume(t *testing.T) {
 				accessPoint := &cloud.AccessPoint{
 					AccessPointId: apId,
 					FileSystemId:  fsId,
-					PosixUser: &cloud.PosixUser{
-						Gid: DefaultGidMin - 1, //use GID that is not in default range
-					},
 				}
-				accessPoints := []*cloud.AccessPoint{accessPoint}
 				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
-				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(accessPoints, nil)
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
 
 				res, err := driver.CreateVolume(ctx, req)
-
 				if err != nil {
 					t.Fatalf("CreateVolume failed: %v", err)
 				}
 
-				if res.Volume == nil {
-					t.Fatal("Volume is nil")
+				encoded, ok := res.Volume.VolumeContext[VolCtxResolvedParams]
+				if !ok {
+					t.Fatalf("Volume context is missing %v: %v", VolCtxResolvedParams, res.Volume.VolumeContext)
 				}
-
-				if res.Volume.VolumeId != volumeId {
-					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
+				if strings.Contains(encoded, "top-secret") {
+					t.Fatalf("Resolved parameters contain a secret value: %v", encoded)
+				}
+				var resolved resolvedParameters
+				if err := json.Unmarshal([]byte(encoded), &resolved); err != nil {
+					t.Fatalf("Failed to decode resolved parameters %v: %v", encoded, err)
+				}
+				expected := resolvedParameters{
+					FileSystemId:     fsId,
+					ProvisioningMode: "efs-ap",
+					BasePath:         "/dynamic",
+					RootDirectory:    "/dynamic/" + volumeName,
+					DirectoryPerms:   "700",
+					Uid:              1234,
+					Gid:              2000,
+					Tags:             map[string]string{"Team": "storage", DefaultTagKey: DefaultTagValue},
+					Secrets:          []string{"credentials", Uid},
+				}
+				if !reflect.DeepEqual(resolved, expected) {
+					t.Fatalf("Resolved parameters mismatched. Expected: %+v, Actual: %+v", expected, resolved)
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Normal flow with tags",
+			name: "Success: Nil CapacityRange uses the default volume size",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -620,8 +856,8 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
-					tags:         parseTagsFromStr("cluster:efs"),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
 				req := &csi.CreateVolumeRequest{
@@ -629,14 +865,9 @@ func TestCreateVolume(t *testing.T) {
 					VolumeCapabilities: []*csi.VolumeCapability{
 						stdVolCap,
 					},
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
-						GidMin:           "1000",
-						GidMax:           "2000",
 						DirectoryPerms:   "777",
 					},
 				}
@@ -648,15 +879,10 @@ func TestCreateVolume(t *testing.T) {
 				accessPoint := &cloud.AccessPoint{
 					AccessPointId: apId,
 					FileSystemId:  fsId,
-					PosixUser: &cloud.PosixUser{
-						Gid: 1000,
-						Uid: 1000,
-					},
 				}
-				accessPoints := []*cloud.AccessPoint{accessPoint}
 				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
-				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(accessPoints, nil)
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
 
 				res, err := driver.CreateVolume(ctx, req)
 
@@ -664,18 +890,14 @@ func TestCreateVolume(t *testing.T) {
 					t.Fatalf("CreateVolume failed: %v", err)
 				}
 
-				if res.Volume == nil {
-					t.Fatal("Volume is nil")
-				}
-
-				if res.Volume.VolumeId != volumeId {
-					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
+				if res.Volume.CapacityBytes != DefaultVolumeSize {
+					t.Fatalf("Volume size mismatched. Expected: %v, Actual: %v", int64(DefaultVolumeSize), res.Volume.CapacityBytes)
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Normal flow with invalid tags",
+			name: "Success: Inherit allowed tags from the file system",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -683,8 +905,8 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
-					tags:         parseTagsFromStr("cluster-efs"),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr("Team:storage"),
 				}
 
 				req := &csi.CreateVolumeRequest{
@@ -698,47 +920,50 @@ func TestCreateVolume(t *testing.T) {
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
-						GidMin:           "1000",
-						GidMax:           "2000",
 						DirectoryPerms:   "777",
+						InheritFsTags:    "CostCenter, Team, " + DefaultTagKey + ", efs.csi.aws.com/future-tag",
 					},
 				}
 
 				ctx := context.Background()
 				fileSystem := &cloud.FileSystem{
 					FileSystemId: fsId,
+					Tags: map[string]string{
+						"CostCenter":                 "1234",
+						"Team":                       "platform",
+						"Environment":                "prod",
+						DefaultTagKey:                "false",
+						"efs.csi.aws.com/future-tag": "inherited",
+					},
 				}
 				accessPoint := &cloud.AccessPoint{
 					AccessPointId: apId,
 					FileSystemId:  fsId,
-					PosixUser: &cloud.PosixUser{
-						Gid: 1000,
-						Uid: 1000,
-					},
 				}
-				accessPoints := []*cloud.AccessPoint{accessPoint}
+				expectedTags := map[string]string{
+					DefaultTagKey: DefaultTagValue,
+					"CostCenter":  "1234",
+					"Team":        "storage",
+				}
 				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
-				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(accessPoints, nil)
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if !reflect.DeepEqual(accessPointOpts.Tags, expectedTags) {
+							t.Fatalf("Tags mismatched. Expected: %v, actual: %v", expectedTags, accessPointOpts.Tags)
+						}
+					})
 
-				res, err := driver.CreateVolume(ctx, req)
+				_, err := driver.CreateVolume(ctx, req)
 
 				if err != nil {
 					t.Fatalf("CreateVolume failed: %v", err)
 				}
-
-				if res.Volume == nil {
-					t.Fatal("Volume is nil")
-				}
-
-				if res.Volume.VolumeId != volumeId {
-					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
-				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: reuseAccessPointName is true",
+			name: "Fail: inheritFileSystemTags lists no tag keys",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -746,10 +971,9 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 					tags:         parseTagsFromStr(""),
 				}
-				pvcNameVal := "test-pvc"
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
@@ -760,54 +984,23 @@ func TestCreateVolume(t *testing.T) {
 						RequiredBytes: capacityRange,
 					},
 					Parameters: map[string]string{
-						ProvisioningMode:    "efs-ap",
-						FsId:                fsId,
-						GidMin:              "1000",
-						GidMax:              "2000",
-						DirectoryPerms:      "777",
-						AzName:              "us-east-1a",
-						ReuseAccessPointKey: "true",
-						PvcNameKey:          pvcNameVal,
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						InheritFsTags:    " , ",
 					},
 				}
 
 				ctx := context.Background()
-				fileSystem := &cloud.FileSystem{
-					FileSystemId: fsId,
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
 				}
-
-				accessPoint := &cloud.AccessPoint{
-					AccessPointId: apId,
-					FileSystemId:  fsId,
-					PosixUser: &cloud.PosixUser{
-						Gid: 1000,
-						Uid: 1000,
-					},
-				}
-				accessPoints := []*cloud.AccessPoint{accessPoint}
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
-				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(accessPoints, nil)
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(get64LenHash(pvcNameVal)), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
-
-				res, err := driver.CreateVolume(ctx, req)
-
-				if err != nil {
-					t.Fatalf("CreateVolume failed: %v", err)
-				}
-
-				if res.Volume == nil {
-					t.Fatal("Volume is nil")
-				}
-
-				if res.Volume.VolumeId != volumeId {
-					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
-				}
-
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Normal flow with a valid directory structure set",
+			name: "Success: Root directory name matches rootDirNamePattern",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -815,14 +1008,10 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 					tags:         parseTagsFromStr(""),
 				}
 
-				pvName := "foo"
-				pvcName := "bar"
-				directoryCreated := fmt.Sprintf("/%s/%s", pvName, pvcName)
-
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
 					VolumeCapabilities: []*csi.VolumeCapability{
@@ -832,14 +1021,14 @@ func TestCreateVolume(t *testing.T) {
 						RequiredBytes: capacityRange,
 					},
 					Parameters: map[string]string{
-						ProvisioningMode: "efs-ap",
-						FsId:             fsId,
-						GidMin:           "1000",
-						GidMax:           "2000",
-						DirectoryPerms:   "777",
-						SubPathPattern:   "${.PV.name}/${.PVC.name}",
-						PvName:           pvName,
-						PvcName:          pvcName,
+						ProvisioningMode:      "efs-ap",
+						FsId:                  fsId,
+						DirectoryPerms:        "777",
+						BasePath:              "team",
+						SubPathPattern:        "${.PVC.name}",
+						EnsureUniqueDirectory: "false",
+						PvcNameKey:            "pvc-12345",
+						RootDirNamePattern:    `pvc-[0-9]+`,
 					},
 				}
 
@@ -853,35 +1042,17 @@ func TestCreateVolume(t *testing.T) {
 				}
 				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
 				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
 
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
-					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPoint bool) {
-						if !verifyPathWhenUUIDIncluded(accessPointOpts.DirectoryPath, directoryCreated) {
-							t.Fatalf("Root directory mismatch. Expected: %v (with UID appended), actual: %v",
-								directoryCreated,
-								accessPointOpts.DirectoryPath)
-						}
-					})
-
-				res, err := driver.CreateVolume(ctx, req)
-
+				_, err := driver.CreateVolume(ctx, req)
 				if err != nil {
 					t.Fatalf("CreateVolume failed: %v", err)
 				}
-
-				if res.Volume == nil {
-					t.Fatal("Volume is nil")
-				}
-
-				if res.Volume.VolumeId != volumeId {
-					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
-				}
-
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Normal flow with a valid directory structure set, using a single element",
+			name: "Fail: Root directory name does not match rootDirNamePattern",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -889,13 +1060,10 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 					tags:         parseTagsFromStr(""),
 				}
 
-				pvcName := "foo"
-				directoryCreated := fmt.Sprintf("/%s", pvcName)
-
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
 					VolumeCapabilities: []*csi.VolumeCapability{
@@ -905,55 +1073,68 @@ func TestCreateVolume(t *testing.T) {
 						RequiredBytes: capacityRange,
 					},
 					Parameters: map[string]string{
-						ProvisioningMode: "efs-ap",
-						FsId:             fsId,
-						GidMin:           "1000",
-						GidMax:           "2000",
-						DirectoryPerms:   "777",
-						SubPathPattern:   "${.PVC.name}",
-						PvcName:          pvcName,
+						ProvisioningMode:      "efs-ap",
+						FsId:                  fsId,
+						DirectoryPerms:        "777",
+						BasePath:              "team",
+						SubPathPattern:        "${.PVC.name}",
+						EnsureUniqueDirectory: "false",
+						PvcNameKey:            "pvc-12345",
+						RootDirNamePattern:    `pvc-[0-9]{3}`,
 					},
 				}
 
 				ctx := context.Background()
-				fileSystem := &cloud.FileSystem{
-					FileSystemId: fsId,
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
 				}
-				accessPoint := &cloud.AccessPoint{
-					AccessPointId: apId,
-					FileSystemId:  fsId,
+				if !strings.Contains(err.Error(), "pvc-12345") {
+					t.Fatalf("Expected error to name the offending directory, got: %v", err)
 				}
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
-				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
-
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
-					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
-						if !verifyPathWhenUUIDIncluded(accessPointOpts.DirectoryPath, directoryCreated) {
-							t.Fatalf("Root directory mismatch. Expected: %v (with UID appended), actual: %v",
-								directoryCreated,
-								accessPointOpts.DirectoryPath)
-						}
-					})
-
-				res, err := driver.CreateVolume(ctx, req)
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: rootDirNamePattern is not a valid regular expression",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
 
-				if err != nil {
-					t.Fatalf("CreateVolume failed: %v", err)
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
-				if res.Volume == nil {
-					t.Fatal("Volume is nil")
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode:   "efs-ap",
+						FsId:               fsId,
+						DirectoryPerms:     "777",
+						RootDirNamePattern: "pvc-[",
+					},
 				}
 
-				if res.Volume.VolumeId != volumeId {
-					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
+				ctx := context.Background()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
 				}
-
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Normal flow with a valid directory structure set, and a basePath",
+			name: "Success: File system has a mount target in expectedVpcId",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -961,14 +1142,10 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 					tags:         parseTagsFromStr(""),
 				}
 
-				pvcName := "foo"
-				basePath := "bash"
-				directoryCreated := fmt.Sprintf("/%s/%s", basePath, pvcName)
-
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
 					VolumeCapabilities: []*csi.VolumeCapability{
@@ -978,15 +1155,10 @@ func TestCreateVolume(t *testing.T) {
 						RequiredBytes: capacityRange,
 					},
 					Parameters: map[string]string{
-						ProvisioningMode:      "efs-ap",
-						FsId:                  fsId,
-						GidMin:                "1000",
-						GidMax:                "2000",
-						DirectoryPerms:        "777",
-						SubPathPattern:        "${.PVC.name}",
-						BasePath:              basePath,
-						EnsureUniqueDirectory: "true",
-						PvcName:               pvcName,
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						ExpectedVpcId:    "vpc-approved",
 					},
 				}
 
@@ -998,37 +1170,24 @@ func TestCreateVolume(t *testing.T) {
 					AccessPointId: apId,
 					FileSystemId:  fsId,
 				}
+				mountTargets := []*cloud.MountTarget{
+					{MountTargetId: "fsmt-1", VpcId: "vpc-other"},
+					{MountTargetId: "fsmt-2", VpcId: "vpc-approved"},
+				}
 				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListMountTargets(gomock.Eq(ctx), gomock.Eq(fsId)).Return(mountTargets, nil)
 				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
 
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
-					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
-						if !verifyPathWhenUUIDIncluded(accessPointOpts.DirectoryPath, directoryCreated) {
-							t.Fatalf("Root directory mismatch. Expected: %v (with UID appended), actual: %v",
-								directoryCreated,
-								accessPointOpts.DirectoryPath)
-						}
-					})
-
-				res, err := driver.CreateVolume(ctx, req)
-
+				_, err := driver.CreateVolume(ctx, req)
 				if err != nil {
 					t.Fatalf("CreateVolume failed: %v", err)
 				}
-
-				if res.Volume == nil {
-					t.Fatal("Volume is nil")
-				}
-
-				if res.Volume.VolumeId != volumeId {
-					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
-				}
-
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Normal flow with a valid directory structure set, and a basePath, and uniqueness guarantees turned off",
+			name: "Fail: File system has no mount target in expectedVpcId",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1036,14 +1195,10 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 					tags:         parseTagsFromStr(""),
 				}
 
-				pvcName := "foo"
-				basePath := "bash"
-				directoryCreated := fmt.Sprintf("/%s/%s", basePath, pvcName)
-
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
 					VolumeCapabilities: []*csi.VolumeCapability{
@@ -1053,15 +1208,10 @@ func TestCreateVolume(t *testing.T) {
 						RequiredBytes: capacityRange,
 					},
 					Parameters: map[string]string{
-						ProvisioningMode:      "efs-ap",
-						FsId:                  fsId,
-						GidMin:                "1000",
-						GidMax:                "2000",
-						DirectoryPerms:        "777",
-						SubPathPattern:        "${.PVC.name}",
-						BasePath:              basePath,
-						EnsureUniqueDirectory: "false",
-						PvcName:               pvcName,
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						ExpectedVpcId:    "vpc-missing",
 					},
 				}
 
@@ -1069,42 +1219,22 @@ func TestCreateVolume(t *testing.T) {
 				fileSystem := &cloud.FileSystem{
 					FileSystemId: fsId,
 				}
-				accessPoint := &cloud.AccessPoint{
-					AccessPointId: apId,
-					FileSystemId:  fsId,
+				mountTargets := []*cloud.MountTarget{
+					{MountTargetId: "fsmt-1", VpcId: "vpc-other"},
+					{MountTargetId: "fsmt-2", VpcId: "vpc-approved"},
 				}
 				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
-				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
-
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
-					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
-						if accessPointOpts.DirectoryPath != directoryCreated {
-							t.Fatalf("Root directory mismatch. Expected: %v, actual: %v",
-								directoryCreated,
-								accessPointOpts.DirectoryPath)
-						}
-					})
-
-				res, err := driver.CreateVolume(ctx, req)
+				mockCloud.EXPECT().ListMountTargets(gomock.Eq(ctx), gomock.Eq(fsId)).Return(mountTargets, nil)
 
-				if err != nil {
-					t.Fatalf("CreateVolume failed: %v", err)
-				}
-
-				if res.Volume == nil {
-					t.Fatal("Volume is nil")
-				}
-
-				if res.Volume.VolumeId != volumeId {
-					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.FailedPrecondition {
+					t.Fatalf("Expected FailedPrecondition, got: %v", err)
 				}
-
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Normal flow with a valid directory structure set, but ensuring uniqueness is set incorrectly, so default of true is used." +
-				"",
+			name: "Success: dataClass is recorded as a tag",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1112,13 +1242,10 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 					tags:         parseTagsFromStr(""),
 				}
 
-				pvcName := "foo"
-				directoryCreated := fmt.Sprintf("/%s", pvcName)
-
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
 					VolumeCapabilities: []*csi.VolumeCapability{
@@ -1128,14 +1255,10 @@ func TestCreateVolume(t *testing.T) {
 						RequiredBytes: capacityRange,
 					},
 					Parameters: map[string]string{
-						ProvisioningMode:      "efs-ap",
-						FsId:                  fsId,
-						GidMin:                "1000",
-						GidMax:                "2000",
-						DirectoryPerms:        "777",
-						SubPathPattern:        "${.PVC.name}",
-						EnsureUniqueDirectory: "banana",
-						PvcName:               pvcName,
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						DataClass:        "scratch",
 					},
 				}
 
@@ -1149,35 +1272,22 @@ func TestCreateVolume(t *testing.T) {
 				}
 				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
 				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
-
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
 					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
-						if !verifyPathWhenUUIDIncluded(accessPointOpts.DirectoryPath, directoryCreated) {
-							t.Fatalf("Root directory mismatch. Expected: %v (with UID appended), actual: %v",
-								directoryCreated,
-								accessPointOpts.DirectoryPath)
+						if accessPointOpts.Tags[DataClassTagKey] != "scratch" {
+							t.Fatalf("Data class tag mismatched. Expected: %v, actual: %v", "scratch", accessPointOpts.Tags[DataClassTagKey])
 						}
 					})
 
-				res, err := driver.CreateVolume(ctx, req)
-
+				_, err := driver.CreateVolume(ctx, req)
 				if err != nil {
 					t.Fatalf("CreateVolume failed: %v", err)
 				}
-
-				if res.Volume == nil {
-					t.Fatal("Volume is nil")
-				}
-
-				if res.Volume.VolumeId != volumeId {
-					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
-				}
-
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Normal flow with an empty subPath Pattern, no basePath and uniqueness guarantees turned off",
+			name: "Fail: dataClass is not one of the allowed values",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1185,7 +1295,7 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 					tags:         parseTagsFromStr(""),
 				}
 
@@ -1198,19 +1308,62 @@ func TestCreateVolume(t *testing.T) {
 						RequiredBytes: capacityRange,
 					},
 					Parameters: map[string]string{
-						ProvisioningMode:      "efs-ap",
-						FsId:                  fsId,
-						GidMin:                "1000",
-						GidMax:                "2000",
-						DirectoryPerms:        "777",
-						SubPathPattern:        "",
-						EnsureUniqueDirectory: "false",
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						DataClass:        "temporary",
+					},
+				}
+
+				ctx := context.Background()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Dropped tags are recorded as an event on the PVC",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				userTags := []string{}
+				for i := 0; i < MaxTagsPerResource-2; i++ {
+					userTags = append(userTags, fmt.Sprintf("key%02d:value", i))
+				}
+				recorder := record.NewFakeRecorder(1)
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(strings.Join(userTags, " ")),
+					recorder:     recorder,
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						PvcName:          "data",
+						PvcNamespace:     "tenant-a",
+						InheritFsTags:    "CostCenter",
 					},
 				}
 
 				ctx := context.Background()
 				fileSystem := &cloud.FileSystem{
 					FileSystemId: fsId,
+					Tags:         map[string]string{"CostCenter": "1234"},
 				}
 				accessPoint := &cloud.AccessPoint{
 					AccessPointId: apId,
@@ -1218,35 +1371,34 @@ func TestCreateVolume(t *testing.T) {
 				}
 				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
 				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
-
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
 					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
-						if accessPointOpts.DirectoryPath != "/" {
-							t.Fatalf("Root directory mismatch. Expected: %v, actual: %v",
-								"/",
-								accessPointOpts.DirectoryPath)
+						if accessPointOpts.Tags["CostCenter"] != "1234" || accessPointOpts.Tags[DefaultTagKey] != DefaultTagValue {
+							t.Fatalf("Expected the inherited and ownership tags to be kept, got tags: %v", accessPointOpts.Tags)
+						}
+						if _, ok := accessPointOpts.Tags[PvcNamespaceTagKey]; ok {
+							t.Fatalf("Expected the %v tag to be dropped, got tags: %v", PvcNamespaceTagKey, accessPointOpts.Tags)
 						}
 					})
 
-				res, err := driver.CreateVolume(ctx, req)
-
+				_, err := driver.CreateVolume(ctx, req)
 				if err != nil {
 					t.Fatalf("CreateVolume failed: %v", err)
 				}
 
-				if res.Volume == nil {
-					t.Fatal("Volume is nil")
-				}
-
-				if res.Volume.VolumeId != volumeId {
-					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
+				select {
+				case event := <-recorder.Events:
+					if !strings.HasPrefix(event, "Warning TagsDropped") || !strings.Contains(event, PvcNamespaceTagKey) {
+						t.Fatalf("Unexpected event: %v", event)
+					}
+				default:
+					t.Fatal("Expected a TagsDropped event")
 				}
-
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Normal flow with an empty subPath Pattern, and basePath set to /",
+			name: "Success: nameSanitization replaces unsafe characters in the access point directory",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1254,12 +1406,12 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 					tags:         parseTagsFromStr(""),
 				}
 
 				req := &csi.CreateVolumeRequest{
-					Name: volumeName,
+					Name: "pvc/1234",
 					VolumeCapabilities: []*csi.VolumeCapability{
 						stdVolCap,
 					},
@@ -1267,14 +1419,10 @@ func TestCreateVolume(t *testing.T) {
 						RequiredBytes: capacityRange,
 					},
 					Parameters: map[string]string{
-						ProvisioningMode:      "efs-ap",
-						FsId:                  fsId,
-						GidMin:                "1000",
-						GidMax:                "2000",
-						DirectoryPerms:        "777",
-						SubPathPattern:        "",
-						BasePath:              "/",
-						EnsureUniqueDirectory: "false",
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						NameSanitization: "replace",
 					},
 				}
 
@@ -1288,35 +1436,22 @@ func TestCreateVolume(t *testing.T) {
 				}
 				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
 				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
-
 				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
 					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
-						if accessPointOpts.DirectoryPath != "/" {
-							t.Fatalf("Root directory mismatch. Expected: %v, actual: %v",
-								"/",
-								accessPointOpts.DirectoryPath)
+						if accessPointOpts.DirectoryPath != "/pvc-1234" {
+							t.Fatalf("Root directory mismatched. Expected: %v, actual: %v", "/pvc-1234", accessPointOpts.DirectoryPath)
 						}
 					})
 
-				res, err := driver.CreateVolume(ctx, req)
-
+				_, err := driver.CreateVolume(ctx, req)
 				if err != nil {
 					t.Fatalf("CreateVolume failed: %v", err)
 				}
-
-				if res.Volume == nil {
-					t.Fatal("Volume is nil")
-				}
-
-				if res.Volume.VolumeId != volumeId {
-					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
-				}
-
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Normal flow with a valid directory structure set, using repeated elements (uses PVC Name in subpath pattern multiple times)",
+			name: "Success: Using Default GID ranges",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1324,13 +1459,9 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
-					tags:         parseTagsFromStr(""),
+					gidAllocator: NewGidAllocator(),
 				}
 
-				pvcName := "foo"
-				directoryCreated := fmt.Sprintf("/%s/%s", pvcName, pvcName)
-
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
 					VolumeCapabilities: []*csi.VolumeCapability{
@@ -1342,11 +1473,8 @@ func TestCreateVolume(t *testing.T) {
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
-						GidMin:           "1000",
-						GidMax:           "2000",
 						DirectoryPerms:   "777",
-						SubPathPattern:   "${.PVC.name}/${.PVC.name}",
-						PvcName:          pvcName,
+						BasePath:         "test",
 					},
 				}
 
@@ -1357,18 +1485,14 @@ func TestCreateVolume(t *testing.T) {
 				accessPoint := &cloud.AccessPoint{
 					AccessPointId: apId,
 					FileSystemId:  fsId,
+					PosixUser: &cloud.PosixUser{
+						Gid: DefaultGidMin - 1, //use GID that is not in default range
+					},
 				}
+				accessPoints := []*cloud.AccessPoint{accessPoint}
 				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
-				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
-
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
-					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
-						if !verifyPathWhenUUIDIncluded(accessPointOpts.DirectoryPath, directoryCreated) {
-							t.Fatalf("Root directory mismatch. Expected: %v (with UID appended), actual: %v",
-								directoryCreated,
-								accessPointOpts.DirectoryPath)
-						}
-					})
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(accessPoints, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
 
 				res, err := driver.CreateVolume(ctx, req)
 
@@ -1383,12 +1507,11 @@ func TestCreateVolume(t *testing.T) {
 				if res.Volume.VolumeId != volumeId {
 					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
 				}
-
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Volume name missing",
+			name: "Success: Retried CreateVolume reuses the Access Point of the earlier attempt",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1396,27 +1519,61 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
 				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
 						DirectoryPerms:   "777",
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/" + volumeName,
+					ClientToken:        volumeName,
+					PosixUser: &cloud.PosixUser{
+						Gid: 2000,
+						Uid: 2000,
+					},
+				}
+				accessPoints := []*cloud.AccessPoint{accessPoint}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(accessPoints, nil).Times(2)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, cloud.ErrAlreadyExists)
+
+				res, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
+				}
+				if res.Volume.VolumeContext[VolCtxGid] != "2000" {
+					t.Fatalf("Gid mismatched. Expected: %v, Actual: %v", "2000", res.Volume.VolumeContext[VolCtxGid])
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Capacity Range missing",
+			name: "Fail: Retried CreateVolume conflicts with the Access Point of the earlier attempt",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1424,28 +1581,58 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
 						DirectoryPerms:   "777",
+						Uid:              "1001",
+						Gid:              "1001",
 					},
 				}
 
 				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/" + volumeName,
+					ClientToken:        volumeName,
+					PosixUser: &cloud.PosixUser{
+						Gid: 1000,
+						Uid: 1000,
+					},
+				}
+				accessPoints := []*cloud.AccessPoint{accessPoint}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, cloud.ErrAlreadyExists)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(accessPoints, nil)
+
 				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
 					t.Fatal("CreateVolume did not fail")
 				}
+				if status.Code(err) != codes.AlreadyExists {
+					t.Fatalf("Expected AlreadyExists, got: %v", err)
+				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Volume capability Missing",
+			name: "Fail: Retry cannot look up the existing access point",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1453,11 +1640,15 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
 					},
@@ -1465,19 +1656,28 @@ func TestCreateVolume(t *testing.T) {
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
 						DirectoryPerms:   "777",
+						Uid:              "1001",
+						Gid:              "1001",
 					},
 				}
 
 				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, cloud.ErrAlreadyExists)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, cloud.ErrAccessDenied)
+
 				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				if status.Code(err) != codes.Unauthenticated {
+					t.Fatalf("Expected Unauthenticated, got: %v", err)
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Volume capability Not Supported",
+			name: "Success: Normal flow with tags",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1485,41 +1685,62 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr("cluster:efs"),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
 					},
-					VolumeCapabilities: []*csi.VolumeCapability{
-						{
-							AccessType: &csi.VolumeCapability_Mount{
-								Mount: &csi.VolumeCapability_MountVolume{},
-							},
-							AccessMode: &csi.VolumeCapability_AccessMode{
-								Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY,
-							},
-						},
-					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
 						DirectoryPerms:   "777",
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+					PosixUser: &cloud.PosixUser{
+						Gid: 1000,
+						Uid: 1000,
+					},
+				}
+				accessPoints := []*cloud.AccessPoint{accessPoint}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(accessPoints, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
+
+				res, err := driver.CreateVolume(ctx, req)
+
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+
+				if res.Volume == nil {
+					t.Fatal("Volume is nil")
+				}
+
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Volume fsType not supported",
+			name: "Success: Normal flow with tag value templates",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1527,23 +1748,14 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
-					tags:         parseTagsFromStr(""),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr("Name:{{.PVCName}}-{{.PVCNamespace}} cluster:efs"),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
 					VolumeCapabilities: []*csi.VolumeCapability{
-						{
-							AccessType: &csi.VolumeCapability_Mount{
-								Mount: &csi.VolumeCapability_MountVolume{
-									FsType: "abc",
-								},
-							},
-							AccessMode: &csi.VolumeCapability_AccessMode{
-								Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
-							},
-						},
+						stdVolCap,
 					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
@@ -1554,20 +1766,44 @@ func TestCreateVolume(t *testing.T) {
 						GidMin:           "1000",
 						GidMax:           "2000",
 						DirectoryPerms:   "777",
-						AzName:           "us-east-1a",
+						PvcName:          "my-pvc",
+						PvcNamespace:     "my-namespace",
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
-				}
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+					PosixUser: &cloud.PosixUser{
+						Gid: 1000,
+						Uid: 1000,
+					},
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).Do(
+					func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if accessPointOpts.Tags["Name"] != "my-pvc-my-namespace" {
+							t.Fatalf("Name tag mismatched. Expected: %v, actual: %v", "my-pvc-my-namespace", accessPointOpts.Tags["Name"])
+						}
+						if accessPointOpts.Tags["cluster"] != "efs" {
+							t.Fatalf("cluster tag mismatched. Expected: %v, actual: %v", "efs", accessPointOpts.Tags["cluster"])
+						}
+					})
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: AccessType is block",
+			name: "Fail: Tag value template references missing PVC metadata",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1575,41 +1811,41 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr("Name:{{.PVCName}}"),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
 					},
-					VolumeCapabilities: []*csi.VolumeCapability{
-						{
-							AccessType: &csi.VolumeCapability_Block{
-								Block: &csi.VolumeCapability_BlockVolume{},
-							},
-							AccessMode: &csi.VolumeCapability_AccessMode{
-								Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
-							},
-						},
-					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
 						DirectoryPerms:   "777",
 					},
 				}
 
 				ctx := context.Background()
+
 				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
 					t.Fatal("CreateVolume did not fail")
 				}
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Provisioning Mode Not Supported",
+			name: "Success: Normal flow with invalid tags",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1617,34 +1853,62 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr("cluster-efs"),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
 					VolumeCapabilities: []*csi.VolumeCapability{
 						stdVolCap,
 					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
 					Parameters: map[string]string{
-						ProvisioningMode: "efs-fs",
+						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
 						DirectoryPerms:   "777",
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+					PosixUser: &cloud.PosixUser{
+						Gid: 1000,
+						Uid: 1000,
+					},
+				}
+				accessPoints := []*cloud.AccessPoint{accessPoint}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(accessPoints, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
+
+				res, err := driver.CreateVolume(ctx, req)
+
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+
+				if res.Volume == nil {
+					t.Fatal("Volume is nil")
+				}
+
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Missing Provisioning Mode parameter",
+			name: "Success: reuseAccessPointName is true",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1652,33 +1916,68 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
+				pvcNameVal := "test-pvc"
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
 					VolumeCapabilities: []*csi.VolumeCapability{
 						stdVolCap,
 					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
 					Parameters: map[string]string{
-						FsId:           fsId,
-						DirectoryPerms: "777",
+						ProvisioningMode:    "efs-ap",
+						FsId:                fsId,
+						GidMin:              "1000",
+						GidMax:              "2000",
+						DirectoryPerms:      "777",
+						AzName:              "us-east-1a",
+						ReuseAccessPointKey: "true",
+						PvcNameKey:          pvcNameVal,
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+					PosixUser: &cloud.PosixUser{
+						Gid: 1000,
+						Uid: 1000,
+					},
+				}
+				accessPoints := []*cloud.AccessPoint{accessPoint}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(accessPoints, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(get64LenHash(pvcNameVal)), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
+
+				res, err := driver.CreateVolume(ctx, req)
+
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
 				}
+
+				if res.Volume == nil {
+					t.Fatal("Volume is nil")
+				}
+
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
+				}
+
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Missing Parameter FsId",
+			name: "Success: Normal flow with a valid directory structure set",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1686,33 +1985,73 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
+				pvName := "foo"
+				pvcName := "bar"
+				directoryCreated := fmt.Sprintf("/%s/%s", pvName, pvcName)
+
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
 					VolumeCapabilities: []*csi.VolumeCapability{
 						stdVolCap,
 					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
 						DirectoryPerms:   "777",
+						SubPathPattern:   "${.PV.name}/${.PVC.name}",
+						PvName:           pvName,
+						PvcName:          pvcName,
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPoint bool) {
+						if !verifyPathWhenUUIDIncluded(accessPointOpts.DirectoryPath, directoryCreated) {
+							t.Fatalf("Root directory mismatch. Expected: %v (with UID appended), actual: %v",
+								directoryCreated,
+								accessPointOpts.DirectoryPath)
+						}
+					})
+
+				res, err := driver.CreateVolume(ctx, req)
+
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+
+				if res.Volume == nil {
+					t.Fatal("Volume is nil")
+				}
+
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
 				}
+
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: FsId cannot be blank",
+			name: "Success: Normal flow with a valid directory structure set, using a single element",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1720,34 +2059,71 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
+				pvcName := "foo"
+				directoryCreated := fmt.Sprintf("/%s", pvcName)
+
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
 					VolumeCapabilities: []*csi.VolumeCapability{
 						stdVolCap,
 					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
-						FsId:             "     ",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
 						DirectoryPerms:   "777",
+						SubPathPattern:   "${.PVC.name}",
+						PvcName:          pvcName,
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if !verifyPathWhenUUIDIncluded(accessPointOpts.DirectoryPath, directoryCreated) {
+							t.Fatalf("Root directory mismatch. Expected: %v (with UID appended), actual: %v",
+								directoryCreated,
+								accessPointOpts.DirectoryPath)
+						}
+					})
+
+				res, err := driver.CreateVolume(ctx, req)
+
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+
+				if res.Volume == nil {
+					t.Fatal("Volume is nil")
+				}
+
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
 				}
+
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Uid invalid",
+			name: "Success: Normal flow with a valid directory structure set, and a basePath",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1755,71 +2131,74 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
+				pvcName := "foo"
+				basePath := "bash"
+				directoryCreated := fmt.Sprintf("/%s/%s", basePath, pvcName)
+
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
 					VolumeCapabilities: []*csi.VolumeCapability{
 						stdVolCap,
 					},
-					Parameters: map[string]string{
-						ProvisioningMode: "efs-ap",
-						FsId:             fsId,
-						DirectoryPerms:   "777",
-						Uid:              "invalid",
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode:      "efs-ap",
+						FsId:                  fsId,
+						GidMin:                "1000",
+						GidMax:                "2000",
+						DirectoryPerms:        "777",
+						SubPathPattern:        "${.PVC.name}",
+						BasePath:              basePath,
+						EnsureUniqueDirectory: "true",
+						PvcName:               pvcName,
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
 				}
-				mockCtl.Finish()
-			},
-		},
-		{
-			name: "Fail: Uid cannot be negative",
-			testFunc: func(t *testing.T) {
-				mockCtl := gomock.NewController(t)
-				mockCloud := mocks.NewMockCloud(mockCtl)
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
 
-				driver := &Driver{
-					endpoint:     endpoint,
-					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if !verifyPathWhenUUIDIncluded(accessPointOpts.DirectoryPath, directoryCreated) {
+							t.Fatalf("Root directory mismatch. Expected: %v (with UID appended), actual: %v",
+								directoryCreated,
+								accessPointOpts.DirectoryPath)
+						}
+					})
+
+				res, err := driver.CreateVolume(ctx, req)
+
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
 				}
 
-				req := &csi.CreateVolumeRequest{
-					Name: volumeName,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
-					},
-					Parameters: map[string]string{
-						ProvisioningMode: "efs-ap",
-						FsId:             fsId,
-						DirectoryPerms:   "777",
-						Uid:              "-5",
-					},
+				if res.Volume == nil {
+					t.Fatal("Volume is nil")
 				}
 
-				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
 				}
+
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Gid invalid",
+			name: "Success: Normal flow with a valid directory structure set, and a basePath, and uniqueness guarantees turned off",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1827,35 +2206,75 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
+				pvcName := "foo"
+				basePath := "bash"
+				directoryCreated := fmt.Sprintf("/%s/%s", basePath, pvcName)
+
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
 					VolumeCapabilities: []*csi.VolumeCapability{
 						stdVolCap,
 					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
 					Parameters: map[string]string{
-						ProvisioningMode: "efs-ap",
-						FsId:             fsId,
-						DirectoryPerms:   "777",
-						Gid:              "invalid",
+						ProvisioningMode:      "efs-ap",
+						FsId:                  fsId,
+						GidMin:                "1000",
+						GidMax:                "2000",
+						DirectoryPerms:        "777",
+						SubPathPattern:        "${.PVC.name}",
+						BasePath:              basePath,
+						EnsureUniqueDirectory: "false",
+						PvcName:               pvcName,
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if accessPointOpts.DirectoryPath != directoryCreated {
+							t.Fatalf("Root directory mismatch. Expected: %v, actual: %v",
+								directoryCreated,
+								accessPointOpts.DirectoryPath)
+						}
+					})
+
+				res, err := driver.CreateVolume(ctx, req)
+
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+
+				if res.Volume == nil {
+					t.Fatal("Volume is nil")
+				}
+
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
 				}
+
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Gid cannot be negative",
+			name: "Success: Normal flow with a valid directory structure set, but ensuring uniqueness is set incorrectly, so default of true is used." +
+				"",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1863,35 +2282,72 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
+				pvcName := "foo"
+				directoryCreated := fmt.Sprintf("/%s", pvcName)
+
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
 					VolumeCapabilities: []*csi.VolumeCapability{
 						stdVolCap,
 					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
 					Parameters: map[string]string{
-						ProvisioningMode: "efs-ap",
-						FsId:             fsId,
-						DirectoryPerms:   "777",
-						Gid:              "-5",
+						ProvisioningMode:      "efs-ap",
+						FsId:                  fsId,
+						GidMin:                "1000",
+						GidMax:                "2000",
+						DirectoryPerms:        "777",
+						SubPathPattern:        "${.PVC.name}",
+						EnsureUniqueDirectory: "banana",
+						PvcName:               pvcName,
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if !verifyPathWhenUUIDIncluded(accessPointOpts.DirectoryPath, directoryCreated) {
+							t.Fatalf("Root directory mismatch. Expected: %v (with UID appended), actual: %v",
+								directoryCreated,
+								accessPointOpts.DirectoryPath)
+						}
+					})
+
+				res, err := driver.CreateVolume(ctx, req)
+
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+
+				if res.Volume == nil {
+					t.Fatal("Volume is nil")
+				}
+
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
 				}
+
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Gid min cannot be 0",
+			name: "Success: Normal flow with an empty subPath Pattern, no basePath and uniqueness guarantees turned off",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1899,35 +2355,68 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
 					VolumeCapabilities: []*csi.VolumeCapability{
 						stdVolCap,
 					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
 					Parameters: map[string]string{
-						ProvisioningMode: "efs-ap",
-						FsId:             fsId,
-						DirectoryPerms:   "777",
-						GidMin:           "0",
+						ProvisioningMode:      "efs-ap",
+						FsId:                  fsId,
+						GidMin:                "1000",
+						GidMax:                "2000",
+						DirectoryPerms:        "777",
+						SubPathPattern:        "",
+						EnsureUniqueDirectory: "false",
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
 				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if accessPointOpts.DirectoryPath != "/" {
+							t.Fatalf("Root directory mismatch. Expected: %v, actual: %v",
+								"/",
+								accessPointOpts.DirectoryPath)
+						}
+					})
+
+				res, err := driver.CreateVolume(ctx, req)
+
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+
+				if res.Volume == nil {
+					t.Fatal("Volume is nil")
+				}
+
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
+				}
+
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: GidMin must be an integer",
+			name: "Success: Normal flow with an empty subPath Pattern, and basePath set to /",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1935,35 +2424,69 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
 					VolumeCapabilities: []*csi.VolumeCapability{
 						stdVolCap,
 					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
 					Parameters: map[string]string{
-						ProvisioningMode: "efs-ap",
-						FsId:             fsId,
-						DirectoryPerms:   "777",
-						GidMin:           "test",
+						ProvisioningMode:      "efs-ap",
+						FsId:                  fsId,
+						GidMin:                "1000",
+						GidMax:                "2000",
+						DirectoryPerms:        "777",
+						SubPathPattern:        "",
+						BasePath:              "/",
+						EnsureUniqueDirectory: "false",
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if accessPointOpts.DirectoryPath != "/" {
+							t.Fatalf("Root directory mismatch. Expected: %v, actual: %v",
+								"/",
+								accessPointOpts.DirectoryPath)
+						}
+					})
+
+				res, err := driver.CreateVolume(ctx, req)
+
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+
+				if res.Volume == nil {
+					t.Fatal("Volume is nil")
+				}
+
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
 				}
+
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: GidMax must be an integer",
+			name: "Success: Normal flow with a valid directory structure set, using repeated elements (uses PVC Name in subpath pattern multiple times)",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1971,36 +2494,71 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
+				pvcName := "foo"
+				directoryCreated := fmt.Sprintf("/%s/%s", pvcName, pvcName)
+
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
 					VolumeCapabilities: []*csi.VolumeCapability{
 						stdVolCap,
 					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
 						DirectoryPerms:   "777",
-						GidMin:           "2000",
-						GidMax:           "test",
+						SubPathPattern:   "${.PVC.name}/${.PVC.name}",
+						PvcName:          pvcName,
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if !verifyPathWhenUUIDIncluded(accessPointOpts.DirectoryPath, directoryCreated) {
+							t.Fatalf("Root directory mismatch. Expected: %v (with UID appended), actual: %v",
+								directoryCreated,
+								accessPointOpts.DirectoryPath)
+						}
+					})
+
+				res, err := driver.CreateVolume(ctx, req)
+
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+
+				if res.Volume == nil {
+					t.Fatal("Volume is nil")
+				}
+
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
 				}
+
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: GidMax must be greater than GidMin",
+			name: "Fail: Volume name missing",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2008,23 +2566,14 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
-					Name: volumeName,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
-					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
 						DirectoryPerms:   "777",
-						GidMin:           "2000",
-						GidMax:           "1000",
 					},
 				}
 
@@ -2037,7 +2586,7 @@ func TestCreateVolume(t *testing.T) {
 			},
 		},
 		{
-			name: "Fail: GidMax must be provided with GidMin",
+			name: "Fail: Capacity Range missing",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2045,22 +2594,15 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: capacityRange,
-					},
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
-					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
 						DirectoryPerms:   "777",
-						GidMin:           "2000",
 					},
 				}
 
@@ -2073,7 +2615,7 @@ func TestCreateVolume(t *testing.T) {
 			},
 		},
 		{
-			name: "Fail: GidMin must be provided with GidMax",
+			name: "Fail: Volume capability Missing",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2081,7 +2623,7 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
@@ -2089,14 +2631,10 @@ func TestCreateVolume(t *testing.T) {
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
 					},
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
-					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
 						DirectoryPerms:   "777",
-						GidMax:           "2000",
 					},
 				}
 
@@ -2109,7 +2647,7 @@ func TestCreateVolume(t *testing.T) {
 			},
 		},
 		{
-			name: "Fail: File system does not exist",
+			name: "Fail: Volume capability Not Supported",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2117,28 +2655,32 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
-					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
 					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						{
+							AccessType: &csi.VolumeCapability_Mount{
+								Mount: &csi.VolumeCapability_MountVolume{},
+							},
+							AccessMode: &csi.VolumeCapability_AccessMode{
+								Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY,
+							},
+						},
+					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
-						GidMin:           "1000",
-						GidMax:           "2000",
 						DirectoryPerms:   "777",
 					},
 				}
 
 				ctx := context.Background()
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, cloud.ErrNotFound)
 				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
 					t.Fatal("CreateVolume did not fail")
@@ -2147,7 +2689,7 @@ func TestCreateVolume(t *testing.T) {
 			},
 		},
 		{
-			name: "Fail: DescribeFileSystem Access Denied",
+			name: "Fail: Volume fsType not supported",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2155,13 +2697,23 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
 					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
+						{
+							AccessType: &csi.VolumeCapability_Mount{
+								Mount: &csi.VolumeCapability_MountVolume{
+									FsType: "abc",
+								},
+							},
+							AccessMode: &csi.VolumeCapability_AccessMode{
+								Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+							},
+						},
 					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
@@ -2172,11 +2724,11 @@ func TestCreateVolume(t *testing.T) {
 						GidMin:           "1000",
 						GidMax:           "2000",
 						DirectoryPerms:   "777",
+						AzName:           "us-east-1a",
 					},
 				}
 
 				ctx := context.Background()
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, cloud.ErrAccessDenied)
 				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
 					t.Fatal("CreateVolume did not fail")
@@ -2185,7 +2737,7 @@ func TestCreateVolume(t *testing.T) {
 			},
 		},
 		{
-			name: "Fail: Describe File system call fails",
+			name: "Fail: AccessType is block",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2193,28 +2745,32 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
-					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
 					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						{
+							AccessType: &csi.VolumeCapability_Block{
+								Block: &csi.VolumeCapability_BlockVolume{},
+							},
+							AccessMode: &csi.VolumeCapability_AccessMode{
+								Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+							},
+						},
+					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
-						GidMin:           "1000",
-						GidMax:           "2000",
 						DirectoryPerms:   "777",
 					},
 				}
 
 				ctx := context.Background()
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("DescribeFileSystem failed"))
 				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
 					t.Fatal("CreateVolume did not fail")
@@ -2223,7 +2779,7 @@ func TestCreateVolume(t *testing.T) {
 			},
 		},
 		{
-			name: "Fail: Create Access Point call fails",
+			name: "Fail: Provisioning Mode Not Supported",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2231,148 +2787,147 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
-					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
 					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
 					Parameters: map[string]string{
-						ProvisioningMode: "efs-ap",
+						ProvisioningMode: "efs-fs",
 						FsId:             fsId,
-						GidMin:           "1000",
-						GidMax:           "2000",
 						DirectoryPerms:   "777",
 					},
 				}
 
 				ctx := context.Background()
-				fileSystem := &cloud.FileSystem{
-					FileSystemId: fsId,
-				}
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
-				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return([]*cloud.AccessPoint{}, nil)
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("CreateAccessPoint call failed"))
 				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				if status.Code(err) != codes.InvalidArgument || !strings.Contains(err.Error(), AccessPointMode) {
+					t.Fatalf("Expected InvalidArgument listing the supported modes, got: %v", err)
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: CreateAccessPoint Access Denied",
+			name: "Fail: Explicit unsupported provisioning mode is not replaced by the default",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
 
 				driver := &Driver{
-					endpoint:     endpoint,
-					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					endpoint:                endpoint,
+					cloud:                   mockCloud,
+					gidAllocator:            NewGidAllocator(),
+					defaultProvisioningMode: AccessPointMode,
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
-					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
 					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
 					Parameters: map[string]string{
-						ProvisioningMode: "efs-ap",
+						ProvisioningMode: "efs-fs",
 						FsId:             fsId,
-						GidMin:           "1000",
-						GidMax:           "2000",
 						DirectoryPerms:   "777",
 					},
 				}
 
-				ctx := context.Background()
-				fileSystem := &cloud.FileSystem{
-					FileSystemId: fsId,
-				}
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
-				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return([]*cloud.AccessPoint{}, nil)
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, cloud.ErrAccessDenied)
-				_, err := driver.CreateVolume(ctx, req)
-				if err == nil {
-					t.Fatal("CreateVolume did not fail")
+				_, err := driver.CreateVolume(context.Background(), req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Run out of GIDs",
+			name: "Success: Default provisioning mode is used when the parameter is missing",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
 
 				driver := &Driver{
-					endpoint:     endpoint,
-					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					endpoint:                endpoint,
+					cloud:                   mockCloud,
+					gidAllocator:            NewGidAllocator(),
+					tags:                    parseTagsFromStr(""),
+					defaultProvisioningMode: AccessPointMode,
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
-					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
 					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
 					Parameters: map[string]string{
-						ProvisioningMode: "efs-ap",
-						FsId:             fsId,
-						GidMin:           "1000",
-						GidMax:           "1001",
-						DirectoryPerms:   "777",
+						FsId:           fsId,
+						DirectoryPerms: "777",
+						Uid:            "1000",
+						Gid:            "1000",
 					},
 				}
 
 				ctx := context.Background()
-				fileSystem := &cloud.FileSystem{
-					FileSystemId: fsId,
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(&cloud.FileSystem{FileSystemId: fsId}, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(&cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId}, nil)
+
+				res, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
 				}
-				ap1 := &cloud.AccessPoint{
-					AccessPointId: apId,
-					FileSystemId:  fsId,
-					PosixUser: &cloud.PosixUser{
-						Gid: 1000,
-						Uid: 1000,
-					},
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
 				}
-				ap2 := &cloud.AccessPoint{
-					AccessPointId: apId,
-					FileSystemId:  fsId,
-					PosixUser: &cloud.PosixUser{
-						Gid: 1001,
-						Uid: 1001,
-					},
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Missing Provisioning Mode parameter",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
 				}
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil).AnyTimes()
-				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return([]*cloud.AccessPoint{ap1, ap2}, nil).AnyTimes()
-				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(ap2, nil).AnyTimes()
 
-				var err error
-				// All GIDs from available range are taken, CreateVolume should fail.
-				_, err = driver.CreateVolume(ctx, req)
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						FsId:           fsId,
+						DirectoryPerms: "777",
+					},
+				}
 
-				if err == nil {
-					t.Fatalf("CreateVolume did not fail")
+				ctx := context.Background()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Cannot assume role for x-account",
+			name: "Fail: Missing Parameter FsId",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2380,211 +2935,140 @@ func TestCreateVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
-					tags:         parseTagsFromStr(""),
+					gidAllocator: NewGidAllocator(),
 				}
 
-				secrets := map[string]string{}
-				secrets["awsRoleArn"] = "arn:aws:iam::1234567890:role/EFSCrossAccountRole"
-
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
-					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
 					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
-						FsId:             fsId,
-						GidMin:           "1000",
-						GidMax:           "2000",
 						DirectoryPerms:   "777",
-						AzName:           "us-east-1a",
 					},
-					Secrets: secrets,
 				}
 
 				ctx := context.Background()
-
 				_, err := driver.CreateVolume(ctx, req)
-
 				if err == nil {
-					t.Fatalf("CreateVolume did not fail")
+					t.Fatal("CreateVolume did not fail")
 				}
-
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: subPathPattern is specified but uses unsupported attributes",
+			name: "Fail: FsId cannot be blank",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
 
-				subPathPattern := "${.PVC.name}/${foo}"
-
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
-					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
 					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
-						FsId:             fsId,
+						FsId:             "     ",
 						DirectoryPerms:   "777",
-						SubPathPattern:   subPathPattern,
 					},
 				}
 
 				ctx := context.Background()
-
-				fileSystem := &cloud.FileSystem{
-					FileSystemId: fsId,
-				}
-
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
-				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
-
 				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
 					t.Fatal("CreateVolume did not fail")
 				}
-				if status.Code(err) != codes.InvalidArgument {
-					t.Fatalf("Did not throw InvalidArgument error, instead threw %v", err)
-				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: resulting accessPointDirectory is too over 100 characters",
+			name: "Fail: Uid invalid",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
 
-				subPathPattern := "this-directory-name-is-far-too-long-for-any-practical-purposes-and-only-serves-to-prove-a-point"
-
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
-					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
 					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
 						DirectoryPerms:   "777",
-						SubPathPattern:   subPathPattern,
+						Uid:              "invalid",
 					},
 				}
 
 				ctx := context.Background()
-
-				fileSystem := &cloud.FileSystem{
-					FileSystemId: fsId,
-				}
-
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
-				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
-
 				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
 					t.Fatal("CreateVolume did not fail")
 				}
-				if status.Code(err) != codes.InvalidArgument {
-					t.Fatalf("Did not throw InvalidArgument error, instead threw %v", err)
-				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail:  resulting accessPointDirectory contains over 4 subdirectories",
+			name: "Fail: Uid cannot be negative",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
 
-				subPathPattern := "a/b/c/d/e/f"
-
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
 				req := &csi.CreateVolumeRequest{
 					Name: volumeName,
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
-					},
 					CapacityRange: &csi.CapacityRange{
 						RequiredBytes: capacityRange,
 					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
 					Parameters: map[string]string{
 						ProvisioningMode: "efs-ap",
 						FsId:             fsId,
 						DirectoryPerms:   "777",
-						SubPathPattern:   subPathPattern,
+						Uid:              "-5",
 					},
 				}
 
 				ctx := context.Background()
-
-				fileSystem := &cloud.FileSystem{
-					FileSystemId: fsId,
-				}
-
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
-				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
-
 				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
 					t.Fatal("CreateVolume did not fail")
 				}
-				if status.Code(err) != codes.InvalidArgument {
-					t.Fatalf("Did not throw InvalidArgument error, instead threw %v", err)
-				}
 				mockCtl.Finish()
 			},
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, tc.testFunc)
-	}
-}
-
-func TestDeleteVolume(t *testing.T) {
-	var (
-		apId     = "fsap-abcd1234xyz987"
-		fsId     = "fs-abcd1234"
-		endpoint = "endpoint"
-		volumeId = "fs-abcd1234::fsap-abcd1234xyz987"
-	)
-
-	testCases := []struct {
-		name     string
-		testFunc func(t *testing.T)
-	}{
 		{
-			name: "Success: Normal flow",
+			name: "Fail: Gid invalid",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2592,258 +3076,289 @@ func TestDeleteVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: volumeId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						Gid:              "invalid",
+					},
 				}
 
 				ctx := context.Background()
-				mockCloud.EXPECT().DeleteAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(nil)
-				_, err := driver.DeleteVolume(ctx, req)
-				if err != nil {
-					t.Fatalf("Delete Volume failed: %v", err)
+				_, err := driver.CreateVolume(ctx, req)
+				if err == nil {
+					t.Fatal("CreateVolume did not fail")
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Normal flow with deleteAccessPointRootDir",
+			name: "Fail: Gid cannot be negative",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
-				mockMounter := mocks.NewMockMounter(mockCtl)
 
 				driver := &Driver{
-					endpoint:                 endpoint,
-					cloud:                    mockCloud,
-					mounter:                  mockMounter,
-					gidAllocator:             NewGidAllocator(mockCloud),
-					deleteAccessPointRootDir: true,
-				}
-
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: volumeId,
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
 				}
 
-				accessPoint := &cloud.AccessPoint{
-					AccessPointId:      apId,
-					FileSystemId:       fsId,
-					AccessPointRootDir: "",
-					CapacityGiB:        0,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						Gid:              "-5",
+					},
 				}
 
 				ctx := context.Background()
-				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
-				mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-				mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
-				mockCloud.EXPECT().DescribeAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(accessPoint, nil)
-				mockCloud.EXPECT().DeleteAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(nil)
-				_, err := driver.DeleteVolume(ctx, req)
-				if err != nil {
-					t.Fatalf("Delete Volume failed: %v", err)
+				_, err := driver.CreateVolume(ctx, req)
+				if err == nil {
+					t.Fatal("CreateVolume did not fail")
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: DescribeAccessPoint Access Point Does not exist",
+			name: "Fail: Gid cannot be negative when Uid is set",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
-				mockMounter := mocks.NewMockMounter(mockCtl)
 
 				driver := &Driver{
-					endpoint:                 endpoint,
-					cloud:                    mockCloud,
-					mounter:                  mockMounter,
-					gidAllocator:             NewGidAllocator(mockCloud),
-					deleteAccessPointRootDir: true,
+					endpoint: endpoint,
+					cloud:    mockCloud,
 				}
 
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: volumeId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						Uid:              "1000",
+						Gid:              "-5",
+					},
 				}
 
 				ctx := context.Background()
-				mockCloud.EXPECT().DescribeAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(nil, cloud.ErrNotFound)
-				_, err := driver.DeleteVolume(ctx, req)
-				if err != nil {
-					t.Fatalf("Delete Volume failed: %v", err)
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: DescribeAccessPoint Access Denied",
+			name: "Fail: Gid min cannot be 0",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
-				mockMounter := mocks.NewMockMounter(mockCtl)
 
 				driver := &Driver{
-					endpoint:                 endpoint,
-					cloud:                    mockCloud,
-					mounter:                  mockMounter,
-					gidAllocator:             NewGidAllocator(mockCloud),
-					deleteAccessPointRootDir: true,
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
 				}
 
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: volumeId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						GidMin:           "0",
+					},
 				}
 
 				ctx := context.Background()
-				mockCloud.EXPECT().DescribeAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(nil, cloud.ErrAccessDenied)
-				_, err := driver.DeleteVolume(ctx, req)
+				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
-					t.Fatalf("DeleteVolume did not fail")
+					t.Fatal("CreateVolume did not fail")
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: DescribeAccessPoint failed",
+			name: "Fail: GidMin must be an integer",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
-				mockMounter := mocks.NewMockMounter(mockCtl)
 
 				driver := &Driver{
-					endpoint:                 endpoint,
-					cloud:                    mockCloud,
-					mounter:                  mockMounter,
-					gidAllocator:             NewGidAllocator(mockCloud),
-					deleteAccessPointRootDir: true,
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
 				}
 
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: volumeId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						GidMin:           "test",
+					},
 				}
 
 				ctx := context.Background()
-				mockCloud.EXPECT().DescribeAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(nil, errors.New("Describe Access Point failed"))
-				_, err := driver.DeleteVolume(ctx, req)
+				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
-					t.Fatalf("DeleteVolume did not fail")
+					t.Fatal("CreateVolume did not fail")
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Fail to make directory for access point mount",
+			name: "Fail: GidMax must be an integer",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
-				mockMounter := mocks.NewMockMounter(mockCtl)
 
 				driver := &Driver{
-					endpoint:                 endpoint,
-					cloud:                    mockCloud,
-					mounter:                  mockMounter,
-					gidAllocator:             NewGidAllocator(mockCloud),
-					deleteAccessPointRootDir: true,
-				}
-
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: volumeId,
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
 				}
 
-				accessPoint := &cloud.AccessPoint{
-					AccessPointId:      apId,
-					FileSystemId:       fsId,
-					AccessPointRootDir: "",
-					CapacityGiB:        0,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						GidMin:           "2000",
+						GidMax:           "test",
+					},
 				}
 
 				ctx := context.Background()
-				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(errors.New("Failed to makeDir"))
-				mockCloud.EXPECT().DescribeAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(accessPoint, nil)
-				_, err := driver.DeleteVolume(ctx, req)
+				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
-					t.Fatal("DeleteVolume did not fail")
+					t.Fatal("CreateVolume did not fail")
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Fail to mount file system on directory for access point root directory removal",
+			name: "Fail: GidMax must be greater than GidMin",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
-				mockMounter := mocks.NewMockMounter(mockCtl)
 
 				driver := &Driver{
-					endpoint:                 endpoint,
-					cloud:                    mockCloud,
-					mounter:                  mockMounter,
-					gidAllocator:             NewGidAllocator(mockCloud),
-					deleteAccessPointRootDir: true,
-				}
-
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: volumeId,
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
 				}
 
-				accessPoint := &cloud.AccessPoint{
-					AccessPointId:      apId,
-					FileSystemId:       fsId,
-					AccessPointRootDir: "",
-					CapacityGiB:        0,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						GidMin:           "2000",
+						GidMax:           "1000",
+					},
 				}
 
 				ctx := context.Background()
-				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
-				mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("Failed to mount"))
-				mockCloud.EXPECT().DescribeAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(accessPoint, nil)
-				_, err := driver.DeleteVolume(ctx, req)
+				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
-					t.Fatal("DeleteVolume did not fail")
+					t.Fatal("CreateVolume did not fail")
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Fail to unmount file system after access point root directory removal",
+			name: "Fail: GidMax must be provided with GidMin",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
-				mockMounter := mocks.NewMockMounter(mockCtl)
 
 				driver := &Driver{
-					endpoint:                 endpoint,
-					cloud:                    mockCloud,
-					mounter:                  mockMounter,
-					gidAllocator:             NewGidAllocator(mockCloud),
-					deleteAccessPointRootDir: true,
-				}
-
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: volumeId,
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
 				}
 
-				accessPoint := &cloud.AccessPoint{
-					AccessPointId:      apId,
-					FileSystemId:       fsId,
-					AccessPointRootDir: "",
-					CapacityGiB:        0,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						GidMin:           "2000",
+					},
 				}
 
 				ctx := context.Background()
-				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
-				mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-				mockMounter.EXPECT().Unmount(gomock.Any()).Return(errors.New("Failed to unmount"))
-				mockCloud.EXPECT().DescribeAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(accessPoint, nil)
-				_, err := driver.DeleteVolume(ctx, req)
+				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
-					t.Fatal("DeleteVolume did not fail")
+					t.Fatal("CreateVolume did not fail")
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Access Point already deleted",
+			name: "Fail: GidMin must be provided with GidMax",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2851,24 +3366,35 @@ func TestDeleteVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: volumeId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						GidMax:           "2000",
+					},
 				}
 
 				ctx := context.Background()
-				mockCloud.EXPECT().DeleteAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(cloud.ErrNotFound)
-				_, err := driver.DeleteVolume(ctx, req)
-				if err != nil {
-					t.Fatalf("Delete Volume failed: %v", err)
+				_, err := driver.CreateVolume(ctx, req)
+				if err == nil {
+					t.Fatal("CreateVolume did not fail")
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: DeleteAccessPoint access denied",
+			name: "Fail: File system does not exist",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2876,24 +3402,41 @@ func TestDeleteVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: volumeId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+						DirectoryPerms:   "777",
+					},
 				}
 
 				ctx := context.Background()
-				mockCloud.EXPECT().DeleteAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(cloud.ErrAccessDenied)
-				_, err := driver.DeleteVolume(ctx, req)
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().GetMetadata().Return(&testMetadata{region: "us-west-2"})
+				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
-					t.Fatal("DeleteVolume did not fail")
+					t.Fatal("CreateVolume did not fail")
+				}
+				if status.Code(err) != codes.InvalidArgument || !strings.Contains(err.Error(), "does not exist in region us-west-2") {
+					t.Fatalf("Expected InvalidArgument naming the region, got: %v", err)
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: DeleteVolume fails",
+			name: "Fail: File system does not exist in the overridden region",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2901,24 +3444,37 @@ func TestDeleteVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
+					endpointOpts: cloud.EndpointOptions{Region: "eu-west-1"},
 				}
 
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: volumeId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+					},
 				}
 
 				ctx := context.Background()
-				mockCloud.EXPECT().DeleteAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(errors.New("Delete Volume failed"))
-				_, err := driver.DeleteVolume(ctx, req)
-				if err == nil {
-					t.Fatal("DeleteVolume did not fail")
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().GetMetadata().Return(&testMetadata{region: "us-west-2"}).AnyTimes()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument || !strings.Contains(err.Error(), "does not exist in region eu-west-1") {
+					t.Fatalf("Expected InvalidArgument naming the overridden region, got: %v", err)
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Access Point is missing in volume Id",
+			name: "Fail: DescribeFileSystem Access Denied",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2926,23 +3482,37 @@ func TestDeleteVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
+					gidAllocator: NewGidAllocator(),
 				}
 
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: "fs-abcd1234",
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+						DirectoryPerms:   "777",
+					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.DeleteVolume(ctx, req)
-				if err == nil {
-					t.Fatal("DeleteVolume did not fail")
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, cloud.ErrAccessDenied)
+				_, err := driver.CreateVolume(ctx, req)
+				if err == nil {
+					t.Fatal("CreateVolume did not fail")
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Cannot assume role for x-account",
+			name: "Fail: Describe File system call fails",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -2950,190 +3520,5072 @@ func TestDeleteVolume(t *testing.T) {
 				driver := &Driver{
 					endpoint:     endpoint,
 					cloud:        mockCloud,
-					gidAllocator: NewGidAllocator(mockCloud),
-					tags:         parseTagsFromStr(""),
+					gidAllocator: NewGidAllocator(),
 				}
 
-				secrets := map[string]string{}
-				secrets["awsRoleArn"] = "arn:aws:iam::1234567890:role/EFSCrossAccountRole"
-
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: volumeId,
-					Secrets:  secrets,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+						DirectoryPerms:   "777",
+					},
 				}
 
 				ctx := context.Background()
-
-				_, err := driver.DeleteVolume(ctx, req)
-
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("DescribeFileSystem failed"))
+				_, err := driver.CreateVolume(ctx, req)
 				if err == nil {
-					t.Fatalf("DeleteVolume did not fail")
+					t.Fatal("CreateVolume did not fail")
 				}
-
 				mockCtl.Finish()
 			},
 		},
-	}
+		{
+			name: "Fail: Create Access Point call fails",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
 
-	for _, tc := range testCases {
-		t.Run(tc.name, tc.testFunc)
-	}
-}
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
 
-func TestValidateVolumeCapabilities(t *testing.T) {
-	var (
-		endpoint       = "endpoint"
-		volumeId       = "fs-abcd1234::fsap-abcd1234xyz987"
-		stdVolCapValid = &csi.VolumeCapability{
-			AccessType: &csi.VolumeCapability_Mount{
-				Mount: &csi.VolumeCapability_MountVolume{},
-			},
-			AccessMode: &csi.VolumeCapability_AccessMode{
-				Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
-			},
-		}
-		stdVolCapInvalid = &csi.VolumeCapability{
-			AccessType: &csi.VolumeCapability_Mount{
-				Mount: &csi.VolumeCapability_MountVolume{},
-			},
-			AccessMode: &csi.VolumeCapability_AccessMode{
-				Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+						DirectoryPerms:   "777",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return([]*cloud.AccessPoint{}, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("CreateAccessPoint call failed"))
+				_, err := driver.CreateVolume(ctx, req)
+				if err == nil {
+					t.Fatal("CreateVolume did not fail")
+				}
+				mockCtl.Finish()
 			},
-		}
-	)
-	testCases := []struct {
-		name     string
-		testFunc func(t *testing.T)
-	}{
+		},
 		{
-			name: "Success",
+			name: "Fail: CreateAccessPoint Access Denied",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
 
 				driver := &Driver{
-					endpoint: endpoint,
-					cloud:    mockCloud,
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
 				}
 
-				req := &csi.ValidateVolumeCapabilitiesRequest{
-					VolumeId: volumeId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
 					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCapValid,
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+						DirectoryPerms:   "777",
 					},
 				}
 
 				ctx := context.Background()
-				res, err := driver.ValidateVolumeCapabilities(ctx, req)
-				if err != nil {
-					t.Fatalf("ValidateVolumeCapabilities failed: %v", err)
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
 				}
-
-				if res.Confirmed == nil {
-					t.Fatalf("Capability is not supported")
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return([]*cloud.AccessPoint{}, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, cloud.ErrAccessDenied)
+				_, err := driver.CreateVolume(ctx, req)
+				if err == nil {
+					t.Fatal("CreateVolume did not fail")
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Success: Unsupported volume capability",
+			name: "Fail: CreateAccessPoint File System is being modified",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
 
 				driver := &Driver{
-					endpoint: endpoint,
-					cloud:    mockCloud,
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
 				}
 
-				req := &csi.ValidateVolumeCapabilitiesRequest{
-					VolumeId: volumeId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
 					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCapInvalid,
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+						DirectoryPerms:   "777",
 					},
 				}
 
 				ctx := context.Background()
-				res, err := driver.ValidateVolumeCapabilities(ctx, req)
-				if err != nil {
-					t.Fatalf("ValidateVolumeCapabilities failed: %v", err)
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
 				}
-
-				if res.Confirmed != nil {
-					t.Fatal("ValidateVolumeCapabilities did not fail")
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return([]*cloud.AccessPoint{}, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, cloud.ErrIncorrectLifeCycleState)
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.Unavailable {
+					t.Fatalf("Expected Unavailable, got: %v", err)
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Volume Id is missing",
+			name: "Success: CreateAccessPoint is retried while throttled",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
 
 				driver := &Driver{
-					endpoint: endpoint,
-					cloud:    mockCloud,
+					endpoint:           endpoint,
+					cloud:              mockCloud,
+					gidAllocator:       NewGidAllocator(),
+					throttleRetries:    3,
+					throttleRetryDelay: time.Millisecond,
 				}
 
-				req := &csi.ValidateVolumeCapabilitiesRequest{
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
 					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCapValid,
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+						DirectoryPerms:   "777",
 					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.ValidateVolumeCapabilities(ctx, req)
-				if err == nil {
-					t.Fatal("ValidateVolumeCapabilities did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return([]*cloud.AccessPoint{}, nil)
+				gomock.InOrder(
+					mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, cloud.ErrThrottled).Times(3),
+					mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil),
+				)
+				res, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
 				}
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "Fail: Volume Capabilities is missing",
+			name: "Fail: CreateAccessPoint is throttled after all retries",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
 
 				driver := &Driver{
-					endpoint: endpoint,
-					cloud:    mockCloud,
+					endpoint:           endpoint,
+					cloud:              mockCloud,
+					gidAllocator:       NewGidAllocator(),
+					throttleRetries:    2,
+					throttleRetryDelay: time.Millisecond,
 				}
 
-				req := &csi.ValidateVolumeCapabilitiesRequest{
-					VolumeId: volumeId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+						DirectoryPerms:   "777",
+					},
 				}
 
 				ctx := context.Background()
-				_, err := driver.ValidateVolumeCapabilities(ctx, req)
-				if err == nil {
-					t.Fatal("ValidateVolumeCapabilities did not fail")
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return([]*cloud.AccessPoint{}, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, cloud.ErrThrottled).Times(3)
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.Unavailable {
+					t.Fatalf("Expected Unavailable, got: %v", err)
 				}
 				mockCtl.Finish()
 			},
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, tc.testFunc)
-	}
-}
+		{
+			name: "Fail: Access point tags do not comply with the tag policy",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
 
-func TestControllerGetCapabilities(t *testing.T) {
-	var endpoint = "endpoint"
-	mockCtl := gomock.NewController(t)
-	mockCloud := mocks.NewMockCloud(mockCtl)
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr("CostCenter:secret-value"),
+				}
 
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return([]*cloud.AccessPoint{}, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, cloud.ErrTagPolicyViolation).Times(1)
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				message := status.Convert(err).Message()
+				if !strings.Contains(message, "tag policy") || !strings.Contains(message, "CostCenter") {
+					t.Fatalf("Expected the error to name the tag policy and tag keys, got: %v", message)
+				}
+				if strings.Contains(message, "secret-value") {
+					t.Fatalf("Expected the error to leave out tag values, got: %v", message)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Tag access point with allocated GID",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+						TagAllocatedGid:  "true",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if accessPointOpts.Gid != 2000 {
+							t.Fatalf("Gid mismatched. Expected: %v, actual: %v", 2000, accessPointOpts.Gid)
+						}
+						if value := accessPointOpts.Tags[AllocatedGidTagKey]; value != "2000" {
+							t.Fatalf("%v tag mismatched. Expected: %v, actual: %v", AllocatedGidTagKey, "2000", value)
+						}
+					})
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Allocated GID is not tagged by default",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if value, ok := accessPointOpts.Tags[AllocatedGidTagKey]; ok {
+							t.Fatalf("Unexpected %v tag %v", AllocatedGidTagKey, value)
+						}
+					})
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Invalid tagAllocatedGid",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint: endpoint,
+					cloud:    mockCloud,
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						TagAllocatedGid:  "sometimes",
+					},
+				}
+
+				ctx := context.Background()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Dry run validates parameters without calling AWS",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr("cluster:efs"),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						BasePath:         "test",
+						DryRun:           "true",
+					},
+				}
+
+				ctx := context.Background()
+				res, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				if res.Volume.VolumeId != DryRunVolumePrefix+volumeName {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", DryRunVolumePrefix+volumeName, res.Volume.VolumeId)
+				}
+				if rootDir := res.Volume.VolumeContext[VolCtxRootDir]; rootDir != "/test/"+volumeName {
+					t.Fatalf("Root directory mismatched. Expected: %v, Actual: %v", "/test/"+volumeName, rootDir)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Dry run reports invalid parameters",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						BasePath:         "../test",
+						DryRun:           "true",
+					},
+				}
+
+				ctx := context.Background()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Tag access point with the storage class of the PVC",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				storageClass := "efs-sc"
+				pvc := &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "tenant-a"},
+					Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+				}
+				driver := &Driver{
+					endpoint:        endpoint,
+					cloud:           mockCloud,
+					gidAllocator:    NewGidAllocator(),
+					tags:            parseTagsFromStr(""),
+					tagStorageClass: true,
+					kubeClient:      fake.NewSimpleClientset(pvc),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+						PvcName:          "data",
+						PvcNamespace:     "tenant-a",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if value := accessPointOpts.Tags[StorageClassTagKey]; value != storageClass {
+							t.Fatalf("%v tag mismatched. Expected: %v, actual: %v", StorageClassTagKey, storageClass, value)
+						}
+					})
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Storage class tag is left out when the PVC cannot be found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				storageClass := "efs-sc"
+				pvc := &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "tenant-a"},
+					Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+				}
+				driver := &Driver{
+					endpoint:        endpoint,
+					cloud:           mockCloud,
+					gidAllocator:    NewGidAllocator(),
+					tags:            parseTagsFromStr(""),
+					tagStorageClass: true,
+					kubeClient:      fake.NewSimpleClientset(pvc),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+						PvcName:          "data",
+						PvcNamespace:     "tenant-a",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if value, ok := accessPointOpts.Tags[StorageClassTagKey]; ok {
+							t.Fatalf("Unexpected %v tag %v", StorageClassTagKey, value)
+						}
+					})
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: requireEncryption allows an encrypted file system",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode:  "efs-ap",
+						FsId:              fsId,
+						Uid:               "1000",
+						Gid:               "1000",
+						RequireEncryption: "true",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+					Encrypted:    true,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: requireEncryption rejects an unencrypted file system",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode:  "efs-ap",
+						FsId:              fsId,
+						RequireEncryption: "true",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+					Encrypted:    false,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.FailedPrecondition {
+					t.Fatalf("Expected FailedPrecondition, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Invalid requireEncryption",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode:  "efs-ap",
+						FsId:              fsId,
+						RequireEncryption: "maybe",
+					},
+				}
+
+				ctx := context.Background()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Single secondary gid",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						Uid:              "1000",
+						Gid:              "1000",
+						SecondaryGids:    "2000",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						expected := []int64{2000}
+						if !reflect.DeepEqual(accessPointOpts.SecondaryGids, expected) {
+							t.Fatalf("SecondaryGids mismatched. Expected: %v, actual: %v", expected, accessPointOpts.SecondaryGids)
+						}
+					})
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Multiple secondary gids",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						Uid:              "1000",
+						Gid:              "1000",
+						SecondaryGids:    "2000, 2001,2002",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						expected := []int64{2000, 2001, 2002}
+						if !reflect.DeepEqual(accessPointOpts.SecondaryGids, expected) {
+							t.Fatalf("SecondaryGids mismatched. Expected: %v, actual: %v", expected, accessPointOpts.SecondaryGids)
+						}
+					})
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Invalid secondaryGids",
+			testFunc: func(t *testing.T) {
+				for _, value := range []string{"abc", "2000,", "-1", "4294967296", "0,1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16"} {
+					mockCtl := gomock.NewController(t)
+					mockCloud := mocks.NewMockCloud(mockCtl)
+
+					driver := &Driver{
+						endpoint:     endpoint,
+						cloud:        mockCloud,
+						gidAllocator: NewGidAllocator(),
+						tags:         parseTagsFromStr(""),
+					}
+
+					req := &csi.CreateVolumeRequest{
+						Name: volumeName,
+						VolumeCapabilities: []*csi.VolumeCapability{
+							stdVolCap,
+						},
+						CapacityRange: &csi.CapacityRange{
+							RequiredBytes: capacityRange,
+						},
+						Parameters: map[string]string{
+							ProvisioningMode: "efs-ap",
+							FsId:             fsId,
+							SecondaryGids:    value,
+						},
+					}
+
+					ctx := context.Background()
+					_, err := driver.CreateVolume(ctx, req)
+					if status.Code(err) != codes.InvalidArgument {
+						t.Fatalf("Expected InvalidArgument for %v %q, got: %v", SecondaryGids, value, err)
+					}
+					mockCtl.Finish()
+				}
+			},
+		},
+		{
+			name: "Success: skipFsDescribeOnAccessDenied creates the access point when describing the file system is denied",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode:     "efs-ap",
+						FsId:                 fsId,
+						Uid:                  "1000",
+						Gid:                  "1000",
+						SkipFsDescribeDenied: "true",
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, cloud.ErrAccessDenied)
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: skipFsDescribeOnAccessDenied does not skip the encryption check",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode:     "efs-ap",
+						FsId:                 fsId,
+						SkipFsDescribeDenied: "true",
+						RequireEncryption:    "true",
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, cloud.ErrAccessDenied)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.Unauthenticated {
+					t.Fatalf("Expected Unauthenticated, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: File system is below the access point limit",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:         endpoint,
+					cloud:            mockCloud,
+					gidAllocator:     NewGidAllocator(),
+					tags:             parseTagsFromStr(""),
+					accessPointLimit: 3,
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						Uid:              "1000",
+						Gid:              "1000",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				var existing []*cloud.AccessPoint
+				for i := 0; i < 2; i++ {
+					existing = append(existing, &cloud.AccessPoint{AccessPointId: fmt.Sprintf("fsap-%d", i), FileSystemId: fsId})
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(existing, nil)
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: File system has reached the access point limit",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:         endpoint,
+					cloud:            mockCloud,
+					gidAllocator:     NewGidAllocator(),
+					tags:             parseTagsFromStr(""),
+					accessPointLimit: 3,
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						Uid:              "1000",
+						Gid:              "1000",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				var existing []*cloud.AccessPoint
+				for i := 0; i < 3; i++ {
+					existing = append(existing, &cloud.AccessPoint{AccessPointId: fmt.Sprintf("fsap-%d", i), FileSystemId: fsId})
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(existing, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.ResourceExhausted {
+					t.Fatalf("Expected ResourceExhausted, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Access point limit is skipped when access points cannot be listed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:         endpoint,
+					cloud:            mockCloud,
+					gidAllocator:     NewGidAllocator(),
+					tags:             parseTagsFromStr(""),
+					accessPointLimit: 3,
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						Uid:              "1000",
+						Gid:              "1000",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(nil, cloud.ErrAccessDenied)
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Directory perms are in the allowed set",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:              endpoint,
+					cloud:                 mockCloud,
+					gidAllocator:          NewGidAllocator(),
+					tags:                  parseTagsFromStr(""),
+					allowedDirectoryPerms: map[string]bool{"700": true, "750": true},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						Uid:              "1000",
+						Gid:              "1000",
+						DirectoryPerms:   "0750",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if accessPointOpts.DirectoryPerms != "750" {
+							t.Fatalf("DirectoryPerms mismatched. Expected: 750, actual: %v", accessPointOpts.DirectoryPerms)
+						}
+					})
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Directory perms are not in the allowed set",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:              endpoint,
+					cloud:                 mockCloud,
+					gidAllocator:          NewGidAllocator(),
+					tags:                  parseTagsFromStr(""),
+					allowedDirectoryPerms: map[string]bool{"700": true, "750": true},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						Uid:              "1000",
+						Gid:              "1000",
+						DirectoryPerms:   "755",
+					},
+				}
+
+				ctx := context.Background()
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Root directory is owned by the POSIX user by default",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						Uid:              "1000",
+						Gid:              "1001",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if accessPointOpts.Uid != 1000 || accessPointOpts.Gid != 1001 {
+							t.Fatalf("POSIX user mismatched. Expected: 1000:1001, actual: %v:%v", accessPointOpts.Uid, accessPointOpts.Gid)
+						}
+						if accessPointOpts.OwnerUid != 1000 || accessPointOpts.OwnerGid != 1001 {
+							t.Fatalf("Root directory owner mismatched. Expected: 1000:1001, actual: %v:%v", accessPointOpts.OwnerUid, accessPointOpts.OwnerGid)
+						}
+					})
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Root directory owner differs from the POSIX user",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						Uid:              "1000",
+						Gid:              "1001",
+						OwnerUid:         "0",
+						OwnerGid:         "2000",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if accessPointOpts.Uid != 1000 || accessPointOpts.Gid != 1001 {
+							t.Fatalf("POSIX user mismatched. Expected: 1000:1001, actual: %v:%v", accessPointOpts.Uid, accessPointOpts.Gid)
+						}
+						if accessPointOpts.OwnerUid != 0 || accessPointOpts.OwnerGid != 2000 {
+							t.Fatalf("Root directory owner mismatched. Expected: 0:2000, actual: %v:%v", accessPointOpts.OwnerUid, accessPointOpts.OwnerGid)
+						}
+					})
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Invalid ownerGid",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						OwnerGid:         "-5",
+					},
+				}
+
+				ctx := context.Background()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: File system is being deleted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId:   fsId,
+					LifeCycleState: efs.LifeCycleStateDeleting,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.FailedPrecondition {
+					t.Fatalf("Expected FailedPrecondition, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: File system referenced by its ARN",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FileSystemArn:    "arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/" + fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+						DirectoryPerms:   "777",
+					},
+				}
+
+				ctx := context.Background()
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().GetMetadata().Return(&testMetadata{region: "us-east-1"})
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fsId)).Return(&cloud.FileSystem{FileSystemId: fsId}, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(nil, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
+
+				res, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				if res.Volume.VolumeId != volumeId {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", volumeId, res.Volume.VolumeId)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: File system ARN in another region is reached through a client for its region",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				regionalCloud := mocks.NewMockCloud(mockCtl)
+
+				var regions []string
+				roleClouds := newCloudCache(time.Minute, cloud.EndpointOptions{}, nil)
+				roleClouds.newCloud = func(roleArn, tokenFile string, sessionDuration time.Duration, region string) (cloud.Cloud, error) {
+					regions = append(regions, region)
+					return regionalCloud, nil
+				}
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+					roleClouds:   roleClouds,
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FileSystemArn:    "arn:aws:elasticfilesystem:eu-west-1:123456789012:file-system/" + fsId,
+						DirectoryPerms:   "777",
+					},
+				}
+
+				ctx := context.Background()
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				mockCloud.EXPECT().GetMetadata().Return(&testMetadata{region: "us-east-1"})
+				regionalCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fsId)).Return(&cloud.FileSystem{FileSystemId: fsId}, nil)
+				regionalCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(nil, nil).AnyTimes()
+				regionalCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
+
+				res, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				if expected := "v1:accesspoint:eu-west-1/" + fsId + ":" + apId; res.Volume.VolumeId != expected {
+					t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", expected, res.Volume.VolumeId)
+				}
+				if !reflect.DeepEqual(regions, []string{"eu-west-1"}) {
+					t.Fatalf("Expected a client for eu-west-1, got clients for %v", regions)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Both file system ID and ARN",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						FileSystemArn:    "arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/" + fsId,
+						DirectoryPerms:   "777",
+					},
+				}
+
+				_, err := driver.CreateVolume(context.Background(), req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: uniquePath gives same-named PVCs in different namespaces distinct directories",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(&cloud.FileSystem{FileSystemId: fsId}, nil).Times(2)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil).Times(2)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, clientToken string, opts *cloud.AccessPointOptions, reuse bool) (*cloud.AccessPoint, error) {
+						return &cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId, AccessPointRootDir: opts.DirectoryPath}, nil
+					}).Times(2)
+
+				rootDirs := map[string]bool{}
+				for _, namespace := range []string{"team-a", "team-b"} {
+					req := &csi.CreateVolumeRequest{
+						Name: volumeName + "-" + namespace,
+						VolumeCapabilities: []*csi.VolumeCapability{
+							stdVolCap,
+						},
+						CapacityRange: &csi.CapacityRange{
+							RequiredBytes: capacityRange,
+						},
+						Parameters: map[string]string{
+							ProvisioningMode:      "efs-ap",
+							FsId:                  fsId,
+							DirectoryPerms:        "777",
+							BasePath:              "shared",
+							SubPathPattern:        "${.PVC.name}",
+							EnsureUniqueDirectory: "false",
+							UniquePath:            "true",
+							PvcName:               "data",
+							PvcNamespace:          namespace,
+						},
+					}
+
+					res, err := driver.CreateVolume(ctx, req)
+					if err != nil {
+						t.Fatalf("CreateVolume failed: %v", err)
+					}
+					rootDir := res.Volume.VolumeContext[VolCtxRootDir]
+					if expected := "/shared/data-" + get64LenHash(namespace + "/data")[:UniquePathHashLength]; rootDir != expected {
+						t.Fatalf("Root directory mismatched. Expected: %v, Actual: %v", expected, rootDir)
+					}
+					rootDirs[rootDir] = true
+				}
+				if len(rootDirs) != 2 {
+					t.Fatalf("Expected distinct root directories, got: %v", rootDirs)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Access point records the requested availability zone",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						Uid:              "1000",
+						Gid:              "1000",
+						AzName:           "us-east-1b",
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(&cloud.FileSystem{FileSystemId: fsId}, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, clientToken string, opts *cloud.AccessPointOptions, reuse bool) (*cloud.AccessPoint, error) {
+						if opts.Tags[AzNameTagKey] != "us-east-1b" {
+							t.Fatalf("Expected the access point to be tagged with %v=us-east-1b, got: %v", AzNameTagKey, opts.Tags)
+						}
+						return &cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId}, nil
+					})
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Run out of GIDs",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "1001",
+						DirectoryPerms:   "777",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				ap1 := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+					PosixUser: &cloud.PosixUser{
+						Gid: 1000,
+						Uid: 1000,
+					},
+				}
+				ap2 := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+					PosixUser: &cloud.PosixUser{
+						Gid: 1001,
+						Uid: 1001,
+					},
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil).AnyTimes()
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return([]*cloud.AccessPoint{ap1, ap2}, nil).AnyTimes()
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(ap2, nil).AnyTimes()
+
+				var err error
+				// All GIDs from available range are taken, CreateVolume should fail.
+				_, err = driver.CreateVolume(ctx, req)
+
+				if err == nil {
+					t.Fatalf("CreateVolume did not fail")
+				}
+				if status.Code(err) != codes.ResourceExhausted {
+					t.Fatalf("Expected ResourceExhausted, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Namespace is under its access point quota",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode:   "efs-ap",
+						FsId:               fsId,
+						DirectoryPerms:     "777",
+						Uid:                "1000",
+						Gid:                "1000",
+						PvcNamespace:       "tenant-a",
+						MaxApsPerNamespace: "2",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId: apId,
+					FileSystemId:  fsId,
+				}
+				accessPoints := []*cloud.AccessPoint{
+					{AccessPointId: "fsap-1", Tags: map[string]string{DefaultTagKey: DefaultTagValue, PvcNamespaceTagKey: "tenant-a"}},
+					{AccessPointId: "fsap-2", Tags: map[string]string{DefaultTagKey: DefaultTagValue, PvcNamespaceTagKey: "tenant-b"}},
+					{AccessPointId: "fsap-3", Tags: map[string]string{PvcNamespaceTagKey: "tenant-a"}},
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(accessPoints, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil).
+					Do(func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPointName bool) {
+						if accessPointOpts.Tags[PvcNamespaceTagKey] != "tenant-a" {
+							t.Fatalf("Namespace tag mismatched. Expected: %v, actual: %v", "tenant-a", accessPointOpts.Tags[PvcNamespaceTagKey])
+						}
+					})
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Namespace has reached its access point quota",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode:   "efs-ap",
+						FsId:               fsId,
+						DirectoryPerms:     "777",
+						Uid:                "1000",
+						Gid:                "1000",
+						PvcNamespace:       "tenant-a",
+						MaxApsPerNamespace: "2",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				accessPoints := []*cloud.AccessPoint{
+					{AccessPointId: "fsap-1", Tags: map[string]string{DefaultTagKey: DefaultTagValue, PvcNamespaceTagKey: "tenant-a"}},
+					{AccessPointId: "fsap-2", Tags: map[string]string{DefaultTagKey: DefaultTagValue, PvcNamespaceTagKey: "tenant-a"}},
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(accessPoints, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err == nil {
+					t.Fatalf("CreateVolume did not fail")
+				}
+				if status.Code(err) != codes.ResourceExhausted {
+					t.Fatalf("Expected error code %v, got: %v", codes.ResourceExhausted, err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Namespace quota counts every access point of the file system",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode:   "efs-ap",
+						FsId:               fsId,
+						DirectoryPerms:     "777",
+						Uid:                "1000",
+						Gid:                "1000",
+						PvcNamespace:       "tenant-a",
+						MaxApsPerNamespace: "30",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				// More than the 100 access points EFS returns in one page, with the namespace's last
+				var accessPoints []*cloud.AccessPoint
+				for i := 0; i < 150; i++ {
+					namespace := "tenant-b"
+					if i >= 120 {
+						namespace = "tenant-a"
+					}
+					accessPoints = append(accessPoints, &cloud.AccessPoint{
+						AccessPointId: fmt.Sprintf("fsap-%d", i),
+						Tags:          map[string]string{DefaultTagKey: DefaultTagValue, PvcNamespaceTagKey: namespace},
+					})
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(accessPoints, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.ResourceExhausted {
+					t.Fatalf("Expected error code %v, got: %v", codes.ResourceExhausted, err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Access point quota requires the PVC namespace",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode:   "efs-ap",
+						FsId:               fsId,
+						DirectoryPerms:     "777",
+						MaxApsPerNamespace: "2",
+					},
+				}
+
+				ctx := context.Background()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected error code %v, got: %v", codes.InvalidArgument, err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: basePath contains a parent directory reference",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						BasePath:         "dynamic/../../escape",
+					},
+				}
+
+				ctx := context.Background()
+				fileSystem := &cloud.FileSystem{
+					FileSystemId: fsId,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(fileSystem, nil).AnyTimes()
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Any()).Return(nil, nil).AnyTimes()
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: subPathPattern resolves outside of basePath",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode:      "efs-ap",
+						FsId:                  fsId,
+						DirectoryPerms:        "777",
+						BasePath:              "/dynamic",
+						SubPathPattern:        "../other-tenant/${.PVC.name}",
+						EnsureUniqueDirectory: "false",
+						PvcName:               "my-pvc",
+					},
+				}
+
+				ctx := context.Background()
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Cannot assume role for x-account",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				secrets := map[string]string{}
+				secrets["awsRoleArn"] = "arn:aws:iam::1234567890:role/EFSCrossAccountRole"
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						GidMin:           "1000",
+						GidMax:           "2000",
+						DirectoryPerms:   "777",
+						AzName:           "us-east-1a",
+					},
+					Secrets: secrets,
+				}
+
+				ctx := context.Background()
+
+				_, err := driver.CreateVolume(ctx, req)
+
+				if err == nil {
+					t.Fatalf("CreateVolume did not fail")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: subPathPattern is specified but uses unsupported attributes",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				subPathPattern := "${.PVC.name}/${foo}"
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						SubPathPattern:   subPathPattern,
+					},
+				}
+
+				ctx := context.Background()
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err == nil {
+					t.Fatal("CreateVolume did not fail")
+				}
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Did not throw InvalidArgument error, instead threw %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: resulting accessPointDirectory is too over 100 characters",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				subPathPattern := "this-directory-name-is-far-too-long-for-any-practical-purposes-and-only-serves-to-prove-a-point"
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						SubPathPattern:   subPathPattern,
+					},
+				}
+
+				ctx := context.Background()
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err == nil {
+					t.Fatal("CreateVolume did not fail")
+				}
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Did not throw InvalidArgument error, instead threw %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail:  resulting accessPointDirectory contains over 4 subdirectories",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				subPathPattern := "a/b/c/d/e/f"
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: capacityRange,
+					},
+					Parameters: map[string]string{
+						ProvisioningMode: "efs-ap",
+						FsId:             fsId,
+						DirectoryPerms:   "777",
+						SubPathPattern:   subPathPattern,
+					},
+				}
+
+				ctx := context.Background()
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err == nil {
+					t.Fatal("CreateVolume did not fail")
+				}
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Did not throw InvalidArgument error, instead threw %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestDeleteVolume(t *testing.T) {
+	var (
+		apId     = "fsap-abcd1234xyz987"
+		fsId     = "fs-abcd1234"
+		endpoint = "endpoint"
+		volumeId = "fs-abcd1234::fsap-abcd1234xyz987"
+	)
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Success: Normal flow",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Normal flow with deleteAccessPointRootDir",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-abcd1234",
+					CapacityGiB:        0,
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Concurrent deletes of the same access point use distinct mount targets",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				// Concurrent deletes of the same volume ID are coalesced, so each delete names the access point
+				// with a different volume ID format
+				reqs := []*csi.DeleteVolumeRequest{
+					{VolumeId: volumeId},
+					{VolumeId: newAccessPointVolumeHandle(fsId, apId).String()},
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-abcd1234",
+					CapacityGiB:        0,
+				}
+
+				const deletes = 2
+				var mu sync.Mutex
+				targets := map[string]bool{}
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil).Times(deletes)
+				mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(deletes).
+					Do(func(source, target, fstype string, options []string) {
+						mu.Lock()
+						defer mu.Unlock()
+						targets[target] = true
+					})
+				mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil).Times(deletes)
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil).Times(deletes)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil).Times(deletes)
+
+				var wg sync.WaitGroup
+				errs := make(chan error, deletes)
+				for _, req := range reqs {
+					req := req
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, err := driver.DeleteVolume(ctx, req)
+						errs <- err
+					}()
+				}
+				wg.Wait()
+				close(errs)
+				for err := range errs {
+					if err != nil {
+						t.Fatalf("Delete Volume failed: %v", err)
+					}
+				}
+				if len(targets) != deletes {
+					t.Fatalf("Expected %d distinct mount targets, got: %v", deletes, targets)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Concurrent deletes under a configured temporary mount prefix remove only their own mount points",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				prefix := t.TempDir()
+				unrelated := path.Join(prefix, "unrelated")
+				if err := os.Mkdir(unrelated, 0755); err != nil {
+					t.Fatal(err)
+				}
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+					tempMountPrefix:          prefix,
+				}
+
+				// Concurrent deletes of the same volume ID are coalesced, so each delete names the access point
+				// with a different volume ID format
+				reqs := []*csi.DeleteVolumeRequest{
+					{VolumeId: volumeId},
+					{VolumeId: newAccessPointVolumeHandle(fsId, apId).String()},
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-1234",
+					CapacityGiB:        0,
+				}
+
+				const deletes = 2
+				var mu sync.Mutex
+				unmounted := map[string]bool{}
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Times(deletes).DoAndReturn(func(target string) error {
+					if path.Dir(target) != prefix {
+						t.Errorf("Mount point %q is not in %q", target, prefix)
+					}
+					return os.Mkdir(target, 0755)
+				})
+				// Stand in for the file system by populating the root directory in the mount point
+				mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(deletes).DoAndReturn(
+					func(source, target, fstype string, options []string) error {
+						if err := os.Mkdir(path.Join(target, "pvc-1234"), 0755); err != nil {
+							return err
+						}
+						return os.WriteFile(path.Join(target, "pvc-1234", "data"), nil, 0644)
+					})
+				mockMounter.EXPECT().Unmount(gomock.Any()).Times(deletes).DoAndReturn(func(target string) error {
+					mu.Lock()
+					defer mu.Unlock()
+					unmounted[target] = true
+					return nil
+				})
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil).Times(deletes)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil).Times(deletes)
+
+				var wg sync.WaitGroup
+				errs := make(chan error, deletes)
+				for _, req := range reqs {
+					req := req
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, err := driver.DeleteVolume(ctx, req)
+						errs <- err
+					}()
+				}
+				wg.Wait()
+				close(errs)
+				for err := range errs {
+					if err != nil {
+						t.Fatalf("Delete Volume failed: %v", err)
+					}
+				}
+				if len(unmounted) != deletes {
+					t.Fatalf("Expected %d distinct mount points to be unmounted, got: %v", deletes, unmounted)
+				}
+				entries, err := os.ReadDir(prefix)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(entries) != 1 || entries[0].Name() != "unrelated" {
+					t.Fatalf("Expected only the unrelated directory to remain in %q, got: %v", prefix, entries)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Delete access point root dir over plain NFS",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+					plainNfsInternalMounts:   true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-abcd1234",
+					CapacityGiB:        0,
+				}
+				mountTarget := &cloud.MountTarget{
+					MountTargetId: "fsmt-abcd1234",
+					IPAddress:     "10.0.1.10",
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq("10.0.1.10:/"), gomock.Any(), gomock.Eq("nfs4"), gomock.Eq(strings.Split(NfsMountOptions, ","))).Return(nil)
+				mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq(fsId), gomock.Eq("")).Return(mountTarget, nil)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Retain access point and remove its data",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:          endpoint,
+					cloud:             mockCloud,
+					mounter:           mockMounter,
+					gidAllocator:      NewGidAllocator(),
+					retainAccessPoint: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-1234",
+					CapacityGiB:        0,
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(fsId), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Any()).Times(0)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Mount with the mount options configured for the file system",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+					fsMountOptions: map[string][]string{
+						fsId:          {"az=us-east-1a", "regional"},
+						"fs-efgh5678": {"az=us-east-1b"},
+					},
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-1234",
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(fsId), gomock.Any(), gomock.Eq("efs"), gomock.Eq([]string{"tls", "iam", "az=us-east-1a", "regional"})).Return(nil)
+				mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Storage class mount options override those configured for the file system",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+					fsMountOptions: map[string][]string{
+						fsId: {"az=us-east-1a", "regional"},
+					},
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+					Secrets:  map[string]string{MountOptions: "az=us-east-1b,noresvport"},
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-1234",
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(fsId), gomock.Any(), gomock.Eq("efs"), gomock.Eq([]string{"tls", "iam", "regional", "az=us-east-1b", "noresvport"})).Return(nil)
+				mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Storage class parameters recorded on the access point set the mount options",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+					fsMountOptions: map[string][]string{
+						fsId: {"az=us-east-1a"},
+					},
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+					Secrets:  map[string]string{MountOptions: "az=us-east-1c"},
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-1234",
+					Tags: map[string]string{
+						MountOptionsTagKey:     "az=us-east-1b noresvport",
+						EncryptInTransitTagKey: "false",
+					},
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(fsId), gomock.Any(), gomock.Eq("efs"), gomock.Eq([]string{"noresvport", "az=us-east-1c"})).Return(nil)
+				mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: deleteRootDir secret deletes the root directory when the flag is off",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					mounter:      mockMounter,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+					Secrets:  map[string]string{DeleteRootDir: "true"},
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-1234",
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(fsId), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: deleteRootDir secret keeps the root directory when the flag is on",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+					Secrets:  map[string]string{DeleteRootDir: "false"},
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Any()).Times(0)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: deleteRootDir secret is not a boolean",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+					Secrets:  map[string]string{DeleteRootDir: "sometimes"},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Any()).Times(0)
+				_, err := driver.DeleteVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: tls mount option conflicts with encryption in transit disabled",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+					Secrets:  map[string]string{EncryptInTransit: "false", MountOptions: "tls"},
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-1234",
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Any()).Times(0)
+				_, err := driver.DeleteVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Delete a versioned volume ID",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: newAccessPointVolumeHandle(fsId, apId).String(),
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Dry run volume has nothing to delete",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint: endpoint,
+					cloud:    mockCloud,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: DryRunVolumePrefix + "pvc-abcd1234",
+				}
+
+				ctx := context.Background()
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Repeated DeleteVolume within the retry interval is aborted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:      endpoint,
+					cloud:         mockCloud,
+					gidAllocator:  NewGidAllocator(),
+					deleteLimiter: newDeleteLimiter(time.Minute),
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(errors.New("Delete Volume failed")).Times(1)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err == nil {
+					t.Fatal("DeleteVolume did not fail")
+				}
+				for i := 0; i < 3; i++ {
+					_, err = driver.DeleteVolume(ctx, req)
+					if status.Code(err) != codes.Aborted {
+						t.Fatalf("Expected Aborted, got %v", err)
+					}
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Access point rooted at the file system root is not deleted",
+			testFunc: func(t *testing.T) {
+				for _, rootDir := range []string{"", "/", "//", "/a/.."} {
+					mockCtl := gomock.NewController(t)
+					mockCloud := mocks.NewMockCloud(mockCtl)
+					mockMounter := mocks.NewMockMounter(mockCtl)
+
+					driver := &Driver{
+						endpoint:                 endpoint,
+						cloud:                    mockCloud,
+						mounter:                  mockMounter,
+						gidAllocator:             NewGidAllocator(),
+						deleteAccessPointRootDir: true,
+					}
+
+					req := &csi.DeleteVolumeRequest{
+						VolumeId: volumeId,
+					}
+
+					ctx := context.Background()
+					accessPoint := &cloud.AccessPoint{
+						AccessPointId:      apId,
+						FileSystemId:       fsId,
+						AccessPointRootDir: rootDir,
+					}
+					// Neither the file system is mounted nor the access point deleted
+					mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+					_, err := driver.DeleteVolume(ctx, req)
+					if status.Code(err) != codes.InvalidArgument {
+						t.Fatalf("Expected InvalidArgument for root directory %q, got: %v", rootDir, err)
+					}
+					mockCtl.Finish()
+				}
+			},
+		},
+		{
+			name: "Success: Cross account delete mounts through a mount target in the recorded availability zone",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+					roleClouds:               newRoleCloudCache(mockCloud),
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+					Secrets:  map[string]string{RoleArn: "arn:aws:iam::1234567890:role/EFSCrossAccountRole"},
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-abcd1234",
+					Tags:               map[string]string{DefaultTagKey: DefaultTagValue, AzNameTagKey: "us-east-1b"},
+				}
+				mountTarget := &cloud.MountTarget{
+					MountTargetId: "fsmt-b",
+					AZName:        "us-east-1b",
+					IPAddress:     "10.0.2.10",
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq(fsId), gomock.Eq("us-east-1b")).Return(mountTarget, nil)
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(fsId), gomock.Any(), gomock.Eq("efs"), gomock.Eq([]string{"tls", "iam", MountTargetIp + "=10.0.2.10"})).Return(nil)
+				mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Retain access point when its root directory cannot be mounted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					retainAccessPoint:        true,
+					rootDirCleanupBestEffort: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-1234",
+					CapacityGiB:        0,
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(fsId), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("Failed to mount"))
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err == nil {
+					t.Fatal("DeleteVolume did not fail")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: DescribeAccessPoint Access Point Does not exist",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil, cloud.ErrNotFound)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: DescribeAccessPoint Access Denied",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil, cloud.ErrAccessDenied)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err == nil {
+					t.Fatalf("DeleteVolume did not fail")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: DescribeAccessPoint failed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil, errors.New("Describe Access Point failed"))
+				_, err := driver.DeleteVolume(ctx, req)
+				if err == nil {
+					t.Fatalf("DeleteVolume did not fail")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Fail to make directory for access point mount",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-abcd1234",
+					CapacityGiB:        0,
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(errors.New("Failed to makeDir"))
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err == nil {
+					t.Fatal("DeleteVolume did not fail")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Fail to mount file system on directory for access point root directory removal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-abcd1234",
+					CapacityGiB:        0,
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("Failed to mount"))
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err == nil {
+					t.Fatal("DeleteVolume did not fail")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Controller host cannot mount the efs file system type",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-abcd1234",
+					CapacityGiB:        0,
+				}
+
+				ctx := context.Background()
+				mountErr := errors.New("mount failed: exit status 32\nMounting command: mount\nOutput: mount: /var/lib/csi/pv/fsap: unknown filesystem type 'efs'.")
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mountErr)
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if status.Code(err) != codes.FailedPrecondition {
+					t.Fatalf("Expected FailedPrecondition, got: %v", err)
+				}
+				if !strings.Contains(err.Error(), "efs-utils") {
+					t.Fatalf("Expected error to suggest installing efs-utils, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Delete access point when root directory cleanup mount fails and best effort cleanup is enabled",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+					rootDirCleanupBestEffort: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/data",
+					CapacityGiB:        0,
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(fsId), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("Failed to mount"))
+				mockMounter.EXPECT().Unmount(gomock.Any()).Times(0)
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Fail to unmount file system after access point root directory removal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-abcd1234",
+					CapacityGiB:        0,
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Unmount(gomock.Any()).Return(errors.New("Failed to unmount"))
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err == nil {
+					t.Fatal("DeleteVolume did not fail")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Access Point already deleted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(cloud.ErrNotFound)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Delete Volume failed: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: DeleteAccessPoint access denied",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(cloud.ErrAccessDenied)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err == nil {
+					t.Fatal("DeleteVolume did not fail")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: DeleteVolume fails",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(errors.New("Delete Volume failed"))
+				_, err := driver.DeleteVolume(ctx, req)
+				if err == nil {
+					t.Fatal("DeleteVolume did not fail")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Access Point is missing in volume Id",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: "fs-abcd1234",
+				}
+
+				ctx := context.Background()
+				_, err := driver.DeleteVolume(ctx, req)
+				if err == nil {
+					t.Fatal("DeleteVolume did not fail")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Volume ID is empty",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: "",
+				}
+
+				ctx := context.Background()
+				_, err := driver.DeleteVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Volume ID is missing the file system ID",
+			testFunc: func(t *testing.T) {
+				for _, volumeId := range []string{"::" + apId, "v1:accesspoint::" + apId, "v1:accesspoint::" + apId + ":/subpath"} {
+					for _, deleteAccessPointRootDir := range []bool{false, true} {
+						mockCtl := gomock.NewController(t)
+						mockCloud := mocks.NewMockCloud(mockCtl)
+						mockMounter := mocks.NewMockMounter(mockCtl)
+
+						driver := &Driver{
+							endpoint:                 endpoint,
+							cloud:                    mockCloud,
+							mounter:                  mockMounter,
+							gidAllocator:             NewGidAllocator(),
+							deleteAccessPointRootDir: deleteAccessPointRootDir,
+						}
+
+						req := &csi.DeleteVolumeRequest{
+							VolumeId: volumeId,
+						}
+
+						ctx := context.Background()
+						_, err := driver.DeleteVolume(ctx, req)
+						if status.Code(err) != codes.InvalidArgument {
+							t.Fatalf("Expected InvalidArgument for %v with deleteAccessPointRootDir %v, got: %v", volumeId, deleteAccessPointRootDir, err)
+						}
+						mockCtl.Finish()
+					}
+				}
+			},
+		},
+		{
+			name: "Success: Malformed volume ID is treated as already deleted",
+			testFunc: func(t *testing.T) {
+				for _, deleteAccessPointRootDir := range []bool{false, true} {
+					mockCtl := gomock.NewController(t)
+					mockCloud := mocks.NewMockCloud(mockCtl)
+					mockMounter := mocks.NewMockMounter(mockCtl)
+
+					driver := &Driver{
+						endpoint:                 endpoint,
+						cloud:                    mockCloud,
+						mounter:                  mockMounter,
+						gidAllocator:             NewGidAllocator(),
+						deleteAccessPointRootDir: deleteAccessPointRootDir,
+					}
+
+					req := &csi.DeleteVolumeRequest{
+						VolumeId: "reallyfakevolumeid",
+					}
+
+					ctx := context.Background()
+					_, err := driver.DeleteVolume(ctx, req)
+					if err != nil {
+						t.Fatalf("DeleteVolume failed with deleteAccessPointRootDir %v: %v", deleteAccessPointRootDir, err)
+					}
+					mockCtl.Finish()
+				}
+			},
+		},
+		{
+			name: "Fail: Mount does not finish before the context is done",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/pvc-1234",
+					CapacityGiB:        0,
+				}
+
+				// The delete is bounded by the driver's timeout, not by the context of the call that started it
+				driver.deleteTimeout = 50 * time.Millisecond
+				ctx := context.Background()
+				unmounted := make(chan string, 1)
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(fsId), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(source, target, fstype string, options []string) error {
+						// Finish after DeleteVolume has given up on the mount
+						time.Sleep(driver.deleteTimeout + 10*time.Millisecond)
+						return nil
+					})
+				mockMounter.EXPECT().Unmount(gomock.Any()).DoAndReturn(func(target string) error {
+					unmounted <- target
+					return nil
+				})
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+
+				_, err := driver.DeleteVolume(ctx, req)
+				if status.Code(err) != codes.DeadlineExceeded {
+					t.Fatalf("Expected DeadlineExceeded, got: %v", err)
+				}
+
+				select {
+				case target := <-unmounted:
+					if !strings.HasPrefix(target, TempMountPathPrefix+"/"+apId+"-") {
+						t.Fatalf("Unexpected unmount of %q", target)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatal("Abandoned mount was not unmounted")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Access point root directory resolves outside of the mount",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockMounter := mocks.NewMockMounter(mockCtl)
+
+				driver := &Driver{
+					endpoint:                 endpoint,
+					cloud:                    mockCloud,
+					mounter:                  mockMounter,
+					gidAllocator:             NewGidAllocator(),
+					deleteAccessPointRootDir: true,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+				}
+
+				accessPoint := &cloud.AccessPoint{
+					AccessPointId:      apId,
+					FileSystemId:       fsId,
+					AccessPointRootDir: "/../../../etc",
+					CapacityGiB:        0,
+				}
+
+				ctx := context.Background()
+				mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(fsId), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+
+				_, err := driver.DeleteVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Volume ID was produced by a newer driver version",
+			testFunc: func(t *testing.T) {
+				for _, deleteAccessPointRootDir := range []bool{false, true} {
+					mockCtl := gomock.NewController(t)
+					mockCloud := mocks.NewMockCloud(mockCtl)
+					mockMounter := mocks.NewMockMounter(mockCtl)
+
+					driver := &Driver{
+						endpoint:                 endpoint,
+						cloud:                    mockCloud,
+						mounter:                  mockMounter,
+						gidAllocator:             NewGidAllocator(),
+						deleteAccessPointRootDir: deleteAccessPointRootDir,
+					}
+
+					req := &csi.DeleteVolumeRequest{
+						VolumeId: "v9:accesspoint:" + fsId + ":" + apId,
+					}
+
+					ctx := context.Background()
+					_, err := driver.DeleteVolume(ctx, req)
+					if status.Code(err) != codes.Unimplemented {
+						t.Fatalf("Expected Unimplemented with deleteAccessPointRootDir %v, got: %v", deleteAccessPointRootDir, err)
+					}
+					mockCtl.Finish()
+				}
+			},
+		},
+		{
+			name: "Fail: Cannot assume role for x-account",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint:     endpoint,
+					cloud:        mockCloud,
+					gidAllocator: NewGidAllocator(),
+					tags:         parseTagsFromStr(""),
+				}
+
+				secrets := map[string]string{}
+				secrets["awsRoleArn"] = "arn:aws:iam::1234567890:role/EFSCrossAccountRole"
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: volumeId,
+					Secrets:  secrets,
+				}
+
+				ctx := context.Background()
+
+				_, err := driver.DeleteVolume(ctx, req)
+
+				if err == nil {
+					t.Fatalf("DeleteVolume did not fail")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestValidateVolumeCapabilities(t *testing.T) {
+	var (
+		endpoint       = "endpoint"
+		volumeId       = "fs-abcd1234::fsap-abcd1234xyz987"
+		stdVolCapValid = &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{
+				Mount: &csi.VolumeCapability_MountVolume{},
+			},
+			AccessMode: &csi.VolumeCapability_AccessMode{
+				Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+			},
+		}
+		stdVolCapInvalid = &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{
+				Mount: &csi.VolumeCapability_MountVolume{},
+			},
+			AccessMode: &csi.VolumeCapability_AccessMode{
+				Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY,
+			},
+		}
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Success",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint: endpoint,
+					cloud:    mockCloud,
+				}
+
+				req := &csi.ValidateVolumeCapabilitiesRequest{
+					VolumeId: volumeId,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCapValid,
+					},
+				}
+
+				ctx := context.Background()
+				res, err := driver.ValidateVolumeCapabilities(ctx, req)
+				if err != nil {
+					t.Fatalf("ValidateVolumeCapabilities failed: %v", err)
+				}
+
+				if res.Confirmed == nil {
+					t.Fatalf("Capability is not supported")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Success: Unsupported volume capability",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint: endpoint,
+					cloud:    mockCloud,
+				}
+
+				req := &csi.ValidateVolumeCapabilitiesRequest{
+					VolumeId: volumeId,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCapInvalid,
+					},
+				}
+
+				ctx := context.Background()
+				res, err := driver.ValidateVolumeCapabilities(ctx, req)
+				if err != nil {
+					t.Fatalf("ValidateVolumeCapabilities failed: %v", err)
+				}
+
+				if res.Confirmed != nil {
+					t.Fatal("ValidateVolumeCapabilities did not fail")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Volume Id is missing",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint: endpoint,
+					cloud:    mockCloud,
+				}
+
+				req := &csi.ValidateVolumeCapabilitiesRequest{
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCapValid,
+					},
+				}
+
+				ctx := context.Background()
+				_, err := driver.ValidateVolumeCapabilities(ctx, req)
+				if err == nil {
+					t.Fatal("ValidateVolumeCapabilities did not fail")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "Fail: Volume Capabilities is missing",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := &Driver{
+					endpoint: endpoint,
+					cloud:    mockCloud,
+				}
+
+				req := &csi.ValidateVolumeCapabilitiesRequest{
+					VolumeId: volumeId,
+				}
+
+				ctx := context.Background()
+				_, err := driver.ValidateVolumeCapabilities(ctx, req)
+				if err == nil {
+					t.Fatal("ValidateVolumeCapabilities did not fail")
+				}
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestControllerGetCapabilities(t *testing.T) {
+	var endpoint = "endpoint"
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+
+	driver := &Driver{
+		endpoint: endpoint,
+		cloud:    mockCloud,
+	}
+
+	ctx := context.Background()
+	_, err := driver.ControllerGetCapabilities(ctx, &csi.ControllerGetCapabilitiesRequest{})
+	if err != nil {
+		t.Fatalf("ControllerGetCapabilities failed: %v", err)
+	}
+}
+
+func TestControllerExpandVolume(t *testing.T) {
+	const (
+		fsId     = "fs-abcd1234"
+		apId     = "fsap-abcd1234xyz987"
+		newBytes = 10 * 1024 * 1024 * 1024
+	)
+	volumeId := newAccessPointVolumeHandle(fsId, apId).String()
+
+	testCases := []struct {
+		name          string
+		req           *csi.ControllerExpandVolumeRequest
+		mockCalls     func(ctx context.Context, mockCloud *mocks.MockCloud)
+		expectBytes   int64
+		expectErrCode codes.Code
+	}{
+		{
+			name: "Success: access point volume",
+			req: &csi.ControllerExpandVolumeRequest{
+				VolumeId:      volumeId,
+				CapacityRange: &csi.CapacityRange{RequiredBytes: newBytes},
+			},
+			mockCalls: func(ctx context.Context, mockCloud *mocks.MockCloud) {
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(&cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId}, nil)
+			},
+			expectBytes: newBytes,
+		},
+		{
+			name: "Success: file system volume sized by limit bytes",
+			req: &csi.ControllerExpandVolumeRequest{
+				VolumeId:      fsId + ":/a/b",
+				CapacityRange: &csi.CapacityRange{LimitBytes: newBytes},
+			},
+			mockCalls: func(ctx context.Context, mockCloud *mocks.MockCloud) {
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fsId)).Return(&cloud.FileSystem{FileSystemId: fsId}, nil)
+			},
+			expectBytes: newBytes,
+		},
+		{
+			name: "Fail: missing volume ID",
+			req: &csi.ControllerExpandVolumeRequest{
+				CapacityRange: &csi.CapacityRange{RequiredBytes: newBytes},
+			},
+			expectErrCode: codes.InvalidArgument,
+		},
+		{
+			name: "Fail: missing capacity range",
+			req: &csi.ControllerExpandVolumeRequest{
+				VolumeId: volumeId,
+			},
+			expectErrCode: codes.InvalidArgument,
+		},
+		{
+			name: "Fail: required bytes exceed limit bytes",
+			req: &csi.ControllerExpandVolumeRequest{
+				VolumeId:      volumeId,
+				CapacityRange: &csi.CapacityRange{RequiredBytes: newBytes, LimitBytes: newBytes / 2},
+			},
+			expectErrCode: codes.OutOfRange,
+		},
+		{
+			name: "Fail: malformed volume ID",
+			req: &csi.ControllerExpandVolumeRequest{
+				VolumeId:      "vol-1234",
+				CapacityRange: &csi.CapacityRange{RequiredBytes: newBytes},
+			},
+			expectErrCode: codes.NotFound,
+		},
+		{
+			name: "Fail: access point does not exist",
+			req: &csi.ControllerExpandVolumeRequest{
+				VolumeId:      volumeId,
+				CapacityRange: &csi.CapacityRange{RequiredBytes: newBytes},
+			},
+			mockCalls: func(ctx context.Context, mockCloud *mocks.MockCloud) {
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(nil, cloud.ErrNotFound)
+			},
+			expectErrCode: codes.NotFound,
+		},
+		{
+			name: "Fail: access denied",
+			req: &csi.ControllerExpandVolumeRequest{
+				VolumeId:      volumeId,
+				CapacityRange: &csi.CapacityRange{RequiredBytes: newBytes},
+			},
+			mockCalls: func(ctx context.Context, mockCloud *mocks.MockCloud) {
+				mockCloud.EXPECT().DescribeAccessPoint(gomock.Eq(ctx), gomock.Eq(apId)).Return(nil, cloud.ErrAccessDenied)
+			},
+			expectErrCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+			mockCloud := mocks.NewMockCloud(mockCtl)
+			driver := &Driver{
+				endpoint: "endpoint",
+				cloud:    mockCloud,
+			}
+
+			ctx := context.Background()
+			if tc.mockCalls != nil {
+				tc.mockCalls(ctx, mockCloud)
+			}
+			res, err := driver.ControllerExpandVolume(ctx, tc.req)
+			if tc.expectErrCode != codes.OK {
+				if status.Code(err) != tc.expectErrCode {
+					t.Fatalf("Expected %v, got: %v", tc.expectErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ControllerExpandVolume failed: %v", err)
+			}
+			if res.CapacityBytes != tc.expectBytes {
+				t.Fatalf("Expected capacity %d, got %d", tc.expectBytes, res.CapacityBytes)
+			}
+			if res.NodeExpansionRequired {
+				t.Fatal("Expected no node expansion to be required")
+			}
+		})
+	}
+}
+
+func TestGetCloudRoleArnValidation(t *testing.T) {
+	testCases := []struct {
+		name    string
+		roleArn string
+		valid   bool
+	}{
+		{name: "Success: commercial partition", roleArn: "arn:aws:iam::1234567890:role/EFSCrossAccountRole", valid: true},
+		{name: "Success: gov cloud partition", roleArn: "arn:aws-us-gov:iam::1234567890:role/EFSCrossAccountRole", valid: true},
+		{name: "Success: role with path", roleArn: "arn:aws-cn:iam::1234567890:role/path/to/EFSRole", valid: true},
+		{name: "Fail: not an ARN", roleArn: "EFSCrossAccountRole"},
+		{name: "Fail: non numeric account", roleArn: "arn:aws:iam::account:role/EFSCrossAccountRole"},
+		{name: "Fail: user instead of role", roleArn: "arn:aws:iam::1234567890:user/EFSUser"},
+		{name: "Fail: wrong service", roleArn: "arn:aws:sts::1234567890:role/EFSCrossAccountRole"},
+		{name: "Fail: missing role name", roleArn: "arn:aws:iam::1234567890:role/"},
+		{name: "Fail: surrounding whitespace", roleArn: " arn:aws:iam::1234567890:role/EFSCrossAccountRole"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if valid := roleArnPattern.MatchString(tc.roleArn); valid != tc.valid {
+				t.Fatalf("Expected %q to be valid: %v, got: %v", tc.roleArn, tc.valid, valid)
+			}
+			if tc.valid {
+				return
+			}
+
+			driver := &Driver{}
+			_, _, err := getCloud(map[string]string{RoleArn: tc.roleArn}, driver, "")
+			if err == nil {
+				t.Fatalf("getCloud did not fail for %q", tc.roleArn)
+			}
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("Expected error code %v, got: %v", codes.InvalidArgument, err)
+			}
+			if !strings.Contains(err.Error(), tc.roleArn) {
+				t.Fatalf("Expected error to contain the offending value %q, got: %v", tc.roleArn, err)
+			}
+		})
+	}
+}
+
+func TestGetCloudSessionDurationValidation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		sessionDuration string
+	}{
+		{name: "Fail: not a number", sessionDuration: "1h"},
+		{name: "Fail: below minimum", sessionDuration: "899"},
+		{name: "Fail: above maximum", sessionDuration: "43201"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver := &Driver{}
+			secrets := map[string]string{
+				RoleArn:         "arn:aws:iam::1234567890:role/EFSCrossAccountRole",
+				SessionDuration: tc.sessionDuration,
+			}
+			_, _, err := getCloud(secrets, driver, "")
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("Expected error code %v, got: %v", codes.InvalidArgument, err)
+			}
+		})
+	}
+}
+
+func TestCleanupTempMounts(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	mockMounter := mocks.NewMockMounter(mockCtl)
+	driver := &Driver{mounter: mockMounter}
+
+	dir := t.TempDir()
+	mounted := path.Join(dir, "fsap-abcd1234xyz987")
+	unmounted := path.Join(dir, "fsap-abcd1234xyz987-"+uuid.New().String())
+	stuck := path.Join(dir, "fsap-efgh5678")
+	other := path.Join(dir, "pvc-1234")
+	for _, p := range []string{mounted, unmounted, stuck, other} {
+		if err := os.Mkdir(p, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(mounted)).Return(false, nil)
+	mockMounter.EXPECT().Unmount(gomock.Eq(mounted)).Return(nil)
+	mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(unmounted)).Return(true, nil)
+	mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stuck)).Return(false, nil)
+	mockMounter.EXPECT().Unmount(gomock.Eq(stuck)).Return(errors.New("device is busy"))
+
+	driver.cleanupTempMounts(dir)
+
+	for _, p := range []string{mounted, unmounted} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("Expected leftover temporary mount point %q to be removed, got: %v", p, err)
+		}
+	}
+	for _, p := range []string{stuck, other} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("Expected %q to be kept, got: %v", p, err)
+		}
+	}
+
+	// A missing directory is not an error
+	driver.cleanupTempMounts(path.Join(dir, "missing"))
+}
+
+// testMetadata is a cloud.MetadataService for an instance in region.
+type testMetadata struct {
+	region string
+}
+
+func (m *testMetadata) GetInstanceID() string       { return "i-1234567890abcdef0" }
+func (m *testMetadata) GetRegion() string           { return m.region }
+func (m *testMetadata) GetAvailabilityZone() string { return m.region + "a" }
+
+// newRoleCloudCache returns a cloud cache that hands out localCloud for every cross account role.
+func newRoleCloudCache(localCloud cloud.Cloud) *cloudCache {
+	cache := newCloudCache(time.Minute, cloud.EndpointOptions{}, nil)
+	cache.newCloud = func(roleArn, tokenFile string, sessionDuration time.Duration, region string) (cloud.Cloud, error) {
+		return localCloud, nil
+	}
+	return cache
+}
+
+func TestCreateVolumeSubnetId(t *testing.T) {
+	const (
+		fsId       = "fs-abcd1234"
+		apId       = "fsap-abcd1234xyz987"
+		volumeName = "volumeName"
+	)
+	mountTarget := &cloud.MountTarget{MountTargetId: "fsmt-b", AZName: "us-east-1b", SubnetId: "subnet-b", IPAddress: "10.0.2.10"}
+
+	testCases := []struct {
+		name          string
+		params        map[string]string
+		expectLookup  bool
+		lookupErr     error
+		expectErrCode codes.Code
+	}{
+		{
+			name:         "Success: mount target in the subnet is used for cross account mount",
+			params:       map[string]string{SubnetId: "subnet-b"},
+			expectLookup: true,
+		},
+		{
+			name:         "Success: subnet agrees with az",
+			params:       map[string]string{SubnetId: "subnet-b", AzName: "us-east-1b"},
+			expectLookup: true,
+		},
+		{
+			name:          "Fail: subnet conflicts with az",
+			params:        map[string]string{SubnetId: "subnet-b", AzName: "us-east-1a"},
+			expectLookup:  true,
+			expectErrCode: codes.InvalidArgument,
+		},
+		{
+			name:          "Fail: no mount target in the subnet",
+			params:        map[string]string{SubnetId: "subnet-c"},
+			expectLookup:  true,
+			lookupErr:     cloud.ErrNotFound,
+			expectErrCode: codes.InvalidArgument,
+		},
+		{
+			name:          "Fail: malformed subnet ID",
+			params:        map[string]string{SubnetId: "sn-123"},
+			expectErrCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := mocks.NewMockCloud(mockCtl)
+
+			driver := &Driver{
+				endpoint:     "endpoint",
+				cloud:        mockCloud,
+				gidAllocator: NewGidAllocator(),
+				roleClouds:   newRoleCloudCache(mockCloud),
+			}
+
+			params := map[string]string{
+				ProvisioningMode: "efs-ap",
+				FsId:             fsId,
+				DirectoryPerms:   "777",
+				Uid:              "1000",
+				Gid:              "1000",
+			}
+			for k, v := range tc.params {
+				params[k] = v
+			}
+			req := &csi.CreateVolumeRequest{
+				Name: volumeName,
+				VolumeCapabilities: []*csi.VolumeCapability{
+					{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+				},
+				Parameters: params,
+				Secrets:    map[string]string{RoleArn: "arn:aws:iam::1234567890:role/EFSCrossAccountRole"},
+			}
+
+			ctx := context.Background()
+			mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(&cloud.FileSystem{FileSystemId: fsId}, nil).AnyTimes()
+			var tags map[string]string
+			mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, clientToken string, accessPointOpts *cloud.AccessPointOptions, reuseAccessPoint bool) (*cloud.AccessPoint, error) {
+					tags = accessPointOpts.Tags
+					return &cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId}, nil
+				}).AnyTimes()
+			if tc.expectLookup {
+				if tc.lookupErr != nil {
+					mockCloud.EXPECT().DescribeMountTargetInSubnet(gomock.Eq(ctx), gomock.Eq(fsId), gomock.Eq(tc.params[SubnetId])).Return(nil, tc.lookupErr)
+				} else {
+					mockCloud.EXPECT().DescribeMountTargetInSubnet(gomock.Eq(ctx), gomock.Eq(fsId), gomock.Eq(tc.params[SubnetId])).Return(mountTarget, nil)
+				}
+			}
+
+			res, err := driver.CreateVolume(ctx, req)
+			if tc.expectErrCode != codes.OK {
+				if status.Code(err) != tc.expectErrCode {
+					t.Fatalf("Expected %v, got: %v", tc.expectErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateVolume failed: %v", err)
+			}
+			if res.Volume.VolumeContext[MountTargetIp] != mountTarget.IPAddress {
+				t.Fatalf("Mount target IP mismatched. Expected: %v, actual: %v", mountTarget.IPAddress, res.Volume.VolumeContext[MountTargetIp])
+			}
+			if tags[SubnetIdTagKey] != tc.params[SubnetId] {
+				t.Fatalf("Subnet tag mismatched. Expected: %v, actual: %v", tc.params[SubnetId], tags[SubnetIdTagKey])
+			}
+			mockCtl.Finish()
+		})
+	}
+}
+
+func TestCreateVolumeFileSystemName(t *testing.T) {
+	const (
+		fsId       = "fs-abcd1234"
+		apId       = "fsap-abcd1234xyz987"
+		volumeName = "volumeName"
+	)
+
+	testCases := []struct {
+		name          string
+		params        map[string]string
+		matches       []*cloud.FileSystem
+		expectLookup  bool
+		expectErrCode codes.Code
+	}{
+		{
+			name:         "Success: name matches a single file system",
+			params:       map[string]string{FileSystemName: "shared"},
+			matches:      []*cloud.FileSystem{{FileSystemId: fsId}},
+			expectLookup: true,
+		},
+		{
+			name:   "Success: fileSystemId is preferred over fileSystemName",
+			params: map[string]string{FsId: fsId, FileSystemName: "shared"},
+		},
+		{
+			name:          "Fail: name matches no file system",
+			params:        map[string]string{FileSystemName: "shared"},
+			expectLookup:  true,
+			expectErrCode: codes.InvalidArgument,
+		},
+		{
+			name:          "Fail: name matches several file systems",
+			params:        map[string]string{FileSystemName: "shared"},
+			matches:       []*cloud.FileSystem{{FileSystemId: fsId}, {FileSystemId: "fs-bcde2345"}},
+			expectLookup:  true,
+			expectErrCode: codes.InvalidArgument,
+		},
+		{
+			name:          "Fail: empty name",
+			params:        map[string]string{FileSystemName: " "},
+			expectErrCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := mocks.NewMockCloud(mockCtl)
+
+			driver := &Driver{
+				endpoint:     "endpoint",
+				cloud:        mockCloud,
+				gidAllocator: NewGidAllocator(),
+			}
+
+			params := map[string]string{
+				ProvisioningMode: "efs-ap",
+				DirectoryPerms:   "777",
+				Uid:              "1000",
+				Gid:              "1000",
+			}
+			for k, v := range tc.params {
+				params[k] = v
+			}
+			req := &csi.CreateVolumeRequest{
+				Name: volumeName,
+				VolumeCapabilities: []*csi.VolumeCapability{
+					{
+						AccessType: &csi.VolumeCapability_Mount{
+							Mount: &csi.VolumeCapability_MountVolume{},
+						},
+						AccessMode: &csi.VolumeCapability_AccessMode{
+							Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+						},
+					},
+				},
+				Parameters: params,
+			}
+
+			ctx := context.Background()
+			if tc.expectLookup {
+				mockCloud.EXPECT().FindFileSystemByTag(gomock.Eq(ctx), gomock.Eq(NameTagKey), gomock.Eq("shared")).Return(tc.matches, nil)
+			}
+			if tc.expectErrCode == codes.OK {
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fsId)).Return(&cloud.FileSystem{FileSystemId: fsId}, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(&cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId}, nil)
+			}
+
+			res, err := driver.CreateVolume(ctx, req)
+			if tc.expectErrCode != codes.OK {
+				if status.Code(err) != tc.expectErrCode {
+					t.Fatalf("Expected %v, got: %v", tc.expectErrCode, err)
+				}
+				mockCtl.Finish()
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateVolume failed: %v", err)
+			}
+			if expected := newAccessPointVolumeHandle(fsId, apId).encode(volumeIdFormatLegacy); res.Volume.VolumeId != expected {
+				t.Fatalf("Volume Id mismatched. Expected: %v, Actual: %v", expected, res.Volume.VolumeId)
+			}
+			mockCtl.Finish()
+		})
+	}
+}
+
+func TestNoMountTargets(t *testing.T) {
+	const (
+		fsId    = "fs-abcd1234"
+		apId    = "fsap-abcd1234xyz987"
+		roleArn = "arn:aws:iam::1234567890:role/EFSCrossAccountRole"
+	)
+
+	t.Run("Fail: CreateVolume for a cross account file system without mount targets", func(t *testing.T) {
+		mockCtl := gomock.NewController(t)
+		mockCloud := mocks.NewMockCloud(mockCtl)
+		driver := &Driver{
+			endpoint:     "endpoint",
+			cloud:        mockCloud,
+			gidAllocator: NewGidAllocator(),
+			roleClouds:   newRoleCloudCache(mockCloud),
+		}
+
+		req := &csi.CreateVolumeRequest{
+			Name: "volumeName",
+			VolumeCapabilities: []*csi.VolumeCapability{
+				{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+					},
+				},
+			},
+			Parameters: map[string]string{
+				ProvisioningMode: "efs-ap",
+				FsId:             fsId,
+				DirectoryPerms:   "777",
+				Uid:              "1000",
+				Gid:              "1000",
+			},
+			Secrets: map[string]string{RoleArn: roleArn},
+		}
+
+		ctx := context.Background()
+		mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(&cloud.FileSystem{FileSystemId: fsId}, nil)
+		mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(&cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId}, nil)
+		mockCloud.EXPECT().DescribeMountTargets(gomock.Eq(ctx), gomock.Eq(fsId), gomock.Eq("")).Return(nil, cloud.ErrNoMountTargets)
+
+		_, err := driver.CreateVolume(ctx, req)
+		if status.Code(err) != codes.FailedPrecondition {
+			t.Fatalf("Expected FailedPrecondition, got: %v", err)
+		}
+		mockCtl.Finish()
+	})
+
+	t.Run("Fail: DeleteVolume does not mount a cross account file system without mount targets", func(t *testing.T) {
+		mockCtl := gomock.NewController(t)
+		mockCloud := mocks.NewMockCloud(mockCtl)
+		mockMounter := mocks.NewMockMounter(mockCtl)
+		driver := &Driver{
+			endpoint:                 "endpoint",
+			cloud:                    mockCloud,
+			mounter:                  mockMounter,
+			gidAllocator:             NewGidAllocator(),
+			deleteAccessPointRootDir: true,
+			roleClouds:               newRoleCloudCache(mockCloud),
+		}
+
+		req := &csi.DeleteVolumeRequest{
+			VolumeId: fsId + "::" + apId,
+			Secrets:  map[string]string{RoleArn: roleArn},
+		}
+
+		ctx := context.Background()
+		accessPoint := &cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId, AccessPointRootDir: "/pvc-abcd1234"}
+		mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+		mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq(fsId), gomock.Eq("")).Return(nil, cloud.ErrNoMountTargets)
+		mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := driver.DeleteVolume(ctx, req)
+		if status.Code(err) != codes.FailedPrecondition {
+			t.Fatalf("Expected FailedPrecondition, got: %v", err)
+		}
+		mockCtl.Finish()
+	})
+}
+
+// fakeDialer connects to the NFS port of the reachable IP addresses and times out for any other address.
+func fakeDialer(reachable ...string) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		for _, ip := range reachable {
+			if address == net.JoinHostPort(ip, NfsPort) {
+				client, server := net.Pipe()
+				server.Close()
+				return client, nil
+			}
+		}
+		return nil, errors.New("i/o timeout")
+	}
+}
+
+func TestDescribeMountTarget(t *testing.T) {
+	const fsId = "fs-abcd1234"
+	mountTargets := func() []*cloud.MountTarget {
+		return []*cloud.MountTarget{
+			{MountTargetId: "fsmt-a", AZName: "us-east-1a", SubnetId: "subnet-a", IPAddress: "10.0.1.10"},
+			{MountTargetId: "fsmt-b", AZName: "us-east-1b", SubnetId: "subnet-b", IPAddress: "10.0.2.10"},
+			{MountTargetId: "fsmt-c", AZName: "us-east-1c", SubnetId: "subnet-c", IPAddress: "10.0.3.10"},
+		}
+	}
+	testCases := []struct {
+		name          string
+		azName        string
+		subnetId      string
+		reachable     []string
+		expectedMtId  string
+		expectErrCode codes.Code
+	}{
+		{
+			name:         "Success: falls back past unreachable mount targets",
+			reachable:    []string{"10.0.3.10"},
+			expectedMtId: "fsmt-c",
+		},
+		{
+			name:         "Success: prefers a reachable mount target in the requested az",
+			azName:       "us-east-1b",
+			reachable:    []string{"10.0.1.10", "10.0.2.10"},
+			expectedMtId: "fsmt-b",
+		},
+		{
+			name:         "Success: falls back to another az when the requested az is unreachable",
+			azName:       "us-east-1b",
+			reachable:    []string{"10.0.1.10"},
+			expectedMtId: "fsmt-a",
+		},
+		{
+			name:         "Success: uses the mount target in the requested subnet",
+			azName:       "us-east-1a",
+			subnetId:     "subnet-b",
+			reachable:    []string{"10.0.1.10", "10.0.2.10"},
+			expectedMtId: "fsmt-b",
+		},
+		{
+			name:          "Fail: no mount target is reachable",
+			expectErrCode: codes.FailedPrecondition,
+		},
+		{
+			name:          "Fail: the mount target in the requested subnet is unreachable",
+			subnetId:      "subnet-b",
+			reachable:     []string{"10.0.1.10"},
+			expectErrCode: codes.FailedPrecondition,
+		},
+		{
+			name:          "Fail: no mount target in the requested subnet",
+			subnetId:      "subnet-d",
+			reachable:     []string{"10.0.1.10"},
+			expectErrCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := mocks.NewMockCloud(mockCtl)
+			driver := &Driver{
+				cloud:             mockCloud,
+				probeMountTargets: true,
+				dialContext:       fakeDialer(tc.reachable...),
+			}
+
+			ctx := context.Background()
+			mockCloud.EXPECT().ListMountTargets(gomock.Eq(ctx), gomock.Eq(fsId)).Return(mountTargets(), nil)
+			mt, err := driver.describeMountTarget(ctx, mockCloud, "", "", fsId, tc.azName, tc.subnetId)
+			if tc.expectErrCode != codes.OK {
+				if status.Code(err) != tc.expectErrCode {
+					t.Fatalf("Expected %v, got: %v", tc.expectErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if mt.MountTargetId != tc.expectedMtId {
+				t.Fatalf("Mount target mismatched. Expected: %v, actual: %v", tc.expectedMtId, mt.MountTargetId)
+			}
+			mockCtl.Finish()
+		})
+	}
+
+	t.Run("Success: probing disabled describes the mount target", func(t *testing.T) {
+		mockCtl := gomock.NewController(t)
+		mockCloud := mocks.NewMockCloud(mockCtl)
+		driver := &Driver{cloud: mockCloud}
+
+		ctx := context.Background()
+		expected := &cloud.MountTarget{MountTargetId: "fsmt-a", IPAddress: "10.0.1.10"}
+		mockCloud.EXPECT().DescribeMountTargets(gomock.Eq(ctx), gomock.Eq(fsId), gomock.Eq("")).Return(expected, nil)
+		mt, err := driver.describeMountTarget(ctx, mockCloud, "", "", fsId, "", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if mt != expected {
+			t.Fatalf("Mount target mismatched. Expected: %v, actual: %v", expected, mt)
+		}
+		mockCtl.Finish()
+	})
+}
+
+func TestVerifyConnectivity(t *testing.T) {
+	const fsId = "fs-abcd1234"
+	mountTarget := &cloud.MountTarget{MountTargetId: "fsmt-a", AZName: "us-east-1a", IPAddress: "10.0.1.10"}
+
+	testCases := []struct {
+		name          string
+		mountTarget   *cloud.MountTarget
+		describeErr   error
+		reachable     []string
+		expectErrCode codes.Code
+	}{
+		{
+			name:        "Success: canary mount target is reachable",
+			mountTarget: mountTarget,
+			reachable:   []string{"10.0.1.10"},
+		},
+		{
+			name:          "Fail: canary mount target is unreachable",
+			mountTarget:   mountTarget,
+			expectErrCode: codes.FailedPrecondition,
+		},
+		{
+			name:          "Fail: canary file system has no mount target",
+			describeErr:   errors.New("Cannot find mount targets for file system fs-abcd1234"),
+			expectErrCode: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := mocks.NewMockCloud(mockCtl)
+			driver := &Driver{
+				cloud:              mockCloud,
+				canaryFileSystemId: fsId,
+				canaryAzName:       "us-east-1a",
+				connectivity:       newConnectivityCheck(time.Minute),
+				dialContext:        fakeDialer(tc.reachable...),
+			}
+
+			ctx := context.Background()
+			mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq(fsId), gomock.Eq("us-east-1a")).Return(tc.mountTarget, tc.describeErr).Times(2)
+			err := driver.verifyConnectivity(ctx)
+			if status.Code(err) != tc.expectErrCode {
+				t.Fatalf("Expected %v, got: %v", tc.expectErrCode, err)
+			}
+
+			_, err = driver.Probe(ctx, &csi.ProbeRequest{})
+			if status.Code(err) != tc.expectErrCode {
+				t.Fatalf("Expected Probe to return %v, got: %v", tc.expectErrCode, err)
+			}
+			mockCtl.Finish()
+		})
+	}
+}
+
+func TestProbeRechecksConnectivity(t *testing.T) {
+	const fsId = "fs-abcd1234"
+	mountTarget := &cloud.MountTarget{MountTargetId: "fsmt-a", AZName: "us-east-1a", IPAddress: "10.0.1.10"}
+
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	connectivity := newConnectivityCheck(time.Minute)
+	connectivity.now = func() time.Time { return now }
+	reachable := false
 	driver := &Driver{
-		endpoint: endpoint,
-		cloud:    mockCloud,
+		cloud:              mockCloud,
+		canaryFileSystemId: fsId,
+		connectivity:       connectivity,
+		dialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			if reachable {
+				return fakeDialer(mountTarget.IPAddress)(ctx, network, address)
+			}
+			return fakeDialer()(ctx, network, address)
+		},
 	}
 
 	ctx := context.Background()
-	_, err := driver.ControllerGetCapabilities(ctx, &csi.ControllerGetCapabilitiesRequest{})
-	if err != nil {
-		t.Fatalf("ControllerGetCapabilities failed: %v", err)
+	mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq(fsId), gomock.Eq("")).Return(mountTarget, nil).Times(2)
+	for _, step := range []struct {
+		advance       time.Duration
+		reachable     bool
+		expectErrCode codes.Code
+	}{
+		{expectErrCode: codes.FailedPrecondition},
+		// Within the interval the last result is reused, even though the mount target is now reachable
+		{advance: 30 * time.Second, reachable: true, expectErrCode: codes.FailedPrecondition},
+		{advance: 30 * time.Second, reachable: true},
+		{advance: 30 * time.Second},
+	} {
+		now = now.Add(step.advance)
+		reachable = step.reachable
+		_, err := driver.Probe(ctx, &csi.ProbeRequest{})
+		if status.Code(err) != step.expectErrCode {
+			t.Fatalf("Expected Probe to return %v after %v, got: %v", step.expectErrCode, step.advance, err)
+		}
+	}
+	mockCtl.Finish()
+
+	// Without a connectivity check the Probe always succeeds
+	if _, err := (&Driver{}).Probe(ctx, &csi.ProbeRequest{}); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+}
+
+func TestParseDirectoryPerms(t *testing.T) {
+	testCases := []struct {
+		value     string
+		expected  string
+		expectErr bool
+	}{
+		{value: "700", expected: "700"},
+		{value: "0700", expected: "700"},
+		{value: "755", expected: "755"},
+		{value: "2775", expected: "2775"},
+		{value: "garbage", expectErr: true},
+		{value: "789", expectErr: true},
+		{value: "17777", expectErr: true},
+		{value: "", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		perms, err := parseDirectoryPerms(tc.value)
+		if tc.expectErr {
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("parseDirectoryPerms(%q): expected InvalidArgument, got: %v", tc.value, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseDirectoryPerms(%q) failed: %v", tc.value, err)
+		} else if perms != tc.expected {
+			t.Errorf("parseDirectoryPerms(%q) = %q, expected %q", tc.value, perms, tc.expected)
+		}
+	}
+}
+
+func TestCheckFileSystemLifeCycleState(t *testing.T) {
+	testCases := []struct {
+		state        string
+		expectedCode codes.Code
+	}{
+		{state: "", expectedCode: codes.OK},
+		{state: efs.LifeCycleStateAvailable, expectedCode: codes.OK},
+		{state: efs.LifeCycleStateCreating, expectedCode: codes.Unavailable},
+		{state: efs.LifeCycleStateUpdating, expectedCode: codes.Unavailable},
+		{state: efs.LifeCycleStateDeleting, expectedCode: codes.FailedPrecondition},
+		{state: efs.LifeCycleStateDeleted, expectedCode: codes.FailedPrecondition},
+		{state: efs.LifeCycleStateError, expectedCode: codes.FailedPrecondition},
+	}
+
+	for _, tc := range testCases {
+		err := checkFileSystemLifeCycleState(&cloud.FileSystem{FileSystemId: "fs-abcd1234", LifeCycleState: tc.state})
+		if code := status.Code(err); code != tc.expectedCode {
+			t.Errorf("checkFileSystemLifeCycleState(%q): expected %v, got: %v", tc.state, tc.expectedCode, err)
+		}
+	}
+}
+
+func TestParseFileSystemArn(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected *fileSystemArn
+	}{
+		{
+			value:    "arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/fs-abcd1234",
+			expected: &fileSystemArn{partition: "aws", region: "us-east-1", accountId: "123456789012", fileSystemId: "fs-abcd1234"},
+		},
+		{
+			value:    "arn:aws-cn:elasticfilesystem:cn-north-1:123456789012:file-system/fs-0123456789abcdef0",
+			expected: &fileSystemArn{partition: "aws-cn", region: "cn-north-1", accountId: "123456789012", fileSystemId: "fs-0123456789abcdef0"},
+		},
+		{
+			value:    "arn:aws-us-gov:elasticfilesystem:us-gov-west-1:123456789012:file-system/fs-abcd1234",
+			expected: &fileSystemArn{partition: "aws-us-gov", region: "us-gov-west-1", accountId: "123456789012", fileSystemId: "fs-abcd1234"},
+		},
+		{value: ""},
+		{value: "fs-abcd1234"},
+		{value: "arn:aws:s3:us-east-1:123456789012:file-system/fs-abcd1234"},
+		{value: "arn:aws:elasticfilesystem:us-east-1:123456789012:access-point/fsap-abcd1234xyz987"},
+		{value: "arn:aws:elasticfilesystem:us-east-1:1234:file-system/fs-abcd1234"},
+		{value: "arn:aws:elasticfilesystem::123456789012:file-system/fs-abcd1234"},
+		{value: "arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/"},
+		{value: "arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/fs-abcd1234/extra"},
+	}
+
+	for _, tc := range testCases {
+		fsArn, err := parseFileSystemArn(tc.value)
+		if tc.expected == nil {
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("parseFileSystemArn(%q): expected InvalidArgument, got: %v, %v", tc.value, fsArn, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseFileSystemArn(%q) failed: %v", tc.value, err)
+		} else if !reflect.DeepEqual(fsArn, tc.expected) {
+			t.Errorf("parseFileSystemArn(%q): expected %+v, got %+v", tc.value, tc.expected, fsArn)
+		}
+	}
+}
+
+func TestParseAllowedDirectoryPerms(t *testing.T) {
+	testCases := []struct {
+		value     string
+		expected  map[string]bool
+		expectErr bool
+	}{
+		{value: "", expected: nil},
+		{value: "700", expected: map[string]bool{"700": true}},
+		{value: "0750, 700", expected: map[string]bool{"750": true, "700": true}},
+		{value: "700,garbage", expectErr: true},
+		{value: "700,", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		allowed, err := parseAllowedDirectoryPerms(tc.value)
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("parseAllowedDirectoryPerms(%q) did not fail", tc.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseAllowedDirectoryPerms(%q) failed: %v", tc.value, err)
+		} else if !reflect.DeepEqual(allowed, tc.expected) {
+			t.Errorf("parseAllowedDirectoryPerms(%q) = %v, expected %v", tc.value, allowed, tc.expected)
+		}
+	}
+}
+
+func TestIsWithinDir(t *testing.T) {
+	testCases := []struct {
+		dir      string
+		p        string
+		expected bool
+	}{
+		{dir: "/", p: "/", expected: true},
+		{dir: "/", p: "/pvc-1234", expected: true},
+		{dir: "/dynamic", p: "/dynamic", expected: true},
+		{dir: "/dynamic", p: "/dynamic/pvc-1234", expected: true},
+		{dir: "/dynamic/", p: "/dynamic/./a/../pvc-1234", expected: true},
+		{dir: "/dynamic", p: "/dynamic-other/pvc-1234", expected: false},
+		{dir: "/dynamic", p: "/dynamic/../pvc-1234", expected: false},
+		{dir: "/dynamic", p: "/etc", expected: false},
+		{dir: "/var/lib/csi/pv/fsap-1", p: "/var/lib/csi/pv/fsap-1/../../../etc", expected: false},
+	}
+
+	for _, tc := range testCases {
+		if actual := isWithinDir(tc.dir, tc.p); actual != tc.expected {
+			t.Errorf("isWithinDir(%q, %q) = %v, expected %v", tc.dir, tc.p, actual, tc.expected)
+		}
+	}
+}
+
+func TestSanitizeVolumeName(t *testing.T) {
+	testCases := []struct {
+		name         string
+		volName      string
+		mode         string
+		expectedName string
+		expectErr    bool
+	}{
+		{name: "Success: default keeps a plain name", volName: "pvc-1234", expectedName: "pvc-1234"},
+		{name: "Success: default keeps unicode", volName: "pvc-données", expectedName: "pvc-données"},
+		{name: "Fail: default rejects slashes", volName: "pvc/../etc", expectErr: true},
+		{name: "Fail: default rejects null bytes", volName: "pvc\x001234", expectErr: true},
+		{name: "Success: none keeps a plain name", volName: "pvc-1234", mode: "none", expectedName: "pvc-1234"},
+		{name: "Success: reject keeps a plain name", volName: "pvc_1234.a", mode: "reject", expectedName: "pvc_1234.a"},
+		{name: "Fail: reject rejects slashes", volName: "pvc/1234", mode: "reject", expectErr: true},
+		{name: "Fail: reject rejects null bytes", volName: "pvc\x001234", mode: "reject", expectErr: true},
+		{name: "Fail: reject rejects unicode", volName: "pvc-données", mode: "reject", expectErr: true},
+		{name: "Success: replace slashes", volName: "pvc/../etc", mode: "replace", expectedName: "pvc-..-etc"},
+		{name: "Success: replace null bytes", volName: "pvc\x001234", mode: "replace", expectedName: "pvc-1234"},
+		{name: "Success: replace unicode one rune at a time", volName: "pvc-données", mode: "replace", expectedName: "pvc-donn-es"},
+		{name: "Fail: unknown mode", volName: "pvc-1234", mode: "escape", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			name, err := sanitizeVolumeName(tc.volName, tc.mode)
+			if tc.expectErr {
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("sanitizeVolumeName failed: %v", err)
+			}
+			if name != tc.expectedName {
+				t.Fatalf("Name mismatched. Expected: %q, actual: %q", tc.expectedName, name)
+			}
+		})
+	}
+}
+
+func TestParseUidGid(t *testing.T) {
+	testCases := []struct {
+		name           string
+		secrets        map[string]string
+		volumeParams   map[string]string
+		driverDefaults map[string]int64
+		fallback       int64
+		expectedId     int64
+		expectErr      bool
+	}{
+		{name: "Success: not set", expectedId: -1},
+		{name: "Success: parameter", volumeParams: map[string]string{Gid: "1000"}, expectedId: 1000},
+		{name: "Success: secret", secrets: map[string]string{Gid: "2000"}, expectedId: 2000},
+		{name: "Success: secret takes precedence over parameter", secrets: map[string]string{Gid: "2000"}, volumeParams: map[string]string{Gid: "1000"}, expectedId: 2000},
+		{name: "Success: zero", volumeParams: map[string]string{Gid: "0"}, expectedId: 0},
+		{name: "Success: maximum", volumeParams: map[string]string{Gid: "4294967295"}, expectedId: 4294967295},
+		{name: "Success: other key is ignored", volumeParams: map[string]string{Uid: "1000"}, expectedId: -1},
+		{name: "Fail: not a number", volumeParams: map[string]string{Gid: "invalid"}, expectErr: true},
+		{name: "Fail: negative", volumeParams: map[string]string{Gid: "-5"}, expectErr: true},
+		{name: "Fail: above maximum", volumeParams: map[string]string{Gid: "4294967296"}, expectErr: true},
+		{name: "Fail: invalid secret is not masked by a valid parameter", secrets: map[string]string{Gid: "-1"}, volumeParams: map[string]string{Gid: "1000"}, expectErr: true},
+		{name: "Success: driver default", driverDefaults: map[string]int64{Gid: 3000}, expectedId: 3000},
+		{name: "Success: parameter takes precedence over driver default", volumeParams: map[string]string{Gid: "1000"}, driverDefaults: map[string]int64{Gid: 3000}, expectedId: 1000},
+		{name: "Success: secret takes precedence over driver default", secrets: map[string]string{Gid: "2000"}, driverDefaults: map[string]int64{Gid: 3000}, expectedId: 2000},
+		{name: "Success: driver default of another key is ignored", driverDefaults: map[string]int64{Uid: 3000}, fallback: 4000, expectedId: 4000},
+		{name: "Success: caller provided", fallback: 4000, expectedId: 4000},
+		{name: "Success: driver default takes precedence over caller provided", driverDefaults: map[string]int64{Gid: 3000}, fallback: 4000, expectedId: 3000},
+		{name: "Success: parameter takes precedence over caller provided", volumeParams: map[string]string{Gid: "1000"}, fallback: 4000, expectedId: 1000},
+		{name: "Fail: invalid parameter is not masked by a driver default", volumeParams: map[string]string{Gid: "invalid"}, driverDefaults: map[string]int64{Gid: 3000}, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fallback := tc.fallback
+			if fallback == 0 {
+				fallback = -1
+			}
+			id, err := parseUidGid(Gid, tc.secrets, tc.volumeParams, tc.driverDefaults, fallback)
+			if tc.expectErr {
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseUidGid failed: %v", err)
+			}
+			if id != tc.expectedId {
+				t.Fatalf("Id mismatched. Expected: %v, actual: %v", tc.expectedId, id)
+			}
+		})
+	}
+}
+
+func TestGetTags(t *testing.T) {
+	numberedTags := func(prefix string, n int) map[string]string {
+		tags := map[string]string{}
+		for i := 0; i < n; i++ {
+			tags[fmt.Sprintf("%s-%02d", prefix, i)] = prefix
+		}
+		return tags
+	}
+	merge := func(sources ...map[string]string) map[string]string {
+		tags := map[string]string{}
+		for _, source := range sources {
+			for k, v := range source {
+				tags[k] = v
+			}
+		}
+		return tags
+	}
+	ownership := map[string]string{DefaultTagKey: DefaultTagValue}
+
+	testCases := []struct {
+		name          string
+		userTags      map[string]string
+		templateTags  map[string]string
+		inheritedTags map[string]string
+		defaultTags   map[string]string
+		expectedTags  map[string]string
+		expectedDrop  []string
+	}{
+		{
+			name:          "Success: higher precedence source wins on the same key",
+			userTags:      map[string]string{"Team": "user"},
+			templateTags:  map[string]string{"Team": "template", "Name": "data-tenant-a"},
+			inheritedTags: map[string]string{"Team": "inherited", "Name": "inherited", "CostCenter": "1234"},
+			defaultTags:   map[string]string{DefaultTagKey: DefaultTagValue},
+			expectedTags:  map[string]string{"Team": "user", "Name": "data-tenant-a", "CostCenter": "1234", DefaultTagKey: DefaultTagValue},
+		},
+		{
+			name:          "Success: default tags are dropped first",
+			userTags:      numberedTags("user", 20),
+			templateTags:  numberedTags("template", 10),
+			inheritedTags: numberedTags("inherited", 18),
+			defaultTags:   map[string]string{DefaultTagKey: DefaultTagValue, AzNameTagKey: "us-east-1a", PvcNamespaceTagKey: "tenant-a"},
+			expectedTags:  merge(numberedTags("user", 20), numberedTags("template", 10), numberedTags("inherited", 18), ownership, map[string]string{AzNameTagKey: "us-east-1a"}),
+			expectedDrop:  []string{PvcNamespaceTagKey},
+		},
+		{
+			name:          "Success: inherited tags are dropped before template tags",
+			userTags:      numberedTags("user", 20),
+			templateTags:  numberedTags("template", 27),
+			inheritedTags: numberedTags("inherited", 4),
+			defaultTags:   map[string]string{DefaultTagKey: DefaultTagValue, PvcNamespaceTagKey: "tenant-a"},
+			expectedTags:  merge(numberedTags("user", 20), numberedTags("template", 27), numberedTags("inherited", 2), ownership),
+			expectedDrop:  []string{"inherited-02", "inherited-03", PvcNamespaceTagKey},
+		},
+		{
+			name:          "Success: template tags are dropped before user tags",
+			userTags:      numberedTags("user", 47),
+			templateTags:  numberedTags("template", 3),
+			inheritedTags: numberedTags("inherited", 1),
+			defaultTags:   map[string]string{DefaultTagKey: DefaultTagValue},
+			expectedTags:  merge(numberedTags("user", 47), numberedTags("template", 2), ownership),
+			expectedDrop:  []string{"template-02", "inherited-00"},
+		},
+		{
+			name:         "Success: user tags are dropped only to keep the ownership tag",
+			userTags:     numberedTags("user", MaxTagsPerResource),
+			defaultTags:  map[string]string{DefaultTagKey: DefaultTagValue},
+			expectedTags: merge(numberedTags("user", MaxTagsPerResource-1), ownership),
+			expectedDrop: []string{fmt.Sprintf("user-%02d", MaxTagsPerResource-1)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tags, dropped, err := getTags(tc.userTags, tc.templateTags, tc.inheritedTags, tc.defaultTags)
+			if err != nil {
+				t.Fatalf("getTags failed: %v", err)
+			}
+			if !reflect.DeepEqual(tags, tc.expectedTags) {
+				t.Fatalf("Tags mismatched. Expected: %v, actual: %v", tc.expectedTags, tags)
+			}
+			if !reflect.DeepEqual(dropped, tc.expectedDrop) {
+				t.Fatalf("Dropped tags mismatched. Expected: %v, actual: %v", tc.expectedDrop, dropped)
+			}
+		})
+	}
+}
+
+func TestGetTagsReserved(t *testing.T) {
+	testCases := []struct {
+		name         string
+		userTags     map[string]string
+		templateTags map[string]string
+		defaultTags  map[string]string
+	}{
+		{
+			name:        "Fail: user tags override the ownership tag",
+			userTags:    map[string]string{DefaultTagKey: "false", "Team": "storage"},
+			defaultTags: map[string]string{DefaultTagKey: DefaultTagValue},
+		},
+		{
+			name:        "Fail: user tags use the driver's tag prefix",
+			userTags:    map[string]string{PvcNamespaceTagKey: "tenant-b", "Team": "storage"},
+			defaultTags: map[string]string{DefaultTagKey: DefaultTagValue, PvcNamespaceTagKey: "tenant-a"},
+		},
+		{
+			name:         "Fail: template tags use the driver's tag prefix",
+			templateTags: map[string]string{PvcNamespaceTagKey: "tenant-b"},
+			defaultTags:  map[string]string{DefaultTagKey: DefaultTagValue, PvcNamespaceTagKey: "tenant-a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := getTags(tc.userTags, tc.templateTags, nil, tc.defaultTags)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("Expected InvalidArgument, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetTagsLength(t *testing.T) {
+	testCases := []struct {
+		name        string
+		userTags    map[string]string
+		expectedErr []string
+	}{
+		{
+			name:     "Success: key and value at the limit",
+			userTags: map[string]string{strings.Repeat("k", MaxTagKeyLength): strings.Repeat("v", MaxTagValueLength)},
+		},
+		{
+			name:     "Success: length is counted in characters",
+			userTags: map[string]string{"Team": strings.Repeat("é", MaxTagValueLength)},
+		},
+		{
+			name:        "Fail: key over the limit",
+			userTags:    map[string]string{strings.Repeat("k", MaxTagKeyLength+1): "value", "Team": "storage"},
+			expectedErr: []string{strings.Repeat("k", MaxTagKeyLength+1)},
+		},
+		{
+			name:        "Fail: values over the limit",
+			userTags:    map[string]string{"Team": strings.Repeat("v", MaxTagValueLength+1), "Owner": strings.Repeat("v", MaxTagValueLength+1), "CostCenter": "1234"},
+			expectedErr: []string{"Owner", "Team"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := getTags(tc.userTags, nil, nil, map[string]string{DefaultTagKey: DefaultTagValue})
+			if tc.expectedErr == nil {
+				if err != nil {
+					t.Fatalf("getTags failed: %v", err)
+				}
+				return
+			}
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("Expected InvalidArgument, got: %v", err)
+			}
+			if !strings.Contains(err.Error(), fmt.Sprint(tc.expectedErr)) {
+				t.Fatalf("Expected the tags over the limit %v to be listed, got: %v", tc.expectedErr, err)
+			}
+			if strings.Contains(err.Error(), "CostCenter") {
+				t.Fatalf("Expected only the tags over the limit to be listed, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestExpandTagTemplates(t *testing.T) {
+	volumeParams := map[string]string{
+		PvcName:      "my-pvc",
+		PvcNamespace: "my-namespace",
+		PvName:       "pv-1234",
+	}
+
+	testCases := []struct {
+		name         string
+		tags         map[string]string
+		volumeParams map[string]string
+		expectedTags map[string]string
+		expectErr    bool
+	}{
+		{
+			name:         "Success: literal values are left untouched",
+			tags:         map[string]string{"Team": "storage", "Braces": "a}b"},
+			volumeParams: volumeParams,
+			expectedTags: map[string]string{"Team": "storage", "Braces": "a}b"},
+		},
+		{
+			name:         "Success: all fields are expanded",
+			tags:         map[string]string{"Name": "{{ .PVCName }}-{{ .PVCNamespace }}", "Volume": "{{.PVName}}"},
+			volumeParams: volumeParams,
+			expectedTags: map[string]string{"Name": "my-pvc-my-namespace", "Volume": "pv-1234"},
+		},
+		{
+			name:         "Fail: referenced field is missing from the volume parameters",
+			tags:         map[string]string{"Name": "{{ .PVCName }}"},
+			volumeParams: map[string]string{},
+			expectErr:    true,
+		},
+		{
+			name:         "Fail: unknown field",
+			tags:         map[string]string{"Name": "{{ .StorageClass }}"},
+			volumeParams: volumeParams,
+			expectErr:    true,
+		},
+		{
+			name:         "Fail: malformed template",
+			tags:         map[string]string{"Name": "{{ .PVCName"},
+			volumeParams: volumeParams,
+			expectErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tags, err := expandTagTemplates(tc.tags, tc.volumeParams)
+			if tc.expectErr {
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Expected InvalidArgument, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tags, tc.expectedTags) {
+				t.Fatalf("Tags mismatched. Expected: %v, actual: %v", tc.expectedTags, tags)
+			}
+		})
 	}
 }
 
@@ -3144,3 +8596,350 @@ func verifyPathWhenUUIDIncluded(pathToVerify string, expectedPathWithoutUUID str
 	_, err := uuid.Parse(matches[2])
 	return err == nil && doesPathMatchWithUuid
 }
+
+func TestDeleteVolumeMountTargetCache(t *testing.T) {
+	const (
+		fsId = "fs-abcd1234"
+		apId = "fsap-abcd1234xyz987"
+	)
+	mountTarget := &cloud.MountTarget{MountTargetId: "fsmt-a", AZName: "us-east-1a", IPAddress: "10.0.1.10"}
+
+	testCases := []struct {
+		name             string
+		azName           string
+		subnetId         string
+		mountErr         error
+		expectedDescribe int
+	}{
+		{
+			name:             "Success: second delete within the TTL reuses the mount target",
+			expectedDescribe: 1,
+		},
+		{
+			name:             "Fail: mount failure invalidates the cached mount target",
+			mountErr:         errors.New("connection timed out"),
+			expectedDescribe: 2,
+		},
+		{
+			name:             "Fail: mount failure invalidates the cached mount target of the access point's availability zone",
+			azName:           "us-east-1a",
+			mountErr:         errors.New("connection timed out"),
+			expectedDescribe: 2,
+		},
+		{
+			name:             "Success: second delete within the TTL reuses the mount target of the access point's subnet",
+			azName:           "us-east-1a",
+			subnetId:         "subnet-a",
+			expectedDescribe: 1,
+		},
+		{
+			name:             "Fail: mount failure invalidates the cached mount target of the access point's subnet",
+			subnetId:         "subnet-a",
+			mountErr:         errors.New("connection timed out"),
+			expectedDescribe: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := mocks.NewMockCloud(mockCtl)
+			mockMounter := mocks.NewMockMounter(mockCtl)
+
+			driver := &Driver{
+				endpoint:                 "endpoint",
+				cloud:                    mockCloud,
+				mounter:                  mockMounter,
+				gidAllocator:             NewGidAllocator(),
+				deleteAccessPointRootDir: true,
+				roleClouds:               newRoleCloudCache(mockCloud),
+				mountTargets:             newMountTargetCache(time.Minute),
+			}
+
+			req := &csi.DeleteVolumeRequest{
+				VolumeId: fsId + "::" + apId,
+				Secrets:  map[string]string{RoleArn: "arn:aws:iam::1234567890:role/EFSCrossAccountRole"},
+			}
+
+			ctx := context.Background()
+			accessPoint := &cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId, AccessPointRootDir: "/pvc-abcd1234"}
+			accessPoint.Tags = map[string]string{}
+			if tc.azName != "" {
+				accessPoint.Tags[AzNameTagKey] = tc.azName
+			}
+			if tc.subnetId != "" {
+				accessPoint.Tags[SubnetIdTagKey] = tc.subnetId
+			}
+			mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil).Times(2)
+			if tc.subnetId != "" {
+				mockCloud.EXPECT().DescribeMountTargetInSubnet(gomock.Any(), gomock.Eq(fsId), gomock.Eq(tc.subnetId)).Return(mountTarget, nil).Times(tc.expectedDescribe)
+			} else {
+				mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq(fsId), gomock.Eq(tc.azName)).Return(mountTarget, nil).Times(tc.expectedDescribe)
+			}
+			mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil).Times(2)
+			mockMounter.EXPECT().Mount(gomock.Eq(fsId), gomock.Any(), gomock.Eq("efs"), gomock.Eq([]string{"tls", "iam", MountTargetIp + "=" + mountTarget.IPAddress})).Return(tc.mountErr).Times(2)
+			if tc.mountErr == nil {
+				mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil).Times(2)
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil).Times(2)
+			}
+
+			for i := 0; i < 2; i++ {
+				_, err := driver.DeleteVolume(ctx, req)
+				if tc.mountErr == nil && err != nil {
+					t.Fatalf("DeleteVolume failed: %v", err)
+				}
+				if tc.mountErr != nil && status.Code(err) != codes.Internal {
+					t.Fatalf("Expected Internal, got: %v", err)
+				}
+			}
+			mockCtl.Finish()
+		})
+	}
+}
+
+func TestDeleteVolumeInAnotherRegion(t *testing.T) {
+	const (
+		fsId = "fs-abcd1234"
+		apId = "fsap-abcd1234xyz987"
+	)
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+	regionalCloud := mocks.NewMockCloud(mockCtl)
+	mockMounter := mocks.NewMockMounter(mockCtl)
+
+	var regions []string
+	roleClouds := newCloudCache(time.Minute, cloud.EndpointOptions{}, nil)
+	roleClouds.newCloud = func(roleArn, tokenFile string, sessionDuration time.Duration, region string) (cloud.Cloud, error) {
+		regions = append(regions, region)
+		return regionalCloud, nil
+	}
+	driver := &Driver{
+		endpoint:                 "endpoint",
+		cloud:                    mockCloud,
+		mounter:                  mockMounter,
+		gidAllocator:             NewGidAllocator(),
+		deleteAccessPointRootDir: true,
+		roleClouds:               roleClouds,
+	}
+
+	ctx := context.Background()
+	accessPoint := &cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId, AccessPointRootDir: "/pvc-abcd1234"}
+	regionalCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+	mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+	mockMounter.EXPECT().Mount(gomock.Eq(fsId), gomock.Any(), gomock.Eq("efs"), gomock.Eq([]string{"tls", "iam", "region=eu-west-1"})).Return(nil)
+	mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
+	regionalCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+
+	_, err := driver.DeleteVolume(ctx, &csi.DeleteVolumeRequest{VolumeId: "v1:accesspoint:eu-west-1/" + fsId + ":" + apId})
+	if err != nil {
+		t.Fatalf("DeleteVolume failed: %v", err)
+	}
+	if !reflect.DeepEqual(regions, []string{"eu-west-1"}) {
+		t.Fatalf("Expected a client for eu-west-1, got clients for %v", regions)
+	}
+	mockCtl.Finish()
+}
+
+func TestDeleteVolumeCleansUpTempMount(t *testing.T) {
+	const (
+		fsId = "fs-abcd1234"
+		apId = "fsap-abcd1234xyz987"
+	)
+
+	testCases := []struct {
+		name             string
+		rootDirIsFile    bool
+		unmountErr       error
+		expectedMessages []string
+		expectMountGone  bool
+	}{
+		{
+			name:             "Fail: root directory cannot be emptied",
+			rootDirIsFile:    true,
+			expectedMessages: []string{"Could not delete access point root directory"},
+			expectMountGone:  true,
+		},
+		{
+			name:             "Fail: unmount fails",
+			unmountErr:       errors.New("device is busy"),
+			expectedMessages: []string{"Could not unmount"},
+		},
+		{
+			name:             "Fail: root directory cannot be emptied and unmount fails",
+			rootDirIsFile:    true,
+			unmountErr:       errors.New("device is busy"),
+			expectedMessages: []string{"Could not delete access point root directory", "Could not unmount", "device is busy"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := mocks.NewMockCloud(mockCtl)
+			mockMounter := mocks.NewMockMounter(mockCtl)
+
+			prefix := t.TempDir()
+			driver := &Driver{
+				endpoint:          "endpoint",
+				cloud:             mockCloud,
+				mounter:           mockMounter,
+				gidAllocator:      NewGidAllocator(),
+				retainAccessPoint: true,
+				tempMountPrefix:   prefix,
+			}
+
+			req := &csi.DeleteVolumeRequest{
+				VolumeId: fsId + "::" + apId,
+			}
+
+			ctx := context.Background()
+			accessPoint := &cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId, AccessPointRootDir: "/pvc-1234"}
+			mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+			mockMounter.EXPECT().MakeDir(gomock.Any()).DoAndReturn(func(target string) error {
+				return os.Mkdir(target, 0755)
+			})
+			// Stand in for the file system, a root directory that is a file cannot be emptied
+			mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(source, target, fstype string, options []string) error {
+					if tc.rootDirIsFile {
+						return os.WriteFile(path.Join(target, "pvc-1234"), nil, 0644)
+					}
+					return os.Mkdir(path.Join(target, "pvc-1234"), 0755)
+				})
+			mockMounter.EXPECT().Unmount(gomock.Any()).DoAndReturn(func(target string) error {
+				if tc.unmountErr != nil {
+					return tc.unmountErr
+				}
+				return os.RemoveAll(path.Join(target, "pvc-1234"))
+			})
+
+			_, err := driver.DeleteVolume(ctx, req)
+			if status.Code(err) != codes.Internal {
+				t.Fatalf("Expected Internal, got: %v", err)
+			}
+			for _, message := range tc.expectedMessages {
+				if !strings.Contains(status.Convert(err).Message(), message) {
+					t.Fatalf("Expected error %q, got: %v", message, err)
+				}
+			}
+			entries, err := os.ReadDir(prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.expectMountGone && len(entries) != 0 {
+				t.Fatalf("Expected the temporary mount point to be removed, got: %v", entries)
+			}
+			if !tc.expectMountGone && len(entries) != 1 {
+				t.Fatalf("Expected the mount point that is still mounted to be left in place, got: %v", entries)
+			}
+			mockCtl.Finish()
+		})
+	}
+}
+
+func TestDeleteVolumeConcurrentDuplicates(t *testing.T) {
+	const (
+		fsId = "fs-abcd1234"
+		apId = "fsap-abcd1234xyz987"
+	)
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+	mockMounter := mocks.NewMockMounter(mockCtl)
+	driver := &Driver{
+		endpoint:                 "endpoint",
+		cloud:                    mockCloud,
+		mounter:                  mockMounter,
+		gidAllocator:             NewGidAllocator(),
+		deleteAccessPointRootDir: true,
+		tempMountPrefix:          t.TempDir(),
+	}
+
+	entered, release := make(chan struct{}), make(chan struct{})
+	accessPoint := &cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId, AccessPointRootDir: "/pvc-abcd1234"}
+	mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).DoAndReturn(func(ctx context.Context, accessPointId string) (*cloud.AccessPoint, error) {
+		close(entered)
+		<-release
+		return accessPoint, nil
+	}).Times(1)
+	mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil).Times(1)
+	mockMounter.EXPECT().Mount(gomock.Eq(fsId), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil).Times(1)
+	mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil).Times(1)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	deleteVolume := func(i int) {
+		defer wg.Done()
+		_, errs[i] = driver.DeleteVolume(context.Background(), &csi.DeleteVolumeRequest{VolumeId: fsId + "::" + apId})
+	}
+	wg.Add(2)
+	go deleteVolume(0)
+	<-entered
+	// The second delete arrives while the first is still describing the access point
+	go deleteVolume(1)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("DeleteVolume failed: %v", err)
+		}
+	}
+	mockCtl.Finish()
+}
+
+func TestDeleteVolumeOutlivesTheCallerThatStartedIt(t *testing.T) {
+	const (
+		fsId = "fs-abcd1234"
+		apId = "fsap-abcd1234xyz987"
+	)
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+	mockMounter := mocks.NewMockMounter(mockCtl)
+	driver := &Driver{
+		endpoint:      "endpoint",
+		cloud:         mockCloud,
+		mounter:       mockMounter,
+		gidAllocator:  NewGidAllocator(),
+		deleteTimeout: time.Minute,
+	}
+
+	entered, release := make(chan context.Context, 1), make(chan struct{})
+	mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).DoAndReturn(func(ctx context.Context, accessPointId string) error {
+		entered <- ctx
+		<-release
+		return ctx.Err()
+	}).Times(1)
+
+	req := &csi.DeleteVolumeRequest{VolumeId: fsId + "::" + apId}
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErr, secondErr := make(chan error, 1), make(chan error, 1)
+	go func() {
+		_, err := driver.DeleteVolume(firstCtx, req)
+		firstErr <- err
+	}()
+	deleteCtx := <-entered
+	go func() {
+		_, err := driver.DeleteVolume(context.Background(), req)
+		secondErr <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// The first caller gives up, but the delete it started keeps running for the second one
+	cancel()
+	if err := <-firstErr; status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("Expected DeadlineExceeded for the cancelled caller, got: %v", err)
+	}
+	if deleteCtx.Err() != nil {
+		t.Fatalf("Delete was cancelled with the caller that started it: %v", deleteCtx.Err())
+	}
+	if _, ok := deleteCtx.Deadline(); !ok {
+		t.Fatal("Delete is not bounded by the driver's timeout")
+	}
+	close(release)
+	if err := <-secondErr; err != nil {
+		t.Fatalf("DeleteVolume failed: %v", err)
+	}
+	mockCtl.Finish()
+}
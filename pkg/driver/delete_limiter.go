@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"sync"
+	"time"
+)
+
+// deleteLimiter enforces a minimum interval between DeleteVolume attempts for the same volume, so a failing
+// cleanup is not retried, and the file system mounted again, as often as the external-provisioner asks.
+type deleteLimiter struct {
+	interval time.Duration
+	now      func() time.Time
+	mu       sync.Mutex
+	attempts map[string]time.Time
+}
+
+func newDeleteLimiter(interval time.Duration) *deleteLimiter {
+	return &deleteLimiter{
+		interval: interval,
+		now:      time.Now,
+		attempts: make(map[string]time.Time),
+	}
+}
+
+// allow records an attempt to delete volId and returns how long the caller has to wait before it may go ahead,
+// 0 if it may go ahead now. A nil limiter, or an interval of 0, always allows it.
+func (l *deleteLimiter) allow(volId string) time.Duration {
+	if l == nil || l.interval <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	for id, last := range l.attempts {
+		if now.Sub(last) >= l.interval {
+			delete(l.attempts, id)
+		}
+	}
+	if last, ok := l.attempts[volId]; ok {
+		return l.interval - now.Sub(last)
+	}
+	l.attempts[volId] = now
+	return 0
+}
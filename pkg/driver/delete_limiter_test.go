@@ -0,0 +1,53 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeleteLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := newDeleteLimiter(time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	if wait := limiter.allow("fs-abcd1234::fsap-abcd1234"); wait != 0 {
+		t.Fatalf("Expected first attempt to be allowed, got wait %v", wait)
+	}
+	now = now.Add(10 * time.Second)
+	if wait := limiter.allow("fs-abcd1234::fsap-abcd1234"); wait != 50*time.Second {
+		t.Fatalf("Expected repeated attempt to wait 50s, got %v", wait)
+	}
+	if wait := limiter.allow("fs-abcd1234::fsap-efgh5678"); wait != 0 {
+		t.Fatalf("Expected attempt for another volume to be allowed, got wait %v", wait)
+	}
+	now = now.Add(time.Minute)
+	if wait := limiter.allow("fs-abcd1234::fsap-abcd1234"); wait != 0 {
+		t.Fatalf("Expected attempt after the interval to be allowed, got wait %v", wait)
+	}
+}
+
+func TestDeleteLimiterDisabled(t *testing.T) {
+	for _, limiter := range []*deleteLimiter{nil, newDeleteLimiter(0)} {
+		for i := 0; i < 3; i++ {
+			if wait := limiter.allow("fs-abcd1234::fsap-abcd1234"); wait != 0 {
+				t.Fatalf("Expected every attempt to be allowed, got wait %v", wait)
+			}
+		}
+	}
+}
@@ -18,11 +18,20 @@ package driver
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
+	"golang.org/x/sync/singleflight"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 
 	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/cloud"
@@ -47,15 +56,114 @@ type Driver struct {
 	volStatter               VolStatter
 	gidAllocator             GidAllocator
 	deleteAccessPointRootDir bool
+	rootDirCleanupBestEffort bool
+	plainNfsInternalMounts   bool
+	retainAccessPoint        bool
+	rootDirDeleteWorkers     int
+	accessPointLimit         int
+	probeMountTargets        bool
+	cleanupOnStartup         bool
+	tagStorageClass          bool
+	tempMountPrefix          string
+	throttleRetries          int
+	throttleRetryDelay       time.Duration
+	dialContext              func(ctx context.Context, network, address string) (net.Conn, error)
+	defaultProvisioningMode  string
+	volumeIdFormat           string
+	defaultIds               map[string]int64
+	canaryFileSystemId       string
+	canaryAzName             string
+	connectivity             *connectivityCheck
+	endpointOpts             cloud.EndpointOptions
+	roleClouds               *cloudCache
+	mountTargets             *mountTargetCache
+	deleteLimiter            *deleteLimiter
+	deleteCalls              singleflight.Group
+	deleteTimeout            time.Duration
+	fsMountOptions           map[string][]string
+	allowedDirectoryPerms    map[string]bool
 	tags                     map[string]string
+	tracer                   Tracer
+	metrics                  *provisioningMetrics
+	metricsAddress           string
+	kubeClient               kubernetes.Interface
+	recorder                 record.EventRecorder
 }
 
-func NewDriver(endpoint, efsUtilsCfgPath, efsUtilsStaticFilesPath, tags string, volMetricsOptIn bool, volMetricsRefreshPeriod float64, volMetricsFsRateLimit int, deleteAccessPointRootDir bool) *Driver {
-	cloud, err := cloud.NewCloud()
+func NewDriver(endpoint, efsUtilsCfgPath, efsUtilsStaticFilesPath, tags string, volMetricsOptIn bool, volMetricsRefreshPeriod float64, volMetricsFsRateLimit int, deleteAccessPointRootDir, rootDirCleanupBestEffort, retainAccessPoint, plainNfsInternalMounts, provisioningEvents, tagStorageClass, probeMountTargets, cleanupOnStartup, phaseMetrics, useFips, verifyEfsConnectivity bool, createAccessPointRetries, rootDirDeleteWorkers, accessPointLimit int, defaultUid, defaultGid int64, roleCloudCacheTTL, mountTargetCacheTTL, deleteRetryInterval, deleteVolumeTimeout, connectivityInterval time.Duration, tempMountPrefix, allowedDirectoryPerms, defaultProvisioningMode, volumeIdFormat, region, efsEndpoint, canaryFileSystemId, canaryAzName, xrayDaemonAddress, metricsAddress, mountOptionsConfig string) *Driver {
+	var kubeClient kubernetes.Interface
+	var recorder record.EventRecorder
+	if provisioningEvents || tagStorageClass {
+		client, err := cloud.DefaultKubernetesAPIClient()
+		if err != nil {
+			klog.Fatalln(err)
+		}
+		kubeClient = client
+		if provisioningEvents {
+			recorder = newEventRecorder(client)
+		}
+	}
+
+	endpointOpts := cloud.EndpointOptions{Region: region, EfsEndpoint: efsEndpoint, UseFIPS: useFips}
+	cloud, err := cloud.NewCloud(endpointOpts)
+	if err != nil {
+		klog.Fatalln(err)
+	}
+
+	var tracer Tracer
+	if xrayDaemonAddress != "" {
+		tracer, err = NewXrayTracer(xrayDaemonAddress)
+		if err != nil {
+			klog.Fatalln(err)
+		}
+		klog.Infof("Sending controller traces to the X-Ray daemon at %v", xrayDaemonAddress)
+	}
+
+	fsMountOptions, err := loadFsMountOptions(mountOptionsConfig)
 	if err != nil {
 		klog.Fatalln(err)
 	}
 
+	allowedPerms, err := parseAllowedDirectoryPerms(allowedDirectoryPerms)
+	if err != nil {
+		klog.Fatalln(err)
+	}
+
+	if defaultProvisioningMode != "" {
+		if err := checkProvisioningMode(defaultProvisioningMode); err != nil {
+			klog.Fatalln(err)
+		}
+	}
+
+	if err := checkVolumeIdFormat(volumeIdFormat); err != nil {
+		klog.Fatalln(err)
+	}
+
+	// The uid and gid of access points whose storage class and secret set neither
+	defaultIds := map[string]int64{}
+	for key, id := range map[string]int64{Uid: defaultUid, Gid: defaultGid} {
+		if id < -1 || id > MaxPosixId {
+			klog.Fatalf("Default %v must be between 0 and %d, or -1 to leave it unset", key, int64(MaxPosixId))
+		}
+		if id != -1 {
+			defaultIds[key] = id
+		}
+	}
+
+	var connectivity *connectivityCheck
+	if !verifyEfsConnectivity {
+		canaryFileSystemId, canaryAzName = "", ""
+	} else if canaryFileSystemId == "" {
+		klog.Fatalln("A canary file system ID is required to verify EFS connectivity")
+	} else {
+		connectivity = newConnectivityCheck(connectivityInterval)
+	}
+
+	var metrics *provisioningMetrics
+	if metricsAddress != "" {
+		metrics = newProvisioningMetrics(phaseMetrics)
+	}
+
 	nodeCaps := SetNodeCapOptInFeatures(volMetricsOptIn)
 	watchdog := newExecWatchdog(efsUtilsCfgPath, efsUtilsStaticFilesPath, "amazon-efs-mount-watchdog")
 	return &Driver{
@@ -69,9 +177,38 @@ func NewDriver(endpoint, efsUtilsCfgPath, efsUtilsStaticFilesPath, tags string,
 		volMetricsOptIn:          volMetricsOptIn,
 		volMetricsRefreshPeriod:  volMetricsRefreshPeriod,
 		volMetricsFsRateLimit:    volMetricsFsRateLimit,
-		gidAllocator:             NewGidAllocator(cloud),
+		gidAllocator:             NewGidAllocator(),
 		deleteAccessPointRootDir: deleteAccessPointRootDir,
+		rootDirCleanupBestEffort: rootDirCleanupBestEffort,
+		plainNfsInternalMounts:   plainNfsInternalMounts,
+		retainAccessPoint:        retainAccessPoint,
+		rootDirDeleteWorkers:     rootDirDeleteWorkers,
+		accessPointLimit:         accessPointLimit,
+		probeMountTargets:        probeMountTargets,
+		cleanupOnStartup:         cleanupOnStartup,
+		tagStorageClass:          tagStorageClass,
+		tempMountPrefix:          tempMountPrefix,
+		throttleRetries:          createAccessPointRetries,
+		throttleRetryDelay:       ThrottleRetryDelay,
+		defaultProvisioningMode:  defaultProvisioningMode,
+		volumeIdFormat:           volumeIdFormat,
+		defaultIds:               defaultIds,
+		canaryFileSystemId:       canaryFileSystemId,
+		canaryAzName:             canaryAzName,
+		connectivity:             connectivity,
+		endpointOpts:             endpointOpts,
+		roleClouds:               newCloudCache(roleCloudCacheTTL, endpointOpts, metrics),
+		mountTargets:             newMountTargetCache(mountTargetCacheTTL),
+		deleteLimiter:            newDeleteLimiter(deleteRetryInterval),
+		deleteTimeout:            deleteVolumeTimeout,
+		fsMountOptions:           fsMountOptions,
+		allowedDirectoryPerms:    allowedPerms,
 		tags:                     parseTagsFromStr(strings.TrimSpace(tags)),
+		tracer:                   tracer,
+		metrics:                  metrics,
+		metricsAddress:           metricsAddress,
+		kubeClient:               kubeClient,
+		recorder:                 recorder,
 	}
 }
 
@@ -87,6 +224,11 @@ func SetNodeCapOptInFeatures(volMetricsOptIn bool) []csi.NodeServiceCapability_R
 }
 
 func (d *Driver) Run() error {
+	// Fail at startup rather than with every mount of the efs type
+	if !d.mounter.IsEfsAvailable() {
+		return fmt.Errorf("efs-utils mount helper %v was not found, install efs-utils to mount EFS file systems", efsMountHelper)
+	}
+
 	scheme, addr, err := util.ParseEndpoint(d.endpoint)
 	if err != nil {
 		return err
@@ -115,11 +257,29 @@ func (d *Driver) Run() error {
 	klog.Info("Registering Controller Server")
 	csi.RegisterControllerServer(d.srv, d)
 
+	if summary, err := json.Marshal(d.configSummary()); err == nil {
+		klog.Infof("Driver configuration: %s", summary)
+	}
+
+	if d.connectivity != nil {
+		klog.Infof("Verifying connectivity to canary File System %v", d.canaryFileSystemId)
+		d.connectivity.check(context.Background(), d.verifyConnectivity)
+	}
+
+	if d.cleanupOnStartup {
+		klog.Info("Cleaning up leftover temporary mount points")
+		d.cleanupTempMounts(d.tempMountDir())
+	}
+
 	klog.Info("Starting efs-utils watchdog")
 	if err := d.efsWatchdog.start(); err != nil {
 		return err
 	}
 
+	if d.metrics != nil {
+		d.metrics.serve(d.metricsAddress)
+	}
+
 	reaper := newReaper()
 	klog.Info("Starting reaper")
 	reaper.start()
@@ -128,6 +288,108 @@ func (d *Driver) Run() error {
 	return d.srv.Serve(listener)
 }
 
+// configSummary is the effective configuration of the driver, logged once at startup so operators can confirm
+// which provisioning modes and features are enabled without reading its flags.
+type configSummary struct {
+	ProvisioningModes        []string          `json:"provisioningModes"`
+	DefaultProvisioningMode  string            `json:"defaultProvisioningMode,omitempty"`
+	VolumeIdFormat           string            `json:"volumeIdFormat,omitempty"`
+	DefaultUid               *int64            `json:"defaultUid,omitempty"`
+	DefaultGid               *int64            `json:"defaultGid,omitempty"`
+	Tags                     map[string]string `json:"tags,omitempty"`
+	TempMountDir             string            `json:"tempMountDir"`
+	RootDirDeleteWorkers     int               `json:"rootDirDeleteWorkers"`
+	AccessPointLimit         int               `json:"accessPointLimit"`
+	CreateAccessPointRetries int               `json:"createAccessPointRetries"`
+	DeleteRetryInterval      string            `json:"deleteRetryInterval"`
+	DeleteVolumeTimeout      string            `json:"deleteVolumeTimeout"`
+	RoleCloudCacheTTL        string            `json:"roleCloudCacheTTL"`
+	MountTargetCacheTTL      string            `json:"mountTargetCacheTTL"`
+	AllowedDirectoryPerms    []string          `json:"allowedDirectoryPerms,omitempty"`
+	MountOptionsFileSystems  []string          `json:"mountOptionsFileSystems,omitempty"`
+	Region                   string            `json:"region,omitempty"`
+	EfsEndpoint              string            `json:"efsEndpoint,omitempty"`
+	CanaryFileSystemId       string            `json:"canaryFileSystemId,omitempty"`
+	MetricsAddress           string            `json:"metricsAddress,omitempty"`
+	Features                 map[string]bool   `json:"features"`
+}
+
+func (d *Driver) configSummary() *configSummary {
+	summary := &configSummary{
+		ProvisioningModes:        provisioningModes,
+		DefaultProvisioningMode:  d.defaultProvisioningMode,
+		VolumeIdFormat:           d.volumeIdFormat,
+		Tags:                     d.tags,
+		TempMountDir:             d.tempMountDir(),
+		RootDirDeleteWorkers:     d.rootDirDeleteWorkers,
+		AccessPointLimit:         d.accessPointLimit,
+		CreateAccessPointRetries: d.throttleRetries,
+		Region:                   d.endpointOpts.Region,
+		EfsEndpoint:              d.endpointOpts.EfsEndpoint,
+		CanaryFileSystemId:       d.canaryFileSystemId,
+		MetricsAddress:           d.metricsAddress,
+		Features: map[string]bool{
+			"delete-access-point-root-dir":                    d.deleteAccessPointRootDir,
+			"delete-access-point-on-root-dir-cleanup-failure": d.rootDirCleanupBestEffort,
+			"retain-access-point-on-delete":                   d.retainAccessPoint,
+			"internal-mounts-plain-nfs":                       d.plainNfsInternalMounts,
+			"provisioning-events":                             d.recorder != nil,
+			"tag-storage-class":                               d.tagStorageClass,
+			"probe-mount-targets":                             d.probeMountTargets,
+			"cleanup-temp-mounts-on-startup":                  d.cleanupOnStartup,
+			"verify-efs-connectivity":                         d.canaryFileSystemId != "",
+			"fips":                                            d.endpointOpts.UseFIPS,
+			"tracing":                                         d.tracer != nil,
+			"vol-metrics-opt-in":                              d.volMetricsOptIn,
+		},
+	}
+
+	var deleteRetryInterval, roleCloudCacheTTL, mountTargetCacheTTL time.Duration
+	if d.deleteLimiter != nil {
+		deleteRetryInterval = d.deleteLimiter.interval
+	}
+	if d.roleClouds != nil {
+		roleCloudCacheTTL = d.roleClouds.ttl
+	}
+	if d.mountTargets != nil {
+		mountTargetCacheTTL = d.mountTargets.ttl
+	}
+	summary.DeleteRetryInterval = deleteRetryInterval.String()
+	summary.DeleteVolumeTimeout = d.deleteTimeout.String()
+	if id, ok := d.defaultIds[Uid]; ok {
+		summary.DefaultUid = &id
+	}
+	if id, ok := d.defaultIds[Gid]; ok {
+		summary.DefaultGid = &id
+	}
+	summary.RoleCloudCacheTTL = roleCloudCacheTTL.String()
+	summary.MountTargetCacheTTL = mountTargetCacheTTL.String()
+
+	for mode := range d.allowedDirectoryPerms {
+		summary.AllowedDirectoryPerms = append(summary.AllowedDirectoryPerms, mode)
+	}
+	sort.Strings(summary.AllowedDirectoryPerms)
+	for fileSystemId := range d.fsMountOptions {
+		summary.MountOptionsFileSystems = append(summary.MountOptionsFileSystems, fileSystemId)
+	}
+	sort.Strings(summary.MountOptionsFileSystems)
+	return summary
+}
+
+// verifyConnectivity checks that the controller can reach a mount target of the canary file system on the NFS
+// port, so that a network misconfiguration is reported by the CSI Probe rather than by every DeleteVolume that mounts.
+func (d *Driver) verifyConnectivity(ctx context.Context) error {
+	mt, err := d.cloud.DescribeMountTargets(ctx, d.canaryFileSystemId, d.canaryAzName)
+	if err != nil {
+		return status.Errorf(codes.FailedPrecondition, "Failed to find a mount target of canary File System %v: %v", d.canaryFileSystemId, err)
+	}
+	if err := d.probeMountTarget(ctx, mt.IPAddress); err != nil {
+		return status.Errorf(codes.FailedPrecondition, "Mount target %v of canary File System %v in %v is not reachable on port %v, check the security groups and network ACLs between the controller and the mount target: %v", mt.MountTargetId, d.canaryFileSystemId, mt.AZName, NfsPort, err)
+	}
+	klog.Infof("Reached mount target %v of canary File System %v on port %v", mt.MountTargetId, d.canaryFileSystemId, NfsPort)
+	return nil
+}
+
 func parseTagsFromStr(tagStr string) map[string]string {
 	defer func() {
 		if r := recover(); r != nil {
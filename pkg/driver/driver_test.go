@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/driver/mocks"
+)
+
+func TestRunWithoutEfsUtils(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockMounter := mocks.NewMockMounter(mockCtl)
+	driver := &Driver{
+		endpoint:    "unix://" + t.TempDir() + "/csi.sock",
+		mounter:     mockMounter,
+		efsWatchdog: &mockWatchdog{},
+	}
+
+	mockMounter.EXPECT().IsEfsAvailable().Return(false)
+	err := driver.Run()
+	if err == nil || !strings.Contains(err.Error(), efsMountHelper) {
+		t.Fatalf("Expected Run to fail naming %v, got: %v", efsMountHelper, err)
+	}
+	if driver.srv != nil {
+		t.Fatal("Expected Run to fail before starting the gRPC server")
+	}
+	mockCtl.Finish()
+}
+
+func TestConfigSummary(t *testing.T) {
+	driver := &Driver{
+		tags:                     map[string]string{"environment": "prod"},
+		tempMountPrefix:          "/tmp/efs",
+		rootDirDeleteWorkers:     4,
+		accessPointLimit:         MaxAccessPointsPerFs,
+		throttleRetries:          5,
+		deleteAccessPointRootDir: true,
+		probeMountTargets:        true,
+		endpointOpts:             cloud.EndpointOptions{Region: "us-west-2", UseFIPS: true},
+		deleteLimiter:            newDeleteLimiter(time.Minute),
+		mountTargets:             newMountTargetCache(30 * time.Second),
+		allowedDirectoryPerms:    map[string]bool{"750": true, "700": true},
+		defaultIds:               map[string]int64{Gid: 2000},
+		volumeIdFormat:           volumeIdV1,
+	}
+
+	summary, err := json.Marshal(driver.configSummary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual map[string]interface{}
+	if err := json.Unmarshal(summary, &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"provisioningModes":        []interface{}{AccessPointMode},
+		"tags":                     map[string]interface{}{"environment": "prod"},
+		"tempMountDir":             "/tmp/efs",
+		"rootDirDeleteWorkers":     float64(4),
+		"accessPointLimit":         float64(MaxAccessPointsPerFs),
+		"createAccessPointRetries": float64(5),
+		"deleteRetryInterval":      "1m0s",
+		"roleCloudCacheTTL":        "0s",
+		"mountTargetCacheTTL":      "30s",
+		"allowedDirectoryPerms":    []interface{}{"700", "750"},
+		"region":                   "us-west-2",
+		"volumeIdFormat":           "v1",
+		"defaultGid":               float64(2000),
+		"defaultUid":               nil,
+	}
+	for key, value := range expected {
+		if !reflect.DeepEqual(actual[key], value) {
+			t.Errorf("Summary %v mismatched. Expected: %v, actual: %v", key, value, actual[key])
+		}
+	}
+
+	features := actual["features"].(map[string]interface{})
+	for feature, enabled := range map[string]bool{
+		"delete-access-point-root-dir":  true,
+		"probe-mount-targets":           true,
+		"fips":                          true,
+		"retain-access-point-on-delete": false,
+		"verify-efs-connectivity":       false,
+	} {
+		if features[feature] != enabled {
+			t.Errorf("Summary feature %v mismatched. Expected: %v, actual: %v", feature, enabled, features[feature])
+		}
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+// newEventRecorder returns a recorder that publishes events through the given client.
+func newEventRecorder(client kubernetes.Interface) record.EventRecorder {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	return broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: driverName})
+}
+
+// recordPvcEvent records an event on the PVC a volume is being provisioned for. It does nothing unless the driver
+// has an event recorder and the csi-provisioner passed the PVC name and namespace with --extra-create-metadata.
+func (d *Driver) recordPvcEvent(ctx context.Context, volumeParams map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	if d.recorder == nil {
+		return
+	}
+	name, namespace := volumeParams[PvcName], volumeParams[PvcNamespace]
+	if name == "" || namespace == "" {
+		return
+	}
+
+	ref := &corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "PersistentVolumeClaim",
+		Name:       name,
+		Namespace:  namespace,
+	}
+	// kubectl describe only lists events carrying the UID of the object
+	if d.kubeClient != nil {
+		pvc, err := d.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			klog.V(4).Infof("Could not get PVC %v/%v to record event %v: %v", namespace, name, reason, err)
+		} else {
+			ref.UID = pvc.UID
+		}
+	}
+	d.recorder.Eventf(ref, eventType, reason, messageFmt, args...)
+}
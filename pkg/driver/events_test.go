@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+// uidRecorder remembers the object of the last event so tests can check which PVC it was recorded on.
+type uidRecorder struct {
+	*record.FakeRecorder
+	object runtime.Object
+}
+
+func (r *uidRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.object = object
+	r.FakeRecorder.Eventf(object, eventtype, reason, messageFmt, args...)
+}
+
+func TestRecordPvcEvent(t *testing.T) {
+	volumeParams := map[string]string{
+		PvcName:      "data",
+		PvcNamespace: "tenant-a",
+	}
+
+	t.Run("No recorder", func(t *testing.T) {
+		driver := &Driver{}
+		driver.recordPvcEvent(context.Background(), volumeParams, corev1.EventTypeNormal, "Reason", "message")
+	})
+
+	t.Run("No PVC metadata", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(1)
+		driver := &Driver{recorder: recorder}
+		driver.recordPvcEvent(context.Background(), map[string]string{}, corev1.EventTypeNormal, "Reason", "message")
+		if len(recorder.Events) != 0 {
+			t.Fatalf("Expected no event, got: %v", <-recorder.Events)
+		}
+	})
+
+	t.Run("Event is recorded on the PVC", func(t *testing.T) {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "tenant-a", UID: types.UID("1234")},
+		}
+		recorder := &uidRecorder{FakeRecorder: record.NewFakeRecorder(1)}
+		driver := &Driver{recorder: recorder, kubeClient: fake.NewSimpleClientset(pvc)}
+		driver.recordPvcEvent(context.Background(), volumeParams, corev1.EventTypeWarning, "TagsDropped", "dropped %v", []string{"a"})
+
+		if event := <-recorder.Events; event != "Warning TagsDropped dropped [a]" {
+			t.Fatalf("Unexpected event: %v", event)
+		}
+		ref, ok := recorder.object.(*corev1.ObjectReference)
+		if !ok {
+			t.Fatalf("Expected an object reference, got: %T", recorder.object)
+		}
+		if ref.Kind != "PersistentVolumeClaim" || ref.Namespace != "tenant-a" || ref.Name != "data" || ref.UID != pvc.UID {
+			t.Fatalf("Event recorded on the wrong object: %+v", ref)
+		}
+	})
+}
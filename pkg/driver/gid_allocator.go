@@ -3,6 +3,7 @@ package driver
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
 
 	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/cloud"
@@ -20,34 +21,33 @@ type FilesystemID struct {
 }
 
 type GidAllocator struct {
-	cloud      cloud.Cloud
 	fsIdGidMap map[string]*FilesystemID
 	mu         sync.Mutex
 }
 
-func NewGidAllocator(cloud cloud.Cloud) GidAllocator {
+func NewGidAllocator() GidAllocator {
 	return GidAllocator{
-		cloud:      cloud,
 		fsIdGidMap: make(map[string]*FilesystemID),
 	}
 }
 
-// Retrieves the next available GID
-func (g *GidAllocator) getNextGid(ctx context.Context, fsId string, gidMin, gidMax int) (int64, error) {
+// Retrieves the next available GID, listing the access points of the file system through localCloud
+func (g *GidAllocator) getNextGid(ctx context.Context, localCloud cloud.Cloud, fsId string, gidMin, gidMax int) (int64, error) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	klog.V(5).Infof("Recieved getNextGid for fsId: %v, min: %v, max: %v", fsId, gidMin, gidMax)
 
-	usedGids, err := g.getUsedGids(ctx, fsId)
+	usedGids, staticGids, err := g.getUsedGids(ctx, localCloud, fsId)
 	if err != nil {
 		return 0, status.Errorf(codes.Internal, "Failed to discover used GIDs for filesystem: %v: %v ", fsId, err)
 	}
+	logStaticGidsInRange(fsId, staticGids, gidMin, gidMax)
 
 	gid, err := getNextUnusedGid(usedGids, gidMin, gidMax)
 
 	if err != nil {
-		return 0, status.Errorf(codes.Internal, "Failed to locate a free GID for given file system: %v. "+
+		return 0, status.Errorf(codes.ResourceExhausted, "Failed to locate a free GID for given file system: %v. "+
 			"Please create a new storage class with a new file-system", fsId)
 	}
 
@@ -61,31 +61,73 @@ func (g *GidAllocator) removeFsId(fsId string) {
 	delete(g.fsIdGidMap, fsId)
 }
 
-func (g *GidAllocator) getUsedGids(ctx context.Context, fsId string) (gids []int64, err error) {
+// getUsedGids returns the GIDs of every access point on the file system, whether or not the driver created it.
+// staticGids holds the subset used by access points that were not created by the driver.
+func (g *GidAllocator) getUsedGids(ctx context.Context, localCloud cloud.Cloud, fsId string) (gids, staticGids []int64, err error) {
 	gids = []int64{}
-	accessPoints, err := g.cloud.ListAccessPoints(ctx, fsId)
+	accessPoints, err := localCloud.ListAccessPoints(ctx, fsId)
 	if err != nil {
 		err = fmt.Errorf("failed to list access points: %v", err)
 		return
 	}
 	if len(accessPoints) == 0 {
-		return gids, nil
+		return gids, nil, nil
 	}
 	for _, ap := range accessPoints {
 		// This should happen only in tests - skip nil pointers.
 		if ap == nil {
 			continue
 		}
-		if ap != nil && ap.PosixUser == nil {
+		_, dynamic := ap.Tags[DefaultTagKey]
+		var apGids []int64
+		if ap.PosixUser != nil {
+			apGids = append(apGids, ap.PosixUser.Gid)
+		}
+		// Access points provisioned with tagAllocatedGid record the gid they were allocated, which stays used
+		// whether or not the access point still enforces it
+		if value, ok := ap.Tags[AllocatedGidTagKey]; ok {
+			var gid int64
+			gid, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				err = fmt.Errorf("failed to parse %v tag %q of AccessPoint: %s: %v", AllocatedGidTagKey, value, ap.AccessPointId, err)
+				return
+			}
+			if ap.PosixUser == nil || ap.PosixUser.Gid != gid {
+				apGids = append(apGids, gid)
+			}
+		}
+		if len(apGids) == 0 {
+			if !dynamic {
+				// Static access points do not have to enforce a POSIX user, and then do not use any GID
+				klog.V(5).Infof("Static AccessPoint %s has no PosixUser, it does not use a GID", ap.AccessPointId)
+				continue
+			}
 			err = fmt.Errorf("failed to discover used GID because PosixUser is nil for AccessPoint: %s", ap.AccessPointId)
 			return
 		}
-		gids = append(gids, ap.PosixUser.Gid)
+		gids = append(gids, apGids...)
+		if !dynamic {
+			staticGids = append(staticGids, apGids...)
+		}
 	}
 	klog.V(5).Infof("Discovered used GIDs: %+v for FS ID: %v", gids, fsId)
 	return
 }
 
+// logStaticGidsInRange logs when access points not created by the driver use GIDs in the range it allocates from,
+// since those GIDs are skipped and the range has less room than configured.
+func logStaticGidsInRange(fsId string, staticGids []int64, gidMin, gidMax int) {
+	var inRange []int64
+	for _, gid := range staticGids {
+		if gid >= int64(gidMin) && gid <= int64(gidMax) {
+			inRange = append(inRange, gid)
+		}
+	}
+	if len(inRange) > 0 {
+		klog.Infof("Static access points on file system %v use GIDs %v in the range (%v:%v). The driver will not allocate them.", fsId, inRange, gidMin, gidMax)
+	}
+}
+
 func getNextUnusedGid(usedGids []int64, gidMin, gidMax int) (nextGid int, err error) {
 	requestedRange := gidMax - gidMin
 
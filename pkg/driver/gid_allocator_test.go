@@ -0,0 +1,142 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/driver/mocks"
+)
+
+func TestGetNextGidSkipsStaticAccessPoints(t *testing.T) {
+	const fsId = "fs-abcd1234"
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	mockCloud := mocks.NewMockCloud(mockCtl)
+
+	dynamic := map[string]string{DefaultTagKey: DefaultTagValue}
+	accessPoints := []*cloud.AccessPoint{
+		{AccessPointId: "fsap-static1", FileSystemId: fsId, PosixUser: &cloud.PosixUser{Gid: 1010, Uid: 1010}},
+		{AccessPointId: "fsap-dynamic1", FileSystemId: fsId, PosixUser: &cloud.PosixUser{Gid: 1009, Uid: 1009}, Tags: dynamic},
+		{AccessPointId: "fsap-static2", FileSystemId: fsId, PosixUser: &cloud.PosixUser{Gid: 1008, Uid: 0}, Tags: map[string]string{"Team": "storage"}},
+		{AccessPointId: "fsap-static3", FileSystemId: fsId, PosixUser: &cloud.PosixUser{Gid: 5000, Uid: 0}},
+	}
+	ctx := context.Background()
+	mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(accessPoints, nil)
+
+	gidAllocator := NewGidAllocator()
+	gid, err := gidAllocator.getNextGid(ctx, mockCloud, fsId, 1000, 1010)
+	if err != nil {
+		t.Fatalf("getNextGid failed: %v", err)
+	}
+	for _, ap := range accessPoints {
+		if ap.PosixUser.Gid == gid {
+			t.Fatalf("Allocated GID %d collides with access point %s", gid, ap.AccessPointId)
+		}
+	}
+	if gid != 1007 {
+		t.Fatalf("Expected GID 1007, got %d", gid)
+	}
+}
+
+func TestGetNextGidStaticAccessPointWithoutPosixUser(t *testing.T) {
+	const fsId = "fs-abcd1234"
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	mockCloud := mocks.NewMockCloud(mockCtl)
+
+	accessPoints := []*cloud.AccessPoint{
+		{AccessPointId: "fsap-static1", FileSystemId: fsId, AccessPointRootDir: "/shared"},
+		{AccessPointId: "fsap-static2", FileSystemId: fsId, PosixUser: &cloud.PosixUser{Gid: 1010, Uid: 0}},
+		{AccessPointId: "fsap-dynamic1", FileSystemId: fsId, PosixUser: &cloud.PosixUser{Gid: 1009, Uid: 1009}, Tags: map[string]string{DefaultTagKey: DefaultTagValue}},
+	}
+	ctx := context.Background()
+	mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(accessPoints, nil)
+
+	gidAllocator := NewGidAllocator()
+	gid, err := gidAllocator.getNextGid(ctx, mockCloud, fsId, 1000, 1010)
+	if err != nil {
+		t.Fatalf("getNextGid failed: %v", err)
+	}
+	if gid != 1008 {
+		t.Fatalf("Expected GID 1008, got %d", gid)
+	}
+}
+
+func TestGetNextGidDynamicAccessPointWithoutGid(t *testing.T) {
+	const fsId = "fs-abcd1234"
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	mockCloud := mocks.NewMockCloud(mockCtl)
+
+	accessPoints := []*cloud.AccessPoint{
+		{AccessPointId: "fsap-dynamic1", FileSystemId: fsId, Tags: map[string]string{DefaultTagKey: DefaultTagValue}},
+	}
+	ctx := context.Background()
+	mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(accessPoints, nil)
+
+	gidAllocator := NewGidAllocator()
+	if _, err := gidAllocator.getNextGid(ctx, mockCloud, fsId, 1000, 1010); err == nil {
+		t.Fatal("Expected getNextGid to fail when the GID of a driver owned access point is unknown")
+	}
+}
+
+func TestGetNextGidReadsAllocatedGidTag(t *testing.T) {
+	const fsId = "fs-abcd1234"
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	mockCloud := mocks.NewMockCloud(mockCtl)
+
+	accessPoints := []*cloud.AccessPoint{
+		// The tag is read even when the access point has a PosixUser
+		{AccessPointId: "fsap-tagged1", FileSystemId: fsId, PosixUser: &cloud.PosixUser{Gid: 1008, Uid: 1008}, Tags: map[string]string{DefaultTagKey: DefaultTagValue, AllocatedGidTagKey: "1010"}},
+		{AccessPointId: "fsap-tagged2", FileSystemId: fsId, Tags: map[string]string{DefaultTagKey: DefaultTagValue, AllocatedGidTagKey: "1009"}},
+		{AccessPointId: "fsap-posix", FileSystemId: fsId, PosixUser: &cloud.PosixUser{Gid: 1008, Uid: 1008}, Tags: map[string]string{DefaultTagKey: DefaultTagValue}},
+	}
+	ctx := context.Background()
+	mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(accessPoints, nil)
+
+	gidAllocator := NewGidAllocator()
+	gid, err := gidAllocator.getNextGid(ctx, mockCloud, fsId, 1000, 1010)
+	if err != nil {
+		t.Fatalf("getNextGid failed: %v", err)
+	}
+	if gid != 1007 {
+		t.Fatalf("Expected GID 1007, got %d", gid)
+	}
+}
+
+func TestGetNextGidInvalidAllocatedGidTag(t *testing.T) {
+	const fsId = "fs-abcd1234"
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	mockCloud := mocks.NewMockCloud(mockCtl)
+
+	accessPoints := []*cloud.AccessPoint{
+		{AccessPointId: "fsap-tagged1", FileSystemId: fsId, Tags: map[string]string{AllocatedGidTagKey: "not-a-gid"}},
+	}
+	ctx := context.Background()
+	mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(accessPoints, nil)
+
+	gidAllocator := NewGidAllocator()
+	if _, err := gidAllocator.getNextGid(ctx, mockCloud, fsId, 1000, 1010); err == nil {
+		t.Fatal("Expected getNextGid to fail on an unparsable tag")
+	}
+}
@@ -51,5 +51,8 @@ func (d *Driver) GetPluginCapabilities(ctx context.Context, req *csi.GetPluginCa
 }
 
 func (d *Driver) Probe(ctx context.Context, req *csi.ProbeRequest) (*csi.ProbeResponse, error) {
+	if err := d.connectivity.check(ctx, d.verifyConnectivity); err != nil {
+		return nil, err
+	}
 	return &csi.ProbeResponse{}, nil
 }
@@ -0,0 +1,226 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+const (
+	metricsNamespace = "efs_csi"
+
+	operationProvision = "provision"
+	operationDelete    = "delete"
+
+	// Access points are the only provisioning mode, the label leaves room for others.
+	modeAccessPoint = "access-point"
+
+	outcomeSuccess            = "success"
+	outcomeAccessDenied       = "access-denied"
+	outcomeNotFound           = "not-found"
+	outcomeAlreadyExists      = "already-exists"
+	outcomeInvalidArgument    = "invalid-argument"
+	outcomeFailedPrecondition = "failed-precondition"
+	outcomeResourceExhausted  = "resource-exhausted"
+	outcomeUnavailable        = "unavailable"
+	outcomeDeadlineExceeded   = "deadline-exceeded"
+	outcomeAborted            = "aborted"
+	outcomeInternal           = "internal"
+	outcomeFailure            = "failure"
+
+	cacheHit  = "hit"
+	cacheMiss = "miss"
+
+	// roleLabelLength is how many hex digits of the hash of a role ARN identify it in metric labels.
+	roleLabelLength = 12
+)
+
+// provisioningMetrics counts and times the volumes the controller provisions and deletes, and optionally the
+// phases, such as AWS calls and mounts, they spend their time in. It also records when each file system last had a
+// volume provisioned from it, and how the clients of cross account roles are reused and their roles assumed. A nil
+// *provisioningMetrics records nothing.
+type provisioningMetrics struct {
+	registry           *prometheus.Registry
+	operations         *prometheus.CounterVec
+	duration           *prometheus.HistogramVec
+	phases             *prometheus.HistogramVec
+	lastProvision      *prometheus.GaugeVec
+	roleClouds         *prometheus.CounterVec
+	assumeRoleCalls    *prometheus.CounterVec
+	assumeRoleDuration *prometheus.HistogramVec
+	now                func() time.Time
+}
+
+func newProvisioningMetrics(phases bool) *provisioningMetrics {
+	labels := []string{"operation", "mode", "outcome"}
+	m := &provisioningMetrics{
+		registry: prometheus.NewRegistry(),
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "provisioning_operations_total",
+			Help:      "Number of volume provision and delete operations, by outcome.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "provisioning_duration_seconds",
+			Help:      "Time taken by volume provision and delete operations, by outcome.",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, labels),
+		lastProvision: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "last_provision_timestamp",
+			Help:      "Unix time of the last volume successfully provisioned from each file system.",
+		}, []string{"file_system_id"}),
+		roleClouds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "role_cloud_cache_requests_total",
+			Help:      "Number of requests for the clients of a cross account role, by whether cached clients were reused.",
+		}, []string{"role", "result"}),
+		assumeRoleCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "assume_role_calls_total",
+			Help:      "Number of calls that assumed a cross account role, by outcome.",
+		}, []string{"role", "outcome"}),
+		assumeRoleDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "assume_role_duration_seconds",
+			Help:      "Time taken by calls that assumed a cross account role.",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"role"}),
+		now: time.Now,
+	}
+	m.registry.MustRegister(m.operations, m.duration, m.lastProvision, m.roleClouds, m.assumeRoleCalls, m.assumeRoleDuration)
+	if phases {
+		m.phases = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "provisioning_phase_duration_seconds",
+			Help:      "Time taken by each phase of volume provision and delete operations.",
+			Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"phase"})
+		m.registry.MustRegister(m.phases)
+	}
+	return m
+}
+
+// observe records an operation that started at start and returned err.
+func (m *provisioningMetrics) observe(operation, mode string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+	outcome := operationOutcome(err)
+	m.operations.WithLabelValues(operation, mode, outcome).Inc()
+	m.duration.WithLabelValues(operation, mode, outcome).Observe(time.Since(start).Seconds())
+}
+
+// observeProvisioned records that a volume was just provisioned from fileSystemId.
+func (m *provisioningMetrics) observeProvisioned(fileSystemId string) {
+	if m == nil || fileSystemId == "" {
+		return
+	}
+	m.lastProvision.WithLabelValues(fileSystemId).Set(float64(m.now().Unix()))
+}
+
+// observePhase records a phase of an operation that started at start.
+func (m *provisioningMetrics) observePhase(phase string, start time.Time) {
+	if m == nil || m.phases == nil {
+		return
+	}
+	m.phases.WithLabelValues(phase).Observe(time.Since(start).Seconds())
+}
+
+// observeRoleCloud records whether the clients requested for roleArn were found in the cache. Clients that only
+// use the driver's own credentials, such as those for another region, are not recorded.
+func (m *provisioningMetrics) observeRoleCloud(roleArn string, hit bool) {
+	if m == nil || roleArn == "" {
+		return
+	}
+	result := cacheMiss
+	if hit {
+		result = cacheHit
+	}
+	m.roleClouds.WithLabelValues(roleLabel(roleArn), result).Inc()
+}
+
+// observeAssumeRole records a call that assumed roleArn. It is a cloud.AssumeRoleObserver.
+func (m *provisioningMetrics) observeAssumeRole(roleArn string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	outcome := outcomeSuccess
+	if err != nil {
+		outcome = outcomeFailure
+	}
+	role := roleLabel(roleArn)
+	m.assumeRoleCalls.WithLabelValues(role, outcome).Inc()
+	m.assumeRoleDuration.WithLabelValues(role).Observe(duration.Seconds())
+}
+
+// roleLabel identifies roleArn in metric labels by a prefix of its hash, so that the account and role names are
+// not exposed by the metrics.
+func roleLabel(roleArn string) string {
+	hash := sha256.Sum256([]byte(roleArn))
+	return hex.EncodeToString(hash[:])[:roleLabelLength]
+}
+
+// serve exposes the metrics on address at /metrics until the server fails.
+func (m *provisioningMetrics) serve(address string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	go func() {
+		klog.Infof("Serving metrics on %v", address)
+		if err := http.ListenAndServe(address, mux); err != nil {
+			klog.Errorf("Metrics server stopped: %v", err)
+		}
+	}()
+}
+
+// operationOutcome classifies the error returned by a controller operation by its gRPC code.
+func operationOutcome(err error) string {
+	switch status.Code(err) {
+	case codes.OK:
+		return outcomeSuccess
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return outcomeAccessDenied
+	case codes.NotFound:
+		return outcomeNotFound
+	case codes.AlreadyExists:
+		return outcomeAlreadyExists
+	case codes.InvalidArgument:
+		return outcomeInvalidArgument
+	case codes.FailedPrecondition:
+		return outcomeFailedPrecondition
+	case codes.ResourceExhausted:
+		return outcomeResourceExhausted
+	case codes.Unavailable:
+		return outcomeUnavailable
+	case codes.DeadlineExceeded:
+		return outcomeDeadlineExceeded
+	case codes.Aborted:
+		return outcomeAborted
+	default:
+		return outcomeInternal
+	}
+}
@@ -0,0 +1,473 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/driver/mocks"
+)
+
+func TestProvisioningMetrics(t *testing.T) {
+	const (
+		fsId = "fs-abcd1234"
+		apId = "fsap-abcd1234xyz987"
+	)
+	createReq := func() *csi.CreateVolumeRequest {
+		return &csi.CreateVolumeRequest{
+			Name: "volumeName",
+			VolumeCapabilities: []*csi.VolumeCapability{
+				{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+					},
+				},
+			},
+			Parameters: map[string]string{
+				ProvisioningMode: "efs-ap",
+				FsId:             fsId,
+				DirectoryPerms:   "777",
+				Uid:              "1000",
+				Gid:              "1000",
+			},
+		}
+	}
+	deleteReq := func(volId string) *csi.DeleteVolumeRequest {
+		return &csi.DeleteVolumeRequest{VolumeId: volId}
+	}
+
+	testCases := []struct {
+		name      string
+		operation string
+		outcome   string
+		testFunc  func(ctx context.Context, d *Driver, mockCloud *mocks.MockCloud) error
+	}{
+		{
+			name:      "provision success",
+			operation: operationProvision,
+			outcome:   outcomeSuccess,
+			testFunc: func(ctx context.Context, d *Driver, mockCloud *mocks.MockCloud) error {
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(&cloud.FileSystem{FileSystemId: fsId}, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(&cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId}, nil)
+				_, err := d.CreateVolume(ctx, createReq())
+				return err
+			},
+		},
+		{
+			name:      "provision access denied",
+			operation: operationProvision,
+			outcome:   outcomeAccessDenied,
+			testFunc: func(ctx context.Context, d *Driver, mockCloud *mocks.MockCloud) error {
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, cloud.ErrAccessDenied)
+				_, err := d.CreateVolume(ctx, createReq())
+				return err
+			},
+		},
+		{
+			name:      "provision already exists",
+			operation: operationProvision,
+			outcome:   outcomeAlreadyExists,
+			testFunc: func(ctx context.Context, d *Driver, mockCloud *mocks.MockCloud) error {
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(&cloud.FileSystem{FileSystemId: fsId}, nil)
+				mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, cloud.ErrAlreadyExists)
+				mockCloud.EXPECT().ListAccessPoints(gomock.Eq(ctx), gomock.Eq(fsId)).Return(nil, nil)
+				_, err := d.CreateVolume(ctx, createReq())
+				return err
+			},
+		},
+		{
+			name:      "provision internal",
+			operation: operationProvision,
+			outcome:   outcomeInternal,
+			testFunc: func(ctx context.Context, d *Driver, mockCloud *mocks.MockCloud) error {
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("DescribeFileSystems failed"))
+				_, err := d.CreateVolume(ctx, createReq())
+				return err
+			},
+		},
+		{
+			name:      "delete success",
+			operation: operationDelete,
+			outcome:   outcomeSuccess,
+			testFunc: func(ctx context.Context, d *Driver, mockCloud *mocks.MockCloud) error {
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+				_, err := d.DeleteVolume(ctx, deleteReq(fsId+"::"+apId))
+				return err
+			},
+		},
+		{
+			name:      "delete access denied",
+			operation: operationDelete,
+			outcome:   outcomeAccessDenied,
+			testFunc: func(ctx context.Context, d *Driver, mockCloud *mocks.MockCloud) error {
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(cloud.ErrAccessDenied)
+				_, err := d.DeleteVolume(ctx, deleteReq(fsId+"::"+apId))
+				return err
+			},
+		},
+		{
+			name:      "delete not found",
+			operation: operationDelete,
+			outcome:   outcomeNotFound,
+			testFunc: func(ctx context.Context, d *Driver, mockCloud *mocks.MockCloud) error {
+				_, err := d.DeleteVolume(ctx, deleteReq(fsId))
+				return err
+			},
+		},
+		{
+			name:      "delete invalid argument",
+			operation: operationDelete,
+			outcome:   outcomeInvalidArgument,
+			testFunc: func(ctx context.Context, d *Driver, mockCloud *mocks.MockCloud) error {
+				_, err := d.DeleteVolume(ctx, deleteReq("::"+apId))
+				return err
+			},
+		},
+		{
+			name:      "delete internal",
+			operation: operationDelete,
+			outcome:   outcomeInternal,
+			testFunc: func(ctx context.Context, d *Driver, mockCloud *mocks.MockCloud) error {
+				mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(errors.New("DeleteAccessPoint failed"))
+				_, err := d.DeleteVolume(ctx, deleteReq(fsId+"::"+apId))
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+			mockCloud := mocks.NewMockCloud(mockCtl)
+			metrics := newProvisioningMetrics(false)
+			driver := &Driver{
+				endpoint:     "endpoint",
+				cloud:        mockCloud,
+				gidAllocator: NewGidAllocator(),
+				metrics:      metrics,
+			}
+
+			err := tc.testFunc(context.Background(), driver, mockCloud)
+			if outcome := operationOutcome(err); outcome != tc.outcome {
+				t.Fatalf("Expected outcome %v, got %v: %v", tc.outcome, outcome, err)
+			}
+			if count := testutil.ToFloat64(metrics.operations.WithLabelValues(tc.operation, modeAccessPoint, tc.outcome)); count != 1 {
+				t.Fatalf("Expected %v %v count to be 1, got %v", tc.operation, tc.outcome, count)
+			}
+			if count := testutil.CollectAndCount(metrics.operations); count != 1 {
+				t.Fatalf("Expected a single series to be recorded, got %v", count)
+			}
+			if count := testutil.CollectAndCount(metrics.duration); count != 1 {
+				t.Fatalf("Expected a single latency series to be recorded, got %v", count)
+			}
+		})
+	}
+}
+
+func TestOperationOutcome(t *testing.T) {
+	testCases := []struct {
+		name    string
+		err     error
+		outcome string
+	}{
+		{name: "ok", err: nil, outcome: outcomeSuccess},
+		{name: "unauthenticated", err: status.Error(codes.Unauthenticated, "denied"), outcome: outcomeAccessDenied},
+		{name: "permission denied", err: status.Error(codes.PermissionDenied, "denied"), outcome: outcomeAccessDenied},
+		{name: "not found", err: status.Error(codes.NotFound, "missing"), outcome: outcomeNotFound},
+		{name: "already exists", err: status.Error(codes.AlreadyExists, "exists"), outcome: outcomeAlreadyExists},
+		{name: "invalid argument", err: status.Error(codes.InvalidArgument, "invalid"), outcome: outcomeInvalidArgument},
+		{name: "failed precondition", err: status.Error(codes.FailedPrecondition, "not ready"), outcome: outcomeFailedPrecondition},
+		{name: "resource exhausted", err: status.Error(codes.ResourceExhausted, "limit"), outcome: outcomeResourceExhausted},
+		{name: "unavailable", err: status.Error(codes.Unavailable, "throttled"), outcome: outcomeUnavailable},
+		{name: "deadline exceeded", err: status.Error(codes.DeadlineExceeded, "timeout"), outcome: outcomeDeadlineExceeded},
+		{name: "aborted", err: status.Error(codes.Aborted, "in progress"), outcome: outcomeAborted},
+		{name: "internal", err: status.Error(codes.Internal, "failed"), outcome: outcomeInternal},
+		{name: "unknown", err: errors.New("failed"), outcome: outcomeInternal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if outcome := operationOutcome(tc.err); outcome != tc.outcome {
+				t.Fatalf("Expected outcome %v, got %v", tc.outcome, outcome)
+			}
+		})
+	}
+}
+
+func TestProvisioningMetricsNil(t *testing.T) {
+	var metrics *provisioningMetrics
+	metrics.observe(operationProvision, modeAccessPoint, time.Now(), nil)
+	metrics.observeProvisioned("fs-abcd1234")
+	metrics.observeRoleCloud(testRoleArn, true)
+	metrics.observeAssumeRole(testRoleArn, time.Second, nil)
+}
+
+func TestLastProvisionTimestamp(t *testing.T) {
+	const (
+		fsId = "fs-abcd1234"
+		apId = "fsap-abcd1234xyz987"
+	)
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	mockCloud := mocks.NewMockCloud(mockCtl)
+	metrics := newProvisioningMetrics(false)
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	metrics.now = func() time.Time { return now }
+	driver := &Driver{
+		endpoint:     "endpoint",
+		cloud:        mockCloud,
+		gidAllocator: NewGidAllocator(),
+		metrics:      metrics,
+	}
+
+	ctx := context.Background()
+	createReq := func(params map[string]string) *csi.CreateVolumeRequest {
+		return &csi.CreateVolumeRequest{
+			Name: "volumeName",
+			VolumeCapabilities: []*csi.VolumeCapability{
+				{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+					},
+				},
+			},
+			Parameters: params,
+		}
+	}
+	params := map[string]string{
+		ProvisioningMode: "efs-ap",
+		FsId:             fsId,
+		Uid:              "1000",
+		Gid:              "1000",
+	}
+
+	mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(&cloud.FileSystem{FileSystemId: fsId}, nil).Times(3)
+	mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(&cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId}, nil)
+	if _, err := driver.CreateVolume(ctx, createReq(params)); err != nil {
+		t.Fatalf("CreateVolume failed: %v", err)
+	}
+	if value := testutil.ToFloat64(metrics.lastProvision.WithLabelValues(fsId)); value != float64(now.Unix()) {
+		t.Fatalf("Expected last provision timestamp %v, got %v", now.Unix(), value)
+	}
+
+	// Failed and dry run provisions do not count as activity
+	now = now.Add(time.Hour)
+	mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("CreateAccessPoint failed"))
+	if _, err := driver.CreateVolume(ctx, createReq(params)); err == nil {
+		t.Fatal("CreateVolume did not fail")
+	}
+	params[DryRun] = "true"
+	if _, err := driver.CreateVolume(ctx, createReq(params)); err != nil {
+		t.Fatalf("CreateVolume failed: %v", err)
+	}
+	if value := testutil.ToFloat64(metrics.lastProvision.WithLabelValues(fsId)); value != float64(now.Add(-time.Hour).Unix()) {
+		t.Fatalf("Expected last provision timestamp to stay at %v, got %v", now.Add(-time.Hour).Unix(), value)
+	}
+
+	// A file system referenced by its ARN is recorded under its ID
+	delete(params, DryRun)
+	delete(params, FsId)
+	params[FileSystemArn] = "arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/" + fsId
+	mockCloud.EXPECT().GetMetadata().Return(&testMetadata{region: "us-east-1"})
+	mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(&cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId}, nil)
+	if _, err := driver.CreateVolume(ctx, createReq(params)); err != nil {
+		t.Fatalf("CreateVolume failed: %v", err)
+	}
+	if value := testutil.ToFloat64(metrics.lastProvision.WithLabelValues(fsId)); value != float64(now.Unix()) {
+		t.Fatalf("Expected last provision timestamp %v, got %v", now.Unix(), value)
+	}
+	if count := testutil.CollectAndCount(metrics.lastProvision); count != 1 {
+		t.Fatalf("Expected one file system to be recorded, got %d", count)
+	}
+}
+
+// phaseCounts returns how many times each phase was observed.
+func phaseCounts(t *testing.T, metrics *provisioningMetrics) map[string]uint64 {
+	families, err := metrics.registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+	counts := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() != metricsNamespace+"_provisioning_phase_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "phase" {
+					counts[label.GetValue()] = metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return counts
+}
+
+func TestProvisioningPhaseMetrics(t *testing.T) {
+	const (
+		fsId = "fs-abcd1234"
+		apId = "fsap-abcd1234xyz987"
+	)
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	mockCloud := mocks.NewMockCloud(mockCtl)
+	mockMounter := mocks.NewMockMounter(mockCtl)
+	metrics := newProvisioningMetrics(true)
+	driver := &Driver{
+		endpoint:                 "endpoint",
+		cloud:                    mockCloud,
+		mounter:                  mockMounter,
+		gidAllocator:             NewGidAllocator(),
+		deleteAccessPointRootDir: true,
+		metrics:                  metrics,
+	}
+
+	ctx := context.Background()
+	createReq := &csi.CreateVolumeRequest{
+		Name: "volumeName",
+		VolumeCapabilities: []*csi.VolumeCapability{
+			{
+				AccessType: &csi.VolumeCapability_Mount{
+					Mount: &csi.VolumeCapability_MountVolume{},
+				},
+				AccessMode: &csi.VolumeCapability_AccessMode{
+					Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+				},
+			},
+		},
+		Parameters: map[string]string{
+			ProvisioningMode: "efs-ap",
+			FsId:             fsId,
+			Uid:              "1000",
+			Gid:              "1000",
+		},
+	}
+	mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Any()).Return(&cloud.FileSystem{FileSystemId: fsId}, nil)
+	mockCloud.EXPECT().CreateAccessPoint(gomock.Eq(ctx), gomock.Any(), gomock.Any(), gomock.Any()).Return(&cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId}, nil)
+	if _, err := driver.CreateVolume(ctx, createReq); err != nil {
+		t.Fatalf("CreateVolume failed: %v", err)
+	}
+	expected := map[string]uint64{"DescribeFileSystem": 1, "CreateAccessPoint": 1}
+	if counts := phaseCounts(t, metrics); !reflect.DeepEqual(counts, expected) {
+		t.Fatalf("Phase counts mismatched. Expected: %v, actual: %v", expected, counts)
+	}
+
+	accessPoint := &cloud.AccessPoint{AccessPointId: apId, FileSystemId: fsId, AccessPointRootDir: "/volumeName"}
+	mockCloud.EXPECT().DescribeAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(accessPoint, nil)
+	mockMounter.EXPECT().MakeDir(gomock.Any()).Return(nil)
+	mockMounter.EXPECT().Mount(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
+	mockCloud.EXPECT().DeleteAccessPoint(gomock.Any(), gomock.Eq(apId)).Return(nil)
+	if _, err := driver.DeleteVolume(ctx, &csi.DeleteVolumeRequest{VolumeId: fsId + "::" + apId}); err != nil {
+		t.Fatalf("DeleteVolume failed: %v", err)
+	}
+	expected = map[string]uint64{
+		"DescribeFileSystem":  1,
+		"CreateAccessPoint":   1,
+		"DescribeAccessPoint": 1,
+		"Mount":               1,
+		"RemoveRootDir":       1,
+		"Unmount":             1,
+		"DeleteAccessPoint":   1,
+	}
+	if counts := phaseCounts(t, metrics); !reflect.DeepEqual(counts, expected) {
+		t.Fatalf("Phase counts mismatched. Expected: %v, actual: %v", expected, counts)
+	}
+}
+
+func TestProvisioningPhaseMetricsDisabled(t *testing.T) {
+	metrics := newProvisioningMetrics(false)
+	metrics.observePhase("Mount", time.Now())
+	if counts := phaseCounts(t, metrics); len(counts) != 0 {
+		t.Fatalf("Expected no phases to be recorded, got: %v", counts)
+	}
+}
+
+func TestRoleCloudMetrics(t *testing.T) {
+	metrics := newProvisioningMetrics(false)
+	cache, _, calls := newTestCloudCache(15*time.Minute, nil)
+	cache.metrics = metrics
+	driver := &Driver{roleClouds: cache}
+	role := roleLabel(testRoleArn)
+
+	if _, _, err := getCloud(map[string]string{RoleArn: testRoleArn}, driver, ""); err != nil {
+		t.Fatalf("getCloud failed: %v", err)
+	}
+	if count := testutil.ToFloat64(metrics.roleClouds.WithLabelValues(role, cacheMiss)); count != 1 {
+		t.Fatalf("Expected 1 miss after the first request, got %v", count)
+	}
+	if count := testutil.ToFloat64(metrics.roleClouds.WithLabelValues(role, cacheHit)); count != 0 {
+		t.Fatalf("Expected no hits after the first request, got %v", count)
+	}
+
+	if _, _, err := getCloud(map[string]string{RoleArn: testRoleArn}, driver, ""); err != nil {
+		t.Fatalf("getCloud failed: %v", err)
+	}
+	if count := testutil.ToFloat64(metrics.roleClouds.WithLabelValues(role, cacheMiss)); count != 1 {
+		t.Fatalf("Expected 1 miss after the second request, got %v", count)
+	}
+	if count := testutil.ToFloat64(metrics.roleClouds.WithLabelValues(role, cacheHit)); count != 1 {
+		t.Fatalf("Expected 1 hit after the second request, got %v", count)
+	}
+	if *calls != 1 {
+		t.Fatalf("Expected the cloud to be created once, got %d creations", *calls)
+	}
+
+	metrics.observeAssumeRole(testRoleArn, time.Second, nil)
+	metrics.observeAssumeRole(testRoleArn, time.Second, errors.New("access denied"))
+	if count := testutil.ToFloat64(metrics.assumeRoleCalls.WithLabelValues(role, outcomeSuccess)); count != 1 {
+		t.Fatalf("Expected 1 successful AssumeRole call, got %v", count)
+	}
+	if count := testutil.ToFloat64(metrics.assumeRoleCalls.WithLabelValues(role, outcomeFailure)); count != 1 {
+		t.Fatalf("Expected 1 failed AssumeRole call, got %v", count)
+	}
+	if count := testutil.CollectAndCount(metrics.assumeRoleDuration); count != 1 {
+		t.Fatalf("Expected 1 AssumeRole duration series, got %d", count)
+	}
+}
+
+func TestRoleLabel(t *testing.T) {
+	label := roleLabel(testRoleArn)
+	if len(label) != roleLabelLength {
+		t.Fatalf("Expected a label of %d characters, got %q", roleLabelLength, label)
+	}
+	if strings.Contains(testRoleArn, label) || label != roleLabel(testRoleArn) {
+		t.Fatalf("Expected a stable label that does not expose the role, got %q", label)
+	}
+	if label == roleLabel("arn:aws:iam::1234567890:role/OtherRole") {
+		t.Fatalf("Expected different roles to have different labels, got %q for both", label)
+	}
+}
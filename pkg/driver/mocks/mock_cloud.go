@@ -235,6 +235,36 @@ func (mr *MockCloudMockRecorder) DescribeMountTargets(ctx, fileSystemId, az inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeMountTargets", reflect.TypeOf((*MockCloud)(nil).DescribeMountTargets), ctx, fileSystemId, az)
 }
 
+// DescribeMountTargetInSubnet mocks base method.
+func (m *MockCloud) DescribeMountTargetInSubnet(ctx context.Context, fileSystemId, subnetId string) (*cloud.MountTarget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeMountTargetInSubnet", ctx, fileSystemId, subnetId)
+	ret0, _ := ret[0].(*cloud.MountTarget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeMountTargetInSubnet indicates an expected call of DescribeMountTargetInSubnet.
+func (mr *MockCloudMockRecorder) DescribeMountTargetInSubnet(ctx, fileSystemId, subnetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeMountTargetInSubnet", reflect.TypeOf((*MockCloud)(nil).DescribeMountTargetInSubnet), ctx, fileSystemId, subnetId)
+}
+
+// FindFileSystemByTag mocks base method.
+func (m *MockCloud) FindFileSystemByTag(ctx context.Context, key, value string) ([]*cloud.FileSystem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindFileSystemByTag", ctx, key, value)
+	ret0, _ := ret[0].([]*cloud.FileSystem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindFileSystemByTag indicates an expected call of FindFileSystemByTag.
+func (mr *MockCloudMockRecorder) FindFileSystemByTag(ctx, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFileSystemByTag", reflect.TypeOf((*MockCloud)(nil).FindFileSystemByTag), ctx, key, value)
+}
+
 // GetMetadata mocks base method.
 func (m *MockCloud) GetMetadata() cloud.MetadataService {
 	m.ctrl.T.Helper()
@@ -263,3 +293,18 @@ func (mr *MockCloudMockRecorder) ListAccessPoints(arg0, arg1 interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccessPoints", reflect.TypeOf((*MockCloud)(nil).ListAccessPoints), arg0, arg1)
 }
+
+// ListMountTargets mocks base method.
+func (m *MockCloud) ListMountTargets(ctx context.Context, fileSystemId string) ([]*cloud.MountTarget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMountTargets", ctx, fileSystemId)
+	ret0, _ := ret[0].([]*cloud.MountTarget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMountTargets indicates an expected call of ListMountTargets.
+func (mr *MockCloudMockRecorder) ListMountTargets(ctx, fileSystemId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMountTargets", reflect.TypeOf((*MockCloud)(nil).ListMountTargets), ctx, fileSystemId)
+}
@@ -66,6 +66,20 @@ func (mr *MockMounterMockRecorder) GetMountRefs(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMountRefs", reflect.TypeOf((*MockMounter)(nil).GetMountRefs), arg0)
 }
 
+// IsEfsAvailable mocks base method.
+func (m *MockMounter) IsEfsAvailable() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEfsAvailable")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsEfsAvailable indicates an expected call of IsEfsAvailable.
+func (mr *MockMounterMockRecorder) IsEfsAvailable() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEfsAvailable", reflect.TypeOf((*MockMounter)(nil).IsEfsAvailable))
+}
+
 // IsLikelyNotMountPoint mocks base method.
 func (m *MockMounter) IsLikelyNotMountPoint(arg0 string) (bool, error) {
 	m.ctrl.T.Helper()
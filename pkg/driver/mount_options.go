@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	MountOptionsTagKey     = "efs.csi.aws.com/mount-options"
+	EncryptInTransitTagKey = "efs.csi.aws.com/encrypt-in-transit"
+)
+
+// loadFsMountOptions reads the default mount options the controller uses for each file system from the
+// JSON file at path, a map of file system ID to a list of mount options. An empty path configures none.
+func loadFsMountOptions(path string) (map[string][]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read mount options config %q: %v", path, err)
+	}
+	fsMountOptions := map[string][]string{}
+	if err := json.Unmarshal(data, &fsMountOptions); err != nil {
+		return nil, fmt.Errorf("could not parse mount options config %q: %v", path, err)
+	}
+	return fsMountOptions, nil
+}
+
+// parseMountOptions splits a comma separated list of mount options, dropping empty entries.
+func parseMountOptions(value string) []string {
+	var options []string
+	for _, option := range strings.Split(value, ",") {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// mergeMountOptions returns base with each list of overrides applied in turn. An override replaces
+// any earlier option with the same name, so "az=us-east-1b" replaces "az=us-east-1a".
+func mergeMountOptions(base []string, overrides ...[]string) []string {
+	merged := append([]string{}, base...)
+	for _, options := range overrides {
+		for _, option := range options {
+			name, _, _ := strings.Cut(option, "=")
+			kept := merged[:0]
+			for _, existing := range merged {
+				if existingName, _, _ := strings.Cut(existing, "="); existingName != name {
+					kept = append(kept, existing)
+				}
+			}
+			merged = append(kept, option)
+		}
+	}
+	return merged
+}
+
+// internalMountOptions returns the options the controller mounts a file system with through efs-utils. It starts
+// from tls and iam, then applies the options configured for the file system, those in the storage class parameters
+// and those in the storage class secrets. An encryptInTransit of false, where the secret takes precedence over the
+// parameter, drops tls and iam, which efs-utils only supports over tls.
+func internalMountOptions(fsOptions []string, parameters, secrets map[string]string) ([]string, error) {
+	encryptInTransit, source := true, ""
+	for _, settings := range []struct {
+		source string
+		values map[string]string
+	}{{"parameter", parameters}, {"secret", secrets}} {
+		if value, ok := settings.values[EncryptInTransit]; ok {
+			var err error
+			encryptInTransit, err = strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%v %v has invalid value %q: %v", settings.source, EncryptInTransit, value, err)
+			}
+			source = settings.source
+		}
+	}
+	parameterOptions := parseMountOptions(parameters[MountOptions])
+	secretOptions := parseMountOptions(secrets[MountOptions])
+	if encryptInTransit {
+		return mergeMountOptions([]string{"tls", "iam"}, fsOptions, parameterOptions, secretOptions), nil
+	}
+
+	mountOptions := mergeMountOptions(nil, fsOptions, parameterOptions, secretOptions)
+	for _, option := range []string{"tls", "iam"} {
+		if hasOption(mountOptions, option) {
+			return nil, fmt.Errorf("mount option %q conflicts with %v %v set to false", option, source, EncryptInTransit)
+		}
+	}
+	return mountOptions, nil
+}
+
+// mountParameterTags records the mountOptions and encryptInTransit storage class parameters as access point tags,
+// since DeleteVolume is not given the parameters. Tag values cannot contain commas, so the mount options are
+// separated by spaces.
+func mountParameterTags(volumeParams map[string]string) map[string]string {
+	tags := map[string]string{}
+	if value, ok := volumeParams[MountOptions]; ok {
+		tags[MountOptionsTagKey] = strings.Join(parseMountOptions(value), " ")
+	}
+	if value, ok := volumeParams[EncryptInTransit]; ok {
+		tags[EncryptInTransitTagKey] = value
+	}
+	return tags
+}
+
+// mountParametersFromTags returns the mountOptions and encryptInTransit storage class parameters that
+// mountParameterTags recorded on an access point.
+func mountParametersFromTags(tags map[string]string) map[string]string {
+	parameters := map[string]string{}
+	if value, ok := tags[MountOptionsTagKey]; ok {
+		parameters[MountOptions] = strings.Join(strings.Fields(value), ",")
+	}
+	if value, ok := tags[EncryptInTransitTagKey]; ok {
+		parameters[EncryptInTransit] = value
+	}
+	return parameters
+}
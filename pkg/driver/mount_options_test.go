@@ -0,0 +1,228 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeMountOptions(t *testing.T) {
+	testCases := []struct {
+		name      string
+		base      []string
+		overrides [][]string
+		expected  []string
+	}{
+		{
+			name:     "no overrides",
+			base:     []string{"tls", "iam"},
+			expected: []string{"tls", "iam"},
+		},
+		{
+			name:      "file system defaults are added",
+			base:      []string{"tls", "iam"},
+			overrides: [][]string{{"az=us-east-1a", "regional"}},
+			expected:  []string{"tls", "iam", "az=us-east-1a", "regional"},
+		},
+		{
+			name:      "later overrides replace options with the same name",
+			base:      []string{"tls", "iam"},
+			overrides: [][]string{{"az=us-east-1a", "regional"}, {"az=us-east-1b"}},
+			expected:  []string{"tls", "iam", "regional", "az=us-east-1b"},
+		},
+		{
+			name:      "repeated options are not duplicated",
+			base:      []string{"tls", "iam"},
+			overrides: [][]string{{"iam"}, {"tls"}},
+			expected:  []string{"iam", "tls"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			base := append([]string{}, tc.base...)
+			merged := mergeMountOptions(base, tc.overrides...)
+			if !reflect.DeepEqual(merged, tc.expected) {
+				t.Fatalf("Expected %v, got %v", tc.expected, merged)
+			}
+			if !reflect.DeepEqual(base, tc.base) {
+				t.Fatalf("Base options were modified: %v", base)
+			}
+		})
+	}
+}
+
+func TestParseMountOptions(t *testing.T) {
+	options := parseMountOptions(" az=us-east-1a, ,regional,")
+	expected := []string{"az=us-east-1a", "regional"}
+	if !reflect.DeepEqual(options, expected) {
+		t.Fatalf("Expected %v, got %v", expected, options)
+	}
+	if options := parseMountOptions(""); len(options) != 0 {
+		t.Fatalf("Expected no options, got %v", options)
+	}
+}
+
+func TestLoadFsMountOptions(t *testing.T) {
+	dir := t.TempDir()
+
+	fsMountOptions, err := loadFsMountOptions("")
+	if err != nil || fsMountOptions != nil {
+		t.Fatalf("Expected no config without a path, got %v, %v", fsMountOptions, err)
+	}
+
+	valid := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(valid, []byte(`{"fs-abcd1234": ["az=us-east-1a"], "fs-efgh5678": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fsMountOptions, err = loadFsMountOptions(valid)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	expected := map[string][]string{"fs-abcd1234": {"az=us-east-1a"}, "fs-efgh5678": {}}
+	if !reflect.DeepEqual(fsMountOptions, expected) {
+		t.Fatalf("Expected %v, got %v", expected, fsMountOptions)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"fs-abcd1234": "az=us-east-1a"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadFsMountOptions(invalid); err == nil {
+		t.Fatal("Expected an error for a malformed config")
+	}
+	if _, err := loadFsMountOptions(filepath.Join(dir, "missing.json")); err == nil {
+		t.Fatal("Expected an error for a missing config")
+	}
+}
+
+func TestInternalMountOptions(t *testing.T) {
+	testCases := []struct {
+		name       string
+		fsOptions  []string
+		parameters map[string]string
+		secrets    map[string]string
+		expected   []string
+		expectFail bool
+	}{
+		{
+			name:     "defaults to tls and iam",
+			expected: []string{"tls", "iam"},
+		},
+		{
+			name:      "storage class options are merged after file system options",
+			fsOptions: []string{"az=us-east-1a"},
+			secrets:   map[string]string{MountOptions: "regional,az=us-east-1b"},
+			expected:  []string{"tls", "iam", "regional", "az=us-east-1b"},
+		},
+		{
+			name:     "repeated tls is not duplicated",
+			secrets:  map[string]string{MountOptions: "tls", EncryptInTransit: "true"},
+			expected: []string{"iam", "tls"},
+		},
+		{
+			name:      "encryption in transit disabled",
+			fsOptions: []string{"az=us-east-1a"},
+			secrets:   map[string]string{EncryptInTransit: "false", MountOptions: "noresvport"},
+			expected:  []string{"az=us-east-1a", "noresvport"},
+		},
+		{
+			name:       "tls conflicts with encryption in transit disabled",
+			secrets:    map[string]string{EncryptInTransit: "false", MountOptions: "tls"},
+			expectFail: true,
+		},
+		{
+			name:       "iam conflicts with encryption in transit disabled",
+			fsOptions:  []string{"iam"},
+			secrets:    map[string]string{EncryptInTransit: "false"},
+			expectFail: true,
+		},
+		{
+			name:       "encryptInTransit is not a boolean",
+			secrets:    map[string]string{EncryptInTransit: "maybe"},
+			expectFail: true,
+		},
+		{
+			name:       "storage class parameters are merged between file system and secret options",
+			fsOptions:  []string{"az=us-east-1a"},
+			parameters: map[string]string{MountOptions: "regional,az=us-east-1b"},
+			secrets:    map[string]string{MountOptions: "az=us-east-1c"},
+			expected:   []string{"tls", "iam", "regional", "az=us-east-1c"},
+		},
+		{
+			name:       "encryption in transit disabled by parameter",
+			parameters: map[string]string{EncryptInTransit: "false", MountOptions: "noresvport"},
+			expected:   []string{"noresvport"},
+		},
+		{
+			name:       "secret takes precedence over parameter for encryption in transit",
+			parameters: map[string]string{EncryptInTransit: "false"},
+			secrets:    map[string]string{EncryptInTransit: "true"},
+			expected:   []string{"tls", "iam"},
+		},
+		{
+			name:       "parameter tls conflicts with secret encryption in transit disabled",
+			parameters: map[string]string{MountOptions: "tls"},
+			secrets:    map[string]string{EncryptInTransit: "false"},
+			expectFail: true,
+		},
+		{
+			name:       "encryptInTransit parameter is not a boolean",
+			parameters: map[string]string{EncryptInTransit: "maybe"},
+			expectFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mountOptions, err := internalMountOptions(tc.fsOptions, tc.parameters, tc.secrets)
+			if tc.expectFail {
+				if err == nil {
+					t.Fatalf("Expected an error, got options %v", mountOptions)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(mountOptions, tc.expected) {
+				t.Fatalf("Expected %v, got %v", tc.expected, mountOptions)
+			}
+		})
+	}
+}
+
+func TestMountParameterTags(t *testing.T) {
+	parameters := map[string]string{MountOptions: "regional, az=us-east-1b,,noresvport", EncryptInTransit: "false", Uid: "1000"}
+	tags := mountParameterTags(parameters)
+	expectedTags := map[string]string{MountOptionsTagKey: "regional az=us-east-1b noresvport", EncryptInTransitTagKey: "false"}
+	if !reflect.DeepEqual(tags, expectedTags) {
+		t.Fatalf("Expected tags %v, got %v", expectedTags, tags)
+	}
+	expected := map[string]string{MountOptions: "regional,az=us-east-1b,noresvport", EncryptInTransit: "false"}
+	if actual := mountParametersFromTags(tags); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Expected parameters %v, got %v", expected, actual)
+	}
+
+	if tags := mountParameterTags(map[string]string{}); len(tags) != 0 {
+		t.Fatalf("Expected no tags, got %v", tags)
+	}
+	if parameters := mountParametersFromTags(map[string]string{AzNameTagKey: "us-east-1a"}); len(parameters) != 0 {
+		t.Fatalf("Expected no parameters, got %v", parameters)
+	}
+}
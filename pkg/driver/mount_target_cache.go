@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/cloud"
+	"golang.org/x/sync/singleflight"
+)
+
+// MountTargetLookupTimeout bounds a DescribeMountTargets call shared by concurrent lookups.
+const MountTargetLookupTimeout = 30 * time.Second
+
+type mountTargetCacheKey struct {
+	roleArn      string
+	region       string
+	fileSystemId string
+	azName       string
+	subnetId     string
+}
+
+type mountTargetCacheEntry struct {
+	mountTarget *cloud.MountTarget
+	expires     time.Time
+}
+
+// mountTargetCache briefly keeps the results of DescribeMountTargets, so that concurrent and back to back requests
+// for the same file system and availability zone share a single call instead of each calling the EFS API. Results are
+// kept per cross account role and region, so a role is never given a mount target it could not describe itself.
+type mountTargetCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	group   singleflight.Group
+	mu      sync.Mutex
+	entries map[mountTargetCacheKey]mountTargetCacheEntry
+}
+
+func newMountTargetCache(ttl time.Duration) *mountTargetCache {
+	return &mountTargetCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[mountTargetCacheKey]mountTargetCacheEntry),
+	}
+}
+
+// describe returns the mount target of the file system in subnetId, or else in azName, calling DescribeMountTargets
+// only when no recent result is cached and no identical call is already in flight. localCloud is the cloud of roleArn
+// in region, both of which are empty for the driver's own. A nil cache, or a ttl of 0, always calls it. The shared
+// call runs detached from the context of the caller that started it, bounded by MountTargetLookupTimeout, so that
+// caller giving up does not fail the others. Each caller stops waiting once its own context is done.
+func (c *mountTargetCache) describe(ctx context.Context, localCloud cloud.Cloud, roleArn, region, fileSystemId, azName, subnetId string) (*cloud.MountTarget, error) {
+	lookup := func(ctx context.Context) (*cloud.MountTarget, error) {
+		if subnetId != "" {
+			return localCloud.DescribeMountTargetInSubnet(ctx, fileSystemId, subnetId)
+		}
+		return localCloud.DescribeMountTargets(ctx, fileSystemId, azName)
+	}
+	if c == nil || c.ttl <= 0 {
+		return lookup(ctx)
+	}
+
+	key := mountTargetCacheKey{roleArn: roleArn, region: region, fileSystemId: fileSystemId, azName: azName, subnetId: subnetId}
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && c.now().Before(entry.expires) {
+		c.mu.Unlock()
+		return entry.mountTarget, nil
+	}
+	c.mu.Unlock()
+
+	lookupCtx := context.Context(detachedContext{ctx})
+	results := c.group.DoChan(strings.Join([]string{roleArn, region, fileSystemId, azName, subnetId}, "/"), func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(lookupCtx, MountTargetLookupTimeout)
+		defer cancel()
+		mountTarget, err := lookup(ctx)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if err != nil {
+			delete(c.entries, key)
+			return nil, err
+		}
+		c.entries[key] = mountTargetCacheEntry{mountTarget: mountTarget, expires: c.now().Add(c.ttl)}
+		return mountTarget, nil
+	})
+
+	select {
+	case result := <-results:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*cloud.MountTarget), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// invalidate drops the mount target of the file system in subnetId or azName cached for roleArn in region, for example
+// after mounting through it failed, so the next describe calls DescribeMountTargets again.
+func (c *mountTargetCache) invalidate(roleArn, region, fileSystemId, azName, subnetId string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, mountTargetCacheKey{roleArn: roleArn, region: region, fileSystemId: fileSystemId, azName: azName, subnetId: subnetId})
+}
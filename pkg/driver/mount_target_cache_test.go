@@ -0,0 +1,290 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/driver/mocks"
+)
+
+func TestMountTargetCacheCollapsesConcurrentLookups(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+	cache := newMountTargetCache(time.Minute)
+
+	ctx := context.Background()
+	release := make(chan struct{})
+	expected := &cloud.MountTarget{MountTargetId: "fsmt-a", AZName: "us-east-1a", IPAddress: "10.0.1.10"}
+	mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq("fs-abcd1234"), gomock.Eq("us-east-1a")).DoAndReturn(
+		func(ctx context.Context, fileSystemId, az string) (*cloud.MountTarget, error) {
+			<-release
+			return expected, nil
+		}).Times(1)
+
+	var wg sync.WaitGroup
+	results := make(chan *cloud.MountTarget, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mt, err := cache.describe(ctx, mockCloud, "", "", "fs-abcd1234", "us-east-1a", "")
+			if err != nil {
+				t.Errorf("describe failed: %v", err)
+			}
+			results <- mt
+		}()
+	}
+	// Give the lookups a chance to queue up behind the first one
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for mt := range results {
+		if mt != expected {
+			t.Fatalf("Mount target mismatched. Expected: %v, actual: %v", expected, mt)
+		}
+	}
+	mockCtl.Finish()
+}
+
+func TestMountTargetCacheKeysAndExpiry(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+	cache := newMountTargetCache(30 * time.Second)
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	mtA := &cloud.MountTarget{MountTargetId: "fsmt-a", AZName: "us-east-1a"}
+	mtB := &cloud.MountTarget{MountTargetId: "fsmt-b", AZName: "us-east-1b"}
+	mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq("fs-abcd1234"), gomock.Eq("us-east-1a")).Return(mtA, nil).Times(2)
+	mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq("fs-abcd1234"), gomock.Eq("us-east-1b")).Return(mtB, nil).Times(1)
+
+	for _, step := range []struct {
+		azName   string
+		advance  time.Duration
+		expected *cloud.MountTarget
+	}{
+		{azName: "us-east-1a", expected: mtA},
+		{azName: "us-east-1b", expected: mtB},
+		{azName: "us-east-1a", advance: 10 * time.Second, expected: mtA},
+		{azName: "us-east-1a", advance: 30 * time.Second, expected: mtA},
+	} {
+		now = now.Add(step.advance)
+		mt, err := cache.describe(ctx, mockCloud, "", "", "fs-abcd1234", step.azName, "")
+		if err != nil {
+			t.Fatalf("describe failed: %v", err)
+		}
+		if mt != step.expected {
+			t.Fatalf("Mount target mismatched. Expected: %v, actual: %v", step.expected, mt)
+		}
+	}
+	mockCtl.Finish()
+}
+
+func TestMountTargetCacheDoesNotCacheErrors(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+	cache := newMountTargetCache(time.Minute)
+
+	ctx := context.Background()
+	expected := &cloud.MountTarget{MountTargetId: "fsmt-a"}
+	gomock.InOrder(
+		mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq("fs-abcd1234"), gomock.Eq("")).Return(nil, errors.New("throttled")),
+		mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq("fs-abcd1234"), gomock.Eq("")).Return(expected, nil),
+	)
+
+	if _, err := cache.describe(ctx, mockCloud, "", "", "fs-abcd1234", "", ""); err == nil {
+		t.Fatal("describe did not fail")
+	}
+	mt, err := cache.describe(ctx, mockCloud, "", "", "fs-abcd1234", "", "")
+	if err != nil {
+		t.Fatalf("describe failed: %v", err)
+	}
+	if mt != expected {
+		t.Fatalf("Mount target mismatched. Expected: %v, actual: %v", expected, mt)
+	}
+	mockCtl.Finish()
+}
+
+func TestMountTargetCacheDisabled(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+
+	ctx := context.Background()
+	mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq("fs-abcd1234"), gomock.Eq("")).Return(&cloud.MountTarget{}, nil).Times(4)
+
+	for _, cache := range []*mountTargetCache{nil, newMountTargetCache(0)} {
+		for i := 0; i < 2; i++ {
+			if _, err := cache.describe(ctx, mockCloud, "", "", "fs-abcd1234", "", ""); err != nil {
+				t.Fatalf("describe failed: %v", err)
+			}
+		}
+	}
+	mockCtl.Finish()
+}
+
+func TestMountTargetCacheInvalidate(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+	cache := newMountTargetCache(time.Minute)
+
+	ctx := context.Background()
+	mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq("fs-abcd1234"), gomock.Eq("")).Return(&cloud.MountTarget{}, nil).Times(2)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.describe(ctx, mockCloud, "", "", "fs-abcd1234", "", ""); err != nil {
+			t.Fatalf("describe failed: %v", err)
+		}
+	}
+	cache.invalidate("", "", "fs-abcd1234", "", "")
+	if _, err := cache.describe(ctx, mockCloud, "", "", "fs-abcd1234", "", ""); err != nil {
+		t.Fatalf("describe failed: %v", err)
+	}
+
+	// Invalidating a nil cache does nothing
+	var disabled *mountTargetCache
+	disabled.invalidate("", "", "fs-abcd1234", "", "")
+	mockCtl.Finish()
+}
+
+func TestMountTargetCacheKeepsRolesAndRegionsApart(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+	cache := newMountTargetCache(time.Minute)
+
+	ctx := context.Background()
+	mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq("fs-abcd1234"), gomock.Eq("us-east-1a")).Return(&cloud.MountTarget{}, nil).Times(4)
+
+	identities := []struct {
+		roleArn string
+		region  string
+	}{
+		{},
+		{roleArn: "arn:aws:iam::1234567890:role/EFSCrossAccountRole"},
+		{region: "us-west-2"},
+	}
+	for i := 0; i < 2; i++ {
+		for _, id := range identities {
+			if _, err := cache.describe(ctx, mockCloud, id.roleArn, id.region, "fs-abcd1234", "us-east-1a", ""); err != nil {
+				t.Fatalf("describe failed: %v", err)
+			}
+		}
+	}
+
+	// Invalidating the mount target of one role leaves the others cached
+	cache.invalidate(identities[1].roleArn, "", "fs-abcd1234", "us-east-1a", "")
+	for _, id := range identities {
+		if _, err := cache.describe(ctx, mockCloud, id.roleArn, id.region, "fs-abcd1234", "us-east-1a", ""); err != nil {
+			t.Fatalf("describe failed: %v", err)
+		}
+	}
+	mockCtl.Finish()
+}
+
+func TestMountTargetCacheSubnet(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+	cache := newMountTargetCache(time.Minute)
+
+	ctx := context.Background()
+	mtA := &cloud.MountTarget{MountTargetId: "fsmt-a", AZName: "us-east-1a", SubnetId: "subnet-a"}
+	mtB := &cloud.MountTarget{MountTargetId: "fsmt-b", AZName: "us-east-1a", SubnetId: "subnet-b"}
+	mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq("fs-abcd1234"), gomock.Eq("us-east-1a")).Return(mtA, nil).Times(1)
+	mockCloud.EXPECT().DescribeMountTargetInSubnet(gomock.Any(), gomock.Eq("fs-abcd1234"), gomock.Eq("subnet-b")).Return(mtB, nil).Times(2)
+
+	for i := 0; i < 2; i++ {
+		for _, expected := range []*cloud.MountTarget{mtA, mtB} {
+			subnetId := ""
+			if expected == mtB {
+				subnetId = expected.SubnetId
+			}
+			mt, err := cache.describe(ctx, mockCloud, "", "", "fs-abcd1234", "us-east-1a", subnetId)
+			if err != nil {
+				t.Fatalf("describe failed: %v", err)
+			}
+			if mt != expected {
+				t.Fatalf("Mount target mismatched. Expected: %v, actual: %v", expected, mt)
+			}
+		}
+	}
+
+	// Only the mount target of the subnet is described again
+	cache.invalidate("", "", "fs-abcd1234", "us-east-1a", "subnet-b")
+	for _, subnetId := range []string{"", "subnet-b"} {
+		if _, err := cache.describe(ctx, mockCloud, "", "", "fs-abcd1234", "us-east-1a", subnetId); err != nil {
+			t.Fatalf("describe failed: %v", err)
+		}
+	}
+	mockCtl.Finish()
+}
+
+func TestMountTargetCacheSharedLookupOutlivesFirstCaller(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+	cache := newMountTargetCache(time.Minute)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	expected := &cloud.MountTarget{MountTargetId: "fsmt-a", AZName: "us-east-1a", IPAddress: "10.0.1.10"}
+	mockCloud.EXPECT().DescribeMountTargets(gomock.Any(), gomock.Eq("fs-abcd1234"), gomock.Eq("us-east-1a")).DoAndReturn(
+		func(ctx context.Context, fileSystemId, az string) (*cloud.MountTarget, error) {
+			close(started)
+			<-release
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return expected, nil
+		}).Times(1)
+
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := cache.describe(firstCtx, mockCloud, "", "", "fs-abcd1234", "us-east-1a", "")
+		firstErr <- err
+	}()
+	<-started
+
+	second := make(chan *cloud.MountTarget, 1)
+	go func() {
+		mt, err := cache.describe(context.Background(), mockCloud, "", "", "fs-abcd1234", "us-east-1a", "")
+		if err != nil {
+			t.Errorf("describe failed: %v", err)
+		}
+		second <- mt
+	}()
+	// Give the second lookup a chance to queue up behind the first one
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	if err := <-firstErr; err != context.Canceled {
+		t.Fatalf("Expected the cancelled caller to stop waiting with %v, got: %v", context.Canceled, err)
+	}
+	close(release)
+	if mt := <-second; mt != expected {
+		t.Fatalf("Mount target mismatched. Expected: %v, actual: %v", expected, mt)
+	}
+	mockCtl.Finish()
+}
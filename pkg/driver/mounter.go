@@ -15,6 +15,9 @@ package driver
 
 import (
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	mount_utils "k8s.io/mount-utils"
 )
@@ -24,8 +27,12 @@ type Mounter interface {
 	mount_utils.Interface
 	MakeDir(pathname string) error
 	GetDeviceName(mountPath string) (string, int, error)
+	IsEfsAvailable() bool
 }
 
+// efsMountHelper is the mount helper efs-utils installs for the efs file system type.
+const efsMountHelper = "mount.efs"
+
 type NodeMounter struct {
 	mount_utils.Interface
 }
@@ -46,6 +53,26 @@ func (m *NodeMounter) MakeDir(pathname string) error {
 	return nil
 }
 
+// IsUnknownFsTypeError returns true if a mount failed because the host has no helper for the file system type,
+// such as when efs-utils is not installed for the efs type.
+func IsUnknownFsTypeError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "unknown filesystem type") || strings.Contains(msg, "helper program")
+}
+
+// IsEfsAvailable returns true if the efs-utils mount helper is installed, without it every mount of the efs type fails.
+func (m *NodeMounter) IsEfsAvailable() bool {
+	if _, err := exec.LookPath(efsMountHelper); err == nil {
+		return true
+	}
+	// mount also runs helpers from /sbin, which is not always on the PATH
+	_, err := os.Stat(filepath.Join("/sbin", efsMountHelper))
+	return err == nil
+}
+
 func (m *NodeMounter) GetDeviceName(mountPath string) (string, int, error) {
 	return mount_utils.GetDeviceNameFromMount(m, mountPath)
 }
@@ -0,0 +1,36 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsEfsAvailable(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	mounter := &NodeMounter{}
+
+	if err := os.WriteFile(filepath.Join(dir, efsMountHelper), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !mounter.IsEfsAvailable() {
+		t.Fatalf("Expected %v on the PATH to be found", efsMountHelper)
+	}
+}
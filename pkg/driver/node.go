@@ -22,6 +22,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -38,6 +39,8 @@ var (
 	}
 	volumeIdCounter  = make(map[string]int)
 	supportedFSTypes = []string{"efs", ""}
+	// volumeIdVersion matches the version prefix of volume IDs in formats newer than fs-...:subpath:fsap-...
+	volumeIdVersion = regexp.MustCompile(`^v(\d+):`)
 )
 
 func (d *Driver) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolumeRequest) (*csi.NodeStageVolumeResponse, error) {
@@ -85,6 +88,9 @@ func (d *Driver) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolu
 			subpath = filepath.Join(subpath, v)
 		case "storage.kubernetes.io/csiprovisioneridentity":
 			continue
+		case VolCtxRootDir, VolCtxUid, VolCtxGid, VolCtxDirectoryPerms, VolCtxResolvedParams:
+			// Informational, set by CreateVolume to describe the access point
+			continue
 		case "encryptintransit":
 			var err error
 			encryptInTransit, err = strconv.ParseBool(v)
@@ -99,11 +105,12 @@ func (d *Driver) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolu
 		}
 	}
 
-	fsid, vpath, apid, err := parseVolumeId(req.GetVolumeId())
+	handle, err := parseVolumeHandle(req.GetVolumeId())
 	if err != nil {
-		// parseVolumeId returns the appropriate error
+		// parseVolumeHandle returns the appropriate error
 		return nil, err
 	}
+	fsid, vpath, apid := handle.fileSystemId, handle.subpath, handle.accessPointId
 	// The `vpath` takes precedence if specified. If not specified, we'll either use the
 	// (deprecated) `path` from the volContext, or default to "/" from above.
 	if vpath != "" {
@@ -120,6 +127,11 @@ func (d *Driver) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolu
 		mountOptions = append(mountOptions, fmt.Sprintf("accesspoint=%s", apid), "tls")
 	}
 
+	// efs-utils resolves the file system in the instance's region unless told otherwise
+	if handle.region != "" {
+		mountOptions = append(mountOptions, "region="+handle.region)
+	}
+
 	if encryptInTransit {
 		// The TLS option may have been added above if apid was set
 		// TODO: mountOptions should be a set to avoid all this hasOption checking
@@ -375,7 +387,8 @@ func (d *Driver) validateFStype(volCaps []*csi.VolumeCapability) error {
 	return nil
 }
 
-// parseVolumeId accepts a NodePublishVolumeRequest.VolumeId as a colon-delimited string of the
+// parseVolumeId accepts a NodePublishVolumeRequest.VolumeId in the versioned format encoded by volumeHandle, such as
+// `v1:accesspoint:{fileSystemID}:{accessPointID}`, or as a legacy colon-delimited string of the
 // form `{fileSystemID}:{mountPath}:{accessPointID}`.
 //   - The `{fileSystemID}` is required, and expected to be of the form `fs-...`.
 //   - The other two fields are optional -- they may be empty or omitted entirely. For example,
@@ -390,6 +403,22 @@ func (d *Driver) validateFStype(volCaps []*csi.VolumeCapability) error {
 // - https://github.com/kubernetes-sigs/aws-efs-csi-driver/issues/100
 // - https://github.com/kubernetes-sigs/aws-efs-csi-driver/issues/167
 func parseVolumeId(volumeId string) (fsid, subpath, apid string, err error) {
+	v, err := parseVolumeHandle(volumeId)
+	return v.fileSystemId, v.subpath, v.accessPointId, err
+}
+
+// parseVolumeHandle parses a volume ID of either format like parseVolumeId, and also returns the region of a file
+// system outside the region of the driver's clients, which only the versioned format records.
+func parseVolumeHandle(volumeId string) (v volumeHandle, err error) {
+	// Never guess at the meaning of a format this driver does not know, it could point at the wrong resources
+	if matches := volumeIdVersion.FindStringSubmatch(volumeId); matches != nil {
+		if "v"+matches[1] != volumeIdV1 {
+			err = status.Errorf(codes.Unimplemented, "volume ID '%s' was produced by a newer driver version: volume ID version %s is not supported", volumeId, matches[1])
+			return
+		}
+		return decodeVolumeIdV1(volumeId)
+	}
+
 	// Might as well do this up front, since the FSID is required and first in the string
 	if !isValidFileSystemId(volumeId) {
 		err = status.Errorf(codes.InvalidArgument, "volume ID '%s' is invalid: Expected a file system ID of the form 'fs-...'", volumeId)
@@ -403,20 +432,20 @@ func parseVolumeId(volumeId string) (fsid, subpath, apid string, err error) {
 	}
 
 	// Okay, we know we have a FSID
-	fsid = tokens[0]
+	v.mode, v.fileSystemId = volumeModeFileSystem, tokens[0]
 
 	// Do we have a subpath?
 	if len(tokens) >= 2 && tokens[1] != "" {
-		subpath = path.Clean(tokens[1])
+		v.subpath = path.Clean(tokens[1])
 	}
 
 	// Do we have an access point ID?
 	if len(tokens) == 3 && tokens[2] != "" {
-		apid = tokens[2]
-		if !isValidAccessPointId(apid) {
-			err = status.Errorf(codes.InvalidArgument, "volume ID '%s' has an invalid access point ID '%s': Expected it to be of the form 'fsap-...'", volumeId, apid)
+		if !isValidAccessPointId(tokens[2]) {
+			err = status.Errorf(codes.InvalidArgument, "volume ID '%s' has an invalid access point ID '%s': Expected it to be of the form 'fsap-...'", volumeId, tokens[2])
 			return
 		}
+		v.mode, v.accessPointId = volumeModeAccessPoint, tokens[2]
 	}
 
 	return
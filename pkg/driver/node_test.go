@@ -246,6 +246,39 @@ func TestNodePublishVolume(t *testing.T) {
 			mountArgs:     []interface{}{volumeId + ":/", targetPath, "efs", []string{"accesspoint=" + accessPointID, "tls"}},
 			mountSuccess:  true,
 		},
+		{
+			name: "success: access point in versioned volume handle",
+			req: &csi.NodePublishVolumeRequest{
+				VolumeId:         "v1:accesspoint:" + volumeId + ":" + accessPointID,
+				VolumeCapability: stdVolCap,
+				TargetPath:       targetPath,
+			},
+			expectMakeDir: true,
+			mountArgs:     []interface{}{volumeId + ":/", targetPath, "efs", []string{"accesspoint=" + accessPointID, "tls"}},
+			mountSuccess:  true,
+		},
+		{
+			name: "success: access point in another region in versioned volume handle",
+			req: &csi.NodePublishVolumeRequest{
+				VolumeId:         "v1:accesspoint:eu-west-1/" + volumeId + ":" + accessPointID,
+				VolumeCapability: stdVolCap,
+				TargetPath:       targetPath,
+			},
+			expectMakeDir: true,
+			mountArgs:     []interface{}{volumeId + ":/", targetPath, "efs", []string{"accesspoint=" + accessPointID, "tls", "region=eu-west-1"}},
+			mountSuccess:  true,
+		},
+		{
+			name: "success: path and file system in versioned volume handle",
+			req: &csi.NodePublishVolumeRequest{
+				VolumeId:         "v1:filesystem:" + volumeId + ":/a/b/",
+				VolumeCapability: stdVolCap,
+				TargetPath:       targetPath,
+			},
+			expectMakeDir: true,
+			mountArgs:     []interface{}{volumeId + ":/a/b", targetPath, "efs", []string{"tls"}},
+			mountSuccess:  true,
+		},
 		{
 			name: "success: path and access point in volume handle",
 			req: &csi.NodePublishVolumeRequest{
@@ -316,6 +349,24 @@ func TestNodePublishVolume(t *testing.T) {
 			mountArgs:     []interface{}{volumeId + ":/", targetPath, "efs", []string{"mounttargetip=127.0.0.1", "tls"}},
 			mountSuccess:  true,
 		},
+		{
+			name: "success: normal with access point details in volume context",
+			req: &csi.NodePublishVolumeRequest{
+				VolumeId:         volumeId,
+				VolumeCapability: stdVolCap,
+				TargetPath:       targetPath,
+				VolumeContext: map[string]string{
+					"accesspoint/rootdir":            "/pvc-1234",
+					"accesspoint/uid":                "1000",
+					"accesspoint/gid":                "1000",
+					"accesspoint/directoryperms":     "700",
+					"accesspoint/resolvedparameters": `{"fileSystemId":"fs-abcd1234"}`,
+				},
+			},
+			expectMakeDir: true,
+			mountArgs:     []interface{}{volumeId + ":/", targetPath, "efs", []string{"tls"}},
+			mountSuccess:  true,
+		},
 		{
 			name: "success: supported volume fstype capability",
 			req: &csi.NodePublishVolumeRequest{
@@ -371,6 +422,19 @@ func TestNodePublishVolume(t *testing.T) {
 				message: "volume ID 'fs-abc123:/a/b/::four!' is invalid: Expected at most three fields separated by ':'",
 			},
 		},
+		{
+			name: "fail: volume handle from a newer driver version",
+			req: &csi.NodePublishVolumeRequest{
+				VolumeId:         "v9:accesspoint:fs-abc123:fsap-abcd1234",
+				VolumeCapability: stdVolCap,
+				TargetPath:       targetPath,
+			},
+			expectMakeDir: false,
+			expectError: errtyp{
+				code:    "Unimplemented",
+				message: "volume ID 'v9:accesspoint:fs-abc123:fsap-abcd1234' was produced by a newer driver version: volume ID version 9 is not supported",
+			},
+		},
 		{
 			name: "fail: missing target path",
 			req: &csi.NodePublishVolumeRequest{
@@ -79,7 +79,7 @@ func TestSanityEFSCSI(t *testing.T) {
 		nodeCaps:        nodeCaps,
 		volMetricsOptIn: true,
 		volStatter:      NewVolStatter(),
-		gidAllocator:    NewGidAllocator(mockCloud),
+		gidAllocator:    NewGidAllocator(),
 	}
 	defer func() {
 		if r := recover(); r != nil {
@@ -98,10 +98,21 @@ func TestSanityEFSCSI(t *testing.T) {
 	mockCtrl.Finish()
 }
 
+// fakeMounter reports efs-utils as installed, the sanity tests never run its mount helper.
+type fakeMounter struct {
+	*NodeMounter
+}
+
+func (m *fakeMounter) IsEfsAvailable() bool {
+	return true
+}
+
 func NewFakeMounter() Mounter {
-	return &NodeMounter{
-		Interface: &mount.FakeMounter{
-			MountPoints: []mount.MountPoint{},
+	return &fakeMounter{
+		NodeMounter: &NodeMounter{
+			Interface: &mount.FakeMounter{
+				MountPoints: []mount.MountPoint{},
+			},
 		},
 	}
 }
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/aws/aws-xray-sdk-go/strategy/sampling"
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"k8s.io/klog/v2"
+)
+
+// Every controller operation is rare enough that it is worth tracing all of them.
+const xraySamplingRules = `{"version": 2, "default": {"fixed_target": 1, "rate": 1.0}}`
+
+// Tracer records the time spent in a controller operation and in the AWS and mount calls it makes.
+type Tracer interface {
+	// BeginSegment starts tracing a controller operation. The returned function ends it.
+	BeginSegment(ctx context.Context, name string) (context.Context, func(error))
+	// Capture traces fn as a step of the operation carried by ctx.
+	Capture(ctx context.Context, name string, fn func(context.Context) error) error
+}
+
+type noopTracer struct{}
+
+func (noopTracer) BeginSegment(ctx context.Context, name string) (context.Context, func(error)) {
+	return ctx, func(error) {}
+}
+
+func (noopTracer) Capture(ctx context.Context, name string, fn func(context.Context) error) error {
+	return fn(ctx)
+}
+
+type xrayTracer struct {
+	config xray.Config
+}
+
+// NewXrayTracer returns a Tracer that sends segments to the AWS X-Ray daemon listening on daemonAddress.
+func NewXrayTracer(daemonAddress string) (Tracer, error) {
+	addr, err := net.ResolveUDPAddr("udp", daemonAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X-Ray daemon address %q: %v", daemonAddress, err)
+	}
+	emitter, err := xray.NewDefaultEmitter(addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not create X-Ray emitter: %v", err)
+	}
+	samplingStrategy, err := sampling.NewLocalizedStrategyFromJSONBytes([]byte(xraySamplingRules))
+	if err != nil {
+		return nil, fmt.Errorf("could not create X-Ray sampling strategy: %v", err)
+	}
+	// The emitter already targets the daemon, leaving DaemonAddr unset keeps each segment from redialing it.
+	return &xrayTracer{
+		config: xray.Config{
+			ServiceVersion:   driverVersion,
+			Emitter:          emitter,
+			SamplingStrategy: samplingStrategy,
+		},
+	}, nil
+}
+
+func (t *xrayTracer) BeginSegment(ctx context.Context, name string) (context.Context, func(error)) {
+	ctx, err := xray.ContextWithConfig(ctx, t.config)
+	if err != nil {
+		return ctx, func(error) {}
+	}
+	ctx, seg := xray.BeginSegment(ctx, name)
+	return ctx, seg.Close
+}
+
+func (t *xrayTracer) Capture(ctx context.Context, name string, fn func(context.Context) error) error {
+	return xray.Capture(ctx, name, fn)
+}
+
+// phaseTracer times the steps captured by the Tracer it wraps, for the phase metrics and the V(4) logs.
+type phaseTracer struct {
+	Tracer
+	metrics *provisioningMetrics
+}
+
+func (t phaseTracer) Capture(ctx context.Context, name string, fn func(context.Context) error) error {
+	start := time.Now()
+	err := t.Tracer.Capture(ctx, name, fn)
+	t.metrics.observePhase(name, start)
+	klog.V(4).Infof("%v took %v", name, time.Since(start))
+	return err
+}
+
+func (d *Driver) getTracer() Tracer {
+	var tracer Tracer = noopTracer{}
+	if d.tracer != nil {
+		tracer = d.tracer
+	}
+	return phaseTracer{Tracer: tracer, metrics: d.metrics}
+}
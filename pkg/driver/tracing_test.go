@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+
+	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-efs-csi-driver/pkg/driver/mocks"
+)
+
+type tracedSegment struct {
+	Name        string          `json:"name"`
+	Fault       bool            `json:"fault"`
+	Subsegments []tracedSegment `json:"subsegments"`
+}
+
+func readSegment(t *testing.T, conn net.PacketConn) tracedSegment {
+	buf := make([]byte, 64*1024)
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Did not receive a segment: %v", err)
+	}
+	// Every packet starts with a header line ahead of the segment document.
+	parts := bytes.SplitN(buf[:n], []byte("\n"), 2)
+	if len(parts) != 2 {
+		t.Fatalf("Unexpected packet: %s", buf[:n])
+	}
+	var seg tracedSegment
+	if err := json.Unmarshal(parts[1], &seg); err != nil {
+		t.Fatalf("Could not decode segment %s: %v", parts[1], err)
+	}
+	return seg
+}
+
+func TestNoopTracer(t *testing.T) {
+	tracer := (&Driver{}).getTracer()
+	ctx := context.WithValue(context.Background(), "key", "value")
+
+	tracedCtx, end := tracer.BeginSegment(ctx, "CreateVolume")
+	defer end(nil)
+	if tracedCtx != ctx {
+		t.Fatal("Expected the no-op tracer to pass the context through unchanged")
+	}
+
+	called := false
+	err := tracer.Capture(ctx, "DescribeFileSystem", func(c context.Context) error {
+		called = true
+		if c != ctx {
+			t.Fatal("Expected the no-op tracer to pass the context through unchanged")
+		}
+		return nil
+	})
+	if err != nil || !called {
+		t.Fatalf("Expected Capture to call fn, called: %v err: %v", called, err)
+	}
+}
+
+func TestXrayTracer(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	tracer, err := NewXrayTracer(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("NewXrayTracer failed: %v", err)
+	}
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	mockCloud := mocks.NewMockCloud(mockCtl)
+
+	driver := &Driver{
+		endpoint:     "endpoint",
+		cloud:        mockCloud,
+		gidAllocator: NewGidAllocator(),
+		tags:         parseTagsFromStr(""),
+		tracer:       tracer,
+	}
+
+	req := &csi.CreateVolumeRequest{
+		Name: "volumeName",
+		VolumeCapabilities: []*csi.VolumeCapability{
+			{
+				AccessType: &csi.VolumeCapability_Mount{
+					Mount: &csi.VolumeCapability_MountVolume{},
+				},
+				AccessMode: &csi.VolumeCapability_AccessMode{
+					Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+				},
+			},
+		},
+		CapacityRange: &csi.CapacityRange{
+			RequiredBytes: 5368709120,
+		},
+		Parameters: map[string]string{
+			ProvisioningMode: "efs-ap",
+			FsId:             "fs-abcd1234",
+			GidMin:           "1000",
+			GidMax:           "2000",
+			DirectoryPerms:   "777",
+		},
+	}
+
+	accessPoint := &cloud.AccessPoint{
+		AccessPointId: "fsap-abcd1234xyz987",
+		FileSystemId:  "fs-abcd1234",
+	}
+	mockCloud.EXPECT().DescribeFileSystem(gomock.Any(), gomock.Any()).Return(&cloud.FileSystem{FileSystemId: "fs-abcd1234"}, nil)
+	mockCloud.EXPECT().ListAccessPoints(gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockCloud.EXPECT().CreateAccessPoint(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(accessPoint, nil)
+
+	if _, err := driver.CreateVolume(context.Background(), req); err != nil {
+		t.Fatalf("CreateVolume failed: %v", err)
+	}
+
+	seg := readSegment(t, conn)
+	if seg.Name != "CreateVolume" {
+		t.Fatalf("Expected segment CreateVolume, got %q", seg.Name)
+	}
+	if seg.Fault {
+		t.Fatal("Expected segment not to record a fault")
+	}
+	var names []string
+	for _, sub := range seg.Subsegments {
+		names = append(names, sub.Name)
+	}
+	if len(names) != 2 || names[0] != "DescribeFileSystem" || names[1] != "CreateAccessPoint" {
+		t.Fatalf("Expected subsegments [DescribeFileSystem CreateAccessPoint], got %v", names)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"os"
+	"path"
+	"sync"
+)
+
+// treeRemover deletes directory trees with up to a fixed number of goroutines working on separate entries at once.
+// Whatever it fails to delete is left in place, so a failed or cancelled removal can simply be run again.
+type treeRemover struct {
+	// slots limits the goroutines started besides the caller's, work is done inline when none is free
+	slots  chan struct{}
+	remove func(name string) error
+}
+
+func newTreeRemover(workers int) *treeRemover {
+	if workers < 1 {
+		workers = 1
+	}
+	return &treeRemover{
+		slots:  make(chan struct{}, workers-1),
+		remove: os.Remove,
+	}
+}
+
+// removeAll removes dir and everything inside it. A missing dir is not an error.
+func (r *treeRemover) removeAll(ctx context.Context, dir string) error {
+	if err := r.removeContents(ctx, dir); err != nil {
+		return err
+	}
+	if err := r.remove(dir); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// removeContents removes everything inside dir but leaves dir itself in place. It stops at the first error,
+// or once ctx is done, and returns that error.
+func (r *treeRemover) removeContents(ctx context.Context, dir string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		name, isDir := path.Join(dir, entry.Name()), entry.IsDir()
+		// Symbolic links are removed rather than followed, DirEntry reports them as non directories
+		remove := func() error {
+			if isDir {
+				return r.removeAll(ctx, name)
+			}
+			if err := r.remove(name); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		}
+		select {
+		case r.slots <- struct{}{}:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-r.slots }()
+				if err := remove(); err != nil {
+					fail(err)
+				}
+			}()
+		default:
+			if err := remove(); err != nil {
+				fail(err)
+			}
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// makeTree creates dirs directories under root, each holding files files.
+func makeTree(t *testing.T, root string, dirs, files int) {
+	for i := 0; i < dirs; i++ {
+		dir := path.Join(root, fmt.Sprintf("dir-%d", i), "nested")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < files; j++ {
+			if err := os.WriteFile(path.Join(dir, fmt.Sprintf("file-%d", j)), []byte("data"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := os.Symlink("/", path.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTreeRemoverRemoveContents(t *testing.T) {
+	for _, workers := range []int{0, 1, 4} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			dir := t.TempDir()
+			makeTree(t, dir, 5, 5)
+
+			if err := newTreeRemover(workers).removeContents(context.Background(), dir); err != nil {
+				t.Fatalf("removeContents failed: %v", err)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("Expected the directory to remain: %v", err)
+			}
+			if len(entries) != 0 {
+				t.Fatalf("Expected the directory to be empty, found %d entries", len(entries))
+			}
+
+			if err := newTreeRemover(workers).removeContents(context.Background(), path.Join(dir, "missing")); err != nil {
+				t.Fatalf("Expected a missing directory to be ignored, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestTreeRemoverRemoveAll(t *testing.T) {
+	dir := path.Join(t.TempDir(), "root")
+	makeTree(t, dir, 3, 3)
+
+	if err := newTreeRemover(4).removeAll(context.Background(), dir); err != nil {
+		t.Fatalf("removeAll failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("Expected the directory to be removed, got: %v", err)
+	}
+	if err := newTreeRemover(4).removeAll(context.Background(), dir); err != nil {
+		t.Fatalf("Expected a missing directory to be ignored, got: %v", err)
+	}
+}
+
+func TestTreeRemoverParallel(t *testing.T) {
+	const workers = 4
+	dir := t.TempDir()
+	makeTree(t, dir, 8, 4)
+
+	var running, maxRunning int32
+	remover := newTreeRemover(workers)
+	remover.remove = func(name string) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return os.Remove(name)
+	}
+
+	if err := remover.removeContents(context.Background(), dir); err != nil {
+		t.Fatalf("removeContents failed: %v", err)
+	}
+	if maxRunning < 2 {
+		t.Fatalf("Expected entries to be removed in parallel, at most %d were", maxRunning)
+	}
+	if maxRunning > workers {
+		t.Fatalf("Expected at most %d entries to be removed at once, %d were", workers, maxRunning)
+	}
+}
+
+func TestTreeRemoverError(t *testing.T) {
+	dir := t.TempDir()
+	makeTree(t, dir, 4, 4)
+
+	failed := path.Join(dir, "dir-2", "nested", "file-1")
+	removeErr := errors.New("permission denied")
+	remover := newTreeRemover(4)
+	remover.remove = func(name string) error {
+		if name == failed {
+			return removeErr
+		}
+		return os.Remove(name)
+	}
+
+	if err := remover.removeContents(context.Background(), dir); !errors.Is(err, removeErr) {
+		t.Fatalf("Expected %v, got: %v", removeErr, err)
+	}
+	if _, err := os.Stat(failed); err != nil {
+		t.Fatalf("Expected %q to be left in place, got: %v", failed, err)
+	}
+
+	// What was left behind is removed by running again
+	if err := newTreeRemover(4).removeContents(context.Background(), dir); err != nil {
+		t.Fatalf("Retried removeContents failed: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("Expected the directory to be empty, found %d entries", len(entries))
+	}
+}
+
+func TestTreeRemoverCancel(t *testing.T) {
+	dir := t.TempDir()
+	makeTree(t, dir, 8, 8)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var once sync.Once
+	var removed int32
+	remover := newTreeRemover(2)
+	remover.remove = func(name string) error {
+		once.Do(cancel)
+		atomic.AddInt32(&removed, 1)
+		return os.Remove(name)
+	}
+
+	if err := remover.removeContents(ctx, dir); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected %v, got: %v", context.Canceled, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) == 0 {
+		t.Fatalf("Expected removal to stop once cancelled, %d entries were removed", removed)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	volumeIdV1 = "v1"
+
+	// volumeIdFormatLegacy volume IDs are {fileSystemID}:{subpath}:{accessPointID}, with empty trailing fields
+	// omitted, which node plugins older than the v1 format can still mount.
+	volumeIdFormatLegacy = "legacy"
+
+	// volumeModeAccessPoint volumes are mounted through an access point: v1:accesspoint:{fileSystemID}:{accessPointID}[:{subpath}]
+	volumeModeAccessPoint = "accesspoint"
+	// volumeModeFileSystem volumes are mounted from the file system root: v1:filesystem:{fileSystemID}[:{subpath}]
+	volumeModeFileSystem = "filesystem"
+)
+
+// A file system outside the region of the driver's clients is recorded with its region, as {region}/{fileSystemID}
+// in place of {fileSystemID}.
+var regionPattern = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-\d+$`)
+
+// volumeHandle holds the fields encoded in a volume ID. Every field is positional and none may contain ':',
+// so each one is recovered unambiguously whichever of the optional fields are set.
+type volumeHandle struct {
+	mode          string
+	region        string
+	fileSystemId  string
+	accessPointId string
+	subpath       string
+}
+
+// newAccessPointVolumeHandle returns the handle of a volume mounted through an access point.
+func newAccessPointVolumeHandle(fileSystemId, accessPointId string) volumeHandle {
+	return volumeHandle{mode: volumeModeAccessPoint, fileSystemId: fileSystemId, accessPointId: accessPointId}
+}
+
+// volumeIdFormats are the formats CreateVolume can encode volume IDs in.
+var volumeIdFormats = []string{volumeIdFormatLegacy, volumeIdV1}
+
+func checkVolumeIdFormat(format string) error {
+	for _, supported := range volumeIdFormats {
+		if format == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("volume ID format %q is not supported, it must be one of %v", format, volumeIdFormats)
+}
+
+// encode encodes the handle in format, where an empty format is legacy. The legacy format cannot record a region,
+// so a handle with one is always encoded in the v1 format.
+func (v volumeHandle) encode(format string) string {
+	if format == volumeIdV1 || v.region != "" {
+		return v.String()
+	}
+	fields := []string{v.fileSystemId, v.subpath, v.accessPointId}
+	for len(fields) > 1 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+	return strings.Join(fields, ":")
+}
+
+// String encodes the handle in the current volume ID format.
+func (v volumeHandle) String() string {
+	fileSystem := v.fileSystemId
+	if v.region != "" {
+		fileSystem = v.region + "/" + fileSystem
+	}
+	fields := []string{volumeIdV1, v.mode, fileSystem}
+	if v.mode == volumeModeAccessPoint {
+		fields = append(fields, v.accessPointId)
+	}
+	if v.subpath != "" {
+		fields = append(fields, v.subpath)
+	}
+	return strings.Join(fields, ":")
+}
+
+// decodeVolumeIdV1 decodes a volume ID in the v1 format. Errors are a `status.Error` with `codes.InvalidArgument`.
+func decodeVolumeIdV1(volumeId string) (v volumeHandle, err error) {
+	tokens := strings.Split(volumeId, ":")
+	if len(tokens) < 3 || tokens[0] != volumeIdV1 {
+		err = status.Errorf(codes.InvalidArgument, "volume ID '%s' is invalid: Expected it to start with '%s:{mode}:{fileSystemID}'", volumeId, volumeIdV1)
+		return
+	}
+	v.mode, v.fileSystemId = tokens[1], tokens[2]
+	if region, fileSystemId, found := strings.Cut(v.fileSystemId, "/"); found {
+		if !regionPattern.MatchString(region) {
+			err = status.Errorf(codes.InvalidArgument, "volume ID '%s' is invalid: Expected a region such as 'us-east-1' before the file system ID", volumeId)
+			return
+		}
+		v.region, v.fileSystemId = region, fileSystemId
+	}
+	if !isValidFileSystemId(v.fileSystemId) {
+		err = status.Errorf(codes.InvalidArgument, "volume ID '%s' is invalid: Expected a file system ID of the form 'fs-...'", volumeId)
+		return
+	}
+
+	rest := tokens[3:]
+	switch v.mode {
+	case volumeModeAccessPoint:
+		if len(rest) == 0 || !isValidAccessPointId(rest[0]) {
+			err = status.Errorf(codes.InvalidArgument, "volume ID '%s' is invalid: Expected an access point ID of the form 'fsap-...' after the file system ID", volumeId)
+			return
+		}
+		v.accessPointId, rest = rest[0], rest[1:]
+	case volumeModeFileSystem:
+	default:
+		err = status.Errorf(codes.InvalidArgument, "volume ID '%s' has unknown mode '%s'", volumeId, v.mode)
+		return
+	}
+
+	if len(rest) > 1 {
+		err = status.Errorf(codes.InvalidArgument, "volume ID '%s' is invalid: Unexpected fields after the subpath", volumeId)
+		return
+	}
+	if len(rest) == 1 {
+		if rest[0] == "" {
+			err = status.Errorf(codes.InvalidArgument, "volume ID '%s' is invalid: Subpath is empty", volumeId)
+			return
+		}
+		v.subpath = path.Clean(rest[0])
+	}
+	return
+}
+
+// accessPointIdOfVolumeId returns the access point ID in the position a volume ID of either format keeps it, even
+// when the rest of the volume ID is invalid. It is empty when that field does not hold an access point ID.
+func accessPointIdOfVolumeId(volumeId string) string {
+	tokens := strings.Split(volumeId, ":")
+	var accessPointId string
+	switch {
+	case tokens[0] == volumeIdV1:
+		if len(tokens) >= 4 && tokens[1] == volumeModeAccessPoint {
+			accessPointId = tokens[3]
+		}
+	case len(tokens) == 3:
+		accessPointId = tokens[2]
+	}
+	if !isValidAccessPointId(accessPointId) {
+		return ""
+	}
+	return accessPointId
+}
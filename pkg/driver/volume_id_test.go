@@ -0,0 +1,288 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestVolumeHandleRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name     string
+		handle   volumeHandle
+		expected string
+	}{
+		{
+			name:     "access point",
+			handle:   newAccessPointVolumeHandle("fs-abcd1234", "fsap-abcd1234xyz987"),
+			expected: "v1:accesspoint:fs-abcd1234:fsap-abcd1234xyz987",
+		},
+		{
+			name:     "access point with subpath",
+			handle:   volumeHandle{mode: volumeModeAccessPoint, fileSystemId: "fs-abcd1234", accessPointId: "fsap-abcd1234xyz987", subpath: "/a/b"},
+			expected: "v1:accesspoint:fs-abcd1234:fsap-abcd1234xyz987:/a/b",
+		},
+		{
+			name:     "file system",
+			handle:   volumeHandle{mode: volumeModeFileSystem, fileSystemId: "fs-abcd1234"},
+			expected: "v1:filesystem:fs-abcd1234",
+		},
+		{
+			name:     "file system with subpath",
+			handle:   volumeHandle{mode: volumeModeFileSystem, fileSystemId: "fs-abcd1234", subpath: "a/b"},
+			expected: "v1:filesystem:fs-abcd1234:a/b",
+		},
+		{
+			name:     "access point in another region",
+			handle:   volumeHandle{mode: volumeModeAccessPoint, region: "eu-west-1", fileSystemId: "fs-abcd1234", accessPointId: "fsap-abcd1234xyz987"},
+			expected: "v1:accesspoint:eu-west-1/fs-abcd1234:fsap-abcd1234xyz987",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			volumeId := tc.handle.String()
+			if volumeId != tc.expected {
+				t.Fatalf("Expected volume ID %q, got %q", tc.expected, volumeId)
+			}
+			decoded, err := decodeVolumeIdV1(volumeId)
+			if err != nil {
+				t.Fatalf("Failed to decode %q: %v", volumeId, err)
+			}
+			if decoded != tc.handle {
+				t.Fatalf("Expected %+v, got %+v", tc.handle, decoded)
+			}
+			fsid, subpath, apid, err := parseVolumeId(volumeId)
+			if err != nil {
+				t.Fatalf("Failed to parse %q: %v", volumeId, err)
+			}
+			if fsid != tc.handle.fileSystemId || subpath != tc.handle.subpath || apid != tc.handle.accessPointId {
+				t.Fatalf("Expected %+v, got fsid %q, subpath %q, apid %q", tc.handle, fsid, subpath, apid)
+			}
+		})
+	}
+}
+
+func TestVolumeHandleEncode(t *testing.T) {
+	accessPoint := newAccessPointVolumeHandle("fs-abcd1234", "fsap-abcd1234xyz987")
+	testCases := []struct {
+		name     string
+		handle   volumeHandle
+		format   string
+		expected string
+	}{
+		{
+			name:     "access point in the default format",
+			handle:   accessPoint,
+			expected: "fs-abcd1234::fsap-abcd1234xyz987",
+		},
+		{
+			name:     "access point in the legacy format",
+			handle:   accessPoint,
+			format:   volumeIdFormatLegacy,
+			expected: "fs-abcd1234::fsap-abcd1234xyz987",
+		},
+		{
+			name:     "access point in the v1 format",
+			handle:   accessPoint,
+			format:   volumeIdV1,
+			expected: "v1:accesspoint:fs-abcd1234:fsap-abcd1234xyz987",
+		},
+		{
+			name:     "access point with subpath in the legacy format",
+			handle:   volumeHandle{mode: volumeModeAccessPoint, fileSystemId: "fs-abcd1234", accessPointId: "fsap-abcd1234xyz987", subpath: "/a/b"},
+			format:   volumeIdFormatLegacy,
+			expected: "fs-abcd1234:/a/b:fsap-abcd1234xyz987",
+		},
+		{
+			name:     "file system in the legacy format",
+			handle:   volumeHandle{mode: volumeModeFileSystem, fileSystemId: "fs-abcd1234"},
+			format:   volumeIdFormatLegacy,
+			expected: "fs-abcd1234",
+		},
+		{
+			name:     "file system with subpath in the legacy format",
+			handle:   volumeHandle{mode: volumeModeFileSystem, fileSystemId: "fs-abcd1234", subpath: "a/b"},
+			format:   volumeIdFormatLegacy,
+			expected: "fs-abcd1234:a/b",
+		},
+		{
+			name:     "access point in another region is always in the v1 format",
+			handle:   volumeHandle{mode: volumeModeAccessPoint, region: "eu-west-1", fileSystemId: "fs-abcd1234", accessPointId: "fsap-abcd1234xyz987"},
+			format:   volumeIdFormatLegacy,
+			expected: "v1:accesspoint:eu-west-1/fs-abcd1234:fsap-abcd1234xyz987",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			volumeId := tc.handle.encode(tc.format)
+			if volumeId != tc.expected {
+				t.Fatalf("Expected volume ID %q, got %q", tc.expected, volumeId)
+			}
+			decoded, err := parseVolumeHandle(volumeId)
+			if err != nil {
+				t.Fatalf("Failed to parse %q: %v", volumeId, err)
+			}
+			if decoded != tc.handle {
+				t.Fatalf("Expected %+v, got %+v", tc.handle, decoded)
+			}
+		})
+	}
+}
+
+func TestCheckVolumeIdFormat(t *testing.T) {
+	for _, format := range volumeIdFormats {
+		if err := checkVolumeIdFormat(format); err != nil {
+			t.Fatalf("Expected format %q to be supported: %v", format, err)
+		}
+	}
+	if err := checkVolumeIdFormat("v2"); err == nil {
+		t.Fatal("Expected format v2 to be rejected")
+	}
+}
+
+func TestParseVolumeId(t *testing.T) {
+	testCases := []struct {
+		name      string
+		volumeId  string
+		fsid      string
+		subpath   string
+		apid      string
+		errorCode codes.Code
+	}{
+		{
+			name:     "legacy file system",
+			volumeId: "fs-abcd1234",
+			fsid:     "fs-abcd1234",
+		},
+		{
+			name:     "legacy file system and subpath",
+			volumeId: "fs-abcd1234:/a/b/",
+			fsid:     "fs-abcd1234",
+			subpath:  "/a/b",
+		},
+		{
+			name:     "legacy access point",
+			volumeId: "fs-abcd1234::fsap-abcd1234xyz987",
+			fsid:     "fs-abcd1234",
+			apid:     "fsap-abcd1234xyz987",
+		},
+		{
+			name:     "legacy access point and subpath",
+			volumeId: "fs-abcd1234:a/b:fsap-abcd1234xyz987",
+			fsid:     "fs-abcd1234",
+			subpath:  "a/b",
+			apid:     "fsap-abcd1234xyz987",
+		},
+		{
+			name:      "legacy with too many fields",
+			volumeId:  "fs-abcd1234:a:fsap-abcd1234xyz987:b",
+			errorCode: codes.InvalidArgument,
+		},
+		{
+			name:      "v1 access point without access point ID",
+			volumeId:  "v1:accesspoint:fs-abcd1234",
+			errorCode: codes.InvalidArgument,
+		},
+		{
+			name:      "v1 access point with a malformed access point ID",
+			volumeId:  "v1:accesspoint:fs-abcd1234:/a/b",
+			errorCode: codes.InvalidArgument,
+		},
+		{
+			name:      "v1 without file system ID",
+			volumeId:  "v1:filesystem::/a/b",
+			errorCode: codes.InvalidArgument,
+		},
+		{
+			name:      "v1 with unknown mode",
+			volumeId:  "v1:bucket:fs-abcd1234",
+			errorCode: codes.InvalidArgument,
+		},
+		{
+			name:      "v1 with empty subpath",
+			volumeId:  "v1:filesystem:fs-abcd1234:",
+			errorCode: codes.InvalidArgument,
+		},
+		{
+			name:      "v1 with too many fields",
+			volumeId:  "v1:filesystem:fs-abcd1234:a:b",
+			errorCode: codes.InvalidArgument,
+		},
+		{
+			name:     "v1 with region",
+			volumeId: "v1:accesspoint:us-gov-west-1/fs-abcd1234:fsap-abcd1234xyz987",
+			fsid:     "fs-abcd1234",
+			apid:     "fsap-abcd1234xyz987",
+		},
+		{
+			name:      "v1 with empty region",
+			volumeId:  "v1:accesspoint:/fs-abcd1234:fsap-abcd1234xyz987",
+			errorCode: codes.InvalidArgument,
+		},
+		{
+			name:      "v1 with malformed region",
+			volumeId:  "v1:accesspoint:EU/fs-abcd1234:fsap-abcd1234xyz987",
+			errorCode: codes.InvalidArgument,
+		},
+		{
+			name:      "newer version",
+			volumeId:  "v2:accesspoint:fs-abcd1234:fsap-abcd1234xyz987",
+			errorCode: codes.Unimplemented,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsid, subpath, apid, err := parseVolumeId(tc.volumeId)
+			if status.Code(err) != tc.errorCode {
+				t.Fatalf("Expected error code %v, got: %v", tc.errorCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if fsid != tc.fsid || subpath != tc.subpath || apid != tc.apid {
+				t.Fatalf("Expected fsid %q, subpath %q, apid %q, got %q, %q, %q", tc.fsid, tc.subpath, tc.apid, fsid, subpath, apid)
+			}
+		})
+	}
+}
+
+func TestAccessPointIdOfVolumeId(t *testing.T) {
+	testCases := []struct {
+		volumeId string
+		apid     string
+	}{
+		{volumeId: "fs-abcd1234::fsap-abcd1234xyz987", apid: "fsap-abcd1234xyz987"},
+		{volumeId: "::fsap-abcd1234xyz987", apid: "fsap-abcd1234xyz987"},
+		{volumeId: "v1:accesspoint:fs-abcd1234:fsap-abcd1234xyz987:/a", apid: "fsap-abcd1234xyz987"},
+		{volumeId: "v1:accesspoint::fsap-abcd1234xyz987", apid: "fsap-abcd1234xyz987"},
+		{volumeId: "v1:filesystem::fsap-abcd1234xyz987"},
+		{volumeId: "fs-abcd1234:/a/b"},
+		{volumeId: "reallyfakevolumeid"},
+		{volumeId: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.volumeId, func(t *testing.T) {
+			if apid := accessPointIdOfVolumeId(tc.volumeId); apid != tc.apid {
+				t.Fatalf("Expected %q, got %q", tc.apid, apid)
+			}
+		})
+	}
+}